@@ -0,0 +1,173 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Ezer015/leetcode-gen-test/codegen"
+)
+
+// outcome describes what processing a file did to the file it generates.
+type outcome int
+
+const (
+	created outcome = iota
+	updated
+	skipped
+)
+
+// fileResult is the result of processing a single file of a batch.
+type fileResult struct {
+	file string
+	// output is the file generated from file
+	output  string
+	outcome outcome
+	err     error
+}
+
+// summary aggregates the results of a batch.
+type summary struct {
+	created, updated, skipped, failed int
+}
+
+func (s summary) String() string {
+	return fmt.Sprintf("%d created, %d updated, %d skipped, %d failed", s.created, s.updated, s.skipped, s.failed)
+}
+
+// expandTargets resolves command arguments to the files to process. Files are
+// taken as they are, directories contribute their matching files, and
+// patterns ending in "/...", such as "./...", contribute the matching files of
+// the directory and all its subdirectories. As with the go command, hidden
+// directories and directories named testdata or vendor are not descended into.
+//
+// Parameters:
+//   - args: The files, directories and patterns to resolve
+//   - match: Reports whether a file found in a directory is processed
+//
+// Returns:
+//   - []string: The files to process, sorted and without duplicates
+//   - error: An error if an argument does not exist or a directory cannot be read
+func expandTargets(args []string, match func(path string) bool) ([]string, error) {
+	seen := make(map[string]bool)
+	var files []string
+	add := func(path string) {
+		path = filepath.Clean(path)
+		if !seen[path] {
+			seen[path] = true
+			files = append(files, path)
+		}
+	}
+
+	for _, arg := range args {
+		if slashed := filepath.ToSlash(arg); slashed == "..." || strings.HasSuffix(slashed, "/...") {
+			root := strings.TrimSuffix(slashed, "...")
+			if root == "" {
+				root = "."
+			}
+			root = filepath.Clean(filepath.FromSlash(root))
+			err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				if d.IsDir() {
+					name := d.Name()
+					if path != root &&
+						(strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") || name == "testdata" || name == "vendor") {
+						return filepath.SkipDir
+					}
+					return nil
+				}
+				if match(path) {
+					add(path)
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, fmt.Errorf("walking %s: %v", arg, err)
+			}
+			continue
+		}
+
+		info, err := os.Stat(arg)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			add(arg)
+			continue
+		}
+		entries, err := os.ReadDir(arg)
+		if err != nil {
+			return nil, fmt.Errorf("reading directory %s: %v", arg, err)
+		}
+		for _, entry := range entries {
+			if path := filepath.Join(arg, entry.Name()); !entry.IsDir() && match(path) {
+				add(path)
+			}
+		}
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+// runBatch processes files concurrently with a bounded number of workers. A
+// failing file does not stop the others; each file that is not skipped is
+// reported, in order, once all files are processed.
+//
+// Parameters:
+//   - files: The files to process
+//   - jobs: The maximum number of files processed at the same time
+//   - process: Processes a single file and returns the path of the file it
+//     generates
+//
+// Returns:
+//   - summary: The number of files per outcome
+func runBatch(files []string, jobs int, process func(file string) (string, outcome, error)) summary {
+	results := make([]fileResult, len(files))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range max(min(jobs, len(files)), 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				output, o, err := process(files[i])
+				results[i] = fileResult{file: files[i], output: output, outcome: o, err: err}
+			}
+		}()
+	}
+	for i := range files {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	var s summary
+	for _, r := range results {
+		switch {
+		case r.err != nil:
+			s.failed++
+			// Diagnostics name the files of the problems themselves
+			if ds, ok := r.err.(codegen.Diagnostics); ok {
+				fmt.Fprintln(os.Stderr, ds)
+			} else {
+				fmt.Fprintf(os.Stderr, "failed %s: %v\n", r.file, r.err)
+			}
+		case r.outcome == created:
+			s.created++
+			fmt.Printf("created %s\n", r.output)
+		case r.outcome == updated:
+			s.updated++
+			fmt.Printf("updated %s\n", r.output)
+		default:
+			s.skipped++
+		}
+	}
+	return s
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestExpandTargets(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{
+		"a.go", "a_testcase.go",
+		"sub/b.go", "sub/b_testcase.go",
+		"sub/deep/c_testcase.go",
+		".hidden/d_testcase.go", "testdata/e_testcase.go", "vendor/f_testcase.go", "_skip/g_testcase.go",
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rel := func(names ...string) []string {
+		var paths []string
+		for _, name := range names {
+			paths = append(paths, filepath.Join(dir, name))
+		}
+		return paths
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected []string
+	}{
+		{"file", rel("sub/b.go"), rel("sub/b.go")},
+		{"directory", rel("sub"), rel("sub/b_testcase.go")},
+		{"pattern", rel("sub/..."), rel("sub/b_testcase.go", "sub/deep/c_testcase.go")},
+		{"duplicates", append(rel("..."), rel("sub", "a_testcase.go")...), rel("a_testcase.go", "sub/b_testcase.go", "sub/deep/c_testcase.go")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := expandTargets(tt.args, isTestCaseFile)
+			if err != nil {
+				t.Fatalf("expandTargets() error = %v", err)
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("expandTargets() = %v; expected %v", result, tt.expected)
+			}
+		})
+	}
+
+	if _, err := expandTargets(rel("missing"), isTestCaseFile); err == nil {
+		t.Errorf("expandTargets() with a missing file succeeded; expected an error")
+	}
+}
+
+func TestRunBatch(t *testing.T) {
+	files := []string{"a", "b", "c", "d", "e"}
+	var running, peak atomic.Int32
+	result := runBatch(files, 2, func(file string) (string, outcome, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		switch file {
+		case "a", "b":
+			return file, created, nil
+		case "c":
+			return file, updated, nil
+		case "d":
+			return file, skipped, nil
+		default:
+			return file, skipped, os.ErrNotExist
+		}
+	})
+
+	expected := summary{created: 2, updated: 1, skipped: 1, failed: 1}
+	if result != expected {
+		t.Errorf("runBatch() = %v; expected %v", result, expected)
+	}
+	if p := peak.Load(); p > 2 {
+		t.Errorf("runBatch() ran %d files at once; expected at most 2", p)
+	}
+	if !strings.Contains(result.String(), "1 failed") {
+		t.Errorf("summary.String() = %q; expected it to count failures", result.String())
+	}
+}
@@ -0,0 +1,391 @@
+package codegen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// annotationPrefix marks doc comment lines that configure how a tagged
+// function is tested, e.g. "//leetcode:tolerance 1e-9".
+const annotationPrefix = "//leetcode:"
+
+const (
+	testAnnotation      = "test"
+	toleranceAnnotation = "tolerance"
+	unorderedAnnotation = "unordered"
+	inPlaceAnnotation   = "inplace"
+)
+
+// legacyTestTag tags declarations for testing in sources written before the
+// "//leetcode:test" directive, e.g. "//go:generate leetcode-gen-test init $GOFILE".
+const legacyTestTag = "//go:generate leetcode-gen-test"
+
+// Options of the test directive, e.g. "//leetcode:test timeout=1s compare=unordered".
+const (
+	// compareOption selects how results are compared: "unordered" compares
+	// the outermost slices as multisets, and "exact" compares floats without
+	// a tolerance.
+	compareOption = "compare"
+	// toleranceOption sets the tolerance of floating-point results, see
+	// parseTolerance, with commas instead of spaces, e.g. "abs=1e-9,rel=0".
+	toleranceOption = "tolerance"
+	// timeoutOption limits the time each test case may take, e.g. "500ms".
+	timeoutOption = "timeout"
+	// nameOption sets the name the test case types and the test are derived
+	// from, instead of the function name, e.g. "TwoSumHashing".
+	nameOption = "name"
+)
+
+// directive is the test directive tagging declarations for testing, whose
+// namespace the annotations share, e.g. "//leetcode:test" along with
+// "//leetcode:tolerance 1e-9".
+type directive struct {
+	// prefix starts the annotations, e.g. "//leetcode:".
+	prefix string
+	// test is the key of the test directive, e.g. "test".
+	test string
+}
+
+// defaultDirective is the "//leetcode:test" directive.
+var defaultDirective = directive{prefix: annotationPrefix, test: testAnnotation}
+
+// parseDirective parses the name of a test directive, which follows the
+// syntax of Go directives, "namespace:key", e.g. "leetcode:test". The key
+// must not be the key of another annotation.
+func parseDirective(name string) (directive, error) {
+	namespace, key, ok := strings.Cut(name, ":")
+	if !ok || !isDirectiveWord(namespace) || !isDirectiveWord(key) {
+		return directive{}, fmt.Errorf("expected namespace:key with lower-case letters and digits, got %q", name)
+	}
+	switch key {
+	case toleranceAnnotation, unorderedAnnotation, inPlaceAnnotation:
+		return directive{}, fmt.Errorf("%q is the key of an annotation", key)
+	}
+	return directive{prefix: "//" + namespace + ":", test: key}, nil
+}
+
+// isDirectiveWord reports whether s is a non-empty run of lower-case ASCII
+// letters and digits.
+func isDirectiveWord(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns the directive as written in doc comments, e.g.
+// "//leetcode:test".
+func (d directive) String() string {
+	return d.prefix + d.test
+}
+
+// testOptions lists the options of the test directive in the order they are
+// reported in errors.
+var testOptions = []string{compareOption, nameOption, timeoutOption, toleranceOption}
+
+// Tolerance holds the absolute and relative epsilon used when generated tests
+// compare floating-point results.
+type Tolerance struct {
+	Abs float64
+	Rel float64
+}
+
+// defaultTolerance matches the precision LeetCode accepts for floating-point
+// answers.
+var defaultTolerance = Tolerance{Abs: 1e-5, Rel: 1e-5}
+
+// annotation is a single "//leetcode:<key> <value>" doc comment line.
+type annotation struct {
+	Key   string
+	Value string
+
+	pos token.Pos
+}
+
+// annotationsOf collects the annotations found in a doc comment.
+// Lines that do not start with the prefix of the directive are ignored.
+//
+// Parameters:
+//   - doc: The doc comment group, which may be nil
+//
+// Returns:
+//   - []annotation: The annotations in the order they appear
+func (d directive) annotationsOf(doc *ast.CommentGroup) []annotation {
+	if doc == nil {
+		return nil
+	}
+
+	var annotations []annotation
+	for _, comment := range doc.List {
+		text, ok := strings.CutPrefix(comment.Text, d.prefix)
+		if !ok {
+			continue
+		}
+		key, value, _ := strings.Cut(strings.TrimSpace(text), " ")
+		annotations = append(annotations, annotation{
+			Key:   key,
+			Value: strings.TrimSpace(value),
+			pos:   comment.Slash,
+		})
+	}
+	return annotations
+}
+
+// applyAnnotations configures the test function data from the annotations in
+// its doc comment. It returns Diagnostics located at the annotations that are
+// unknown or have malformed values.
+func (d directive) applyAnnotations(fset *token.FileSet, tf *testFuncData, annotations []annotation) error {
+	var ds Diagnostics
+	for _, a := range annotations {
+		var err error
+		switch a.Key {
+		case d.test:
+			err = applyTestOptions(tf, a.Value)
+		case toleranceAnnotation:
+			var tol Tolerance
+			if tol, err = parseTolerance(a.Value); err == nil {
+				tf.Tolerance = tol
+			}
+		case unorderedAnnotation:
+			var levels []int
+			if levels, err = parseLevels(a.Value); err == nil {
+				tf.Unordered = levels
+			}
+		case inPlaceAnnotation:
+			var names []string
+			if names, err = parseParamNames(a.Value, tf.Params); err == nil {
+				tf.InPlace = names
+			}
+		default:
+			ds.add(errorAt(fset, a.pos, "%s: unknown annotation %s%s", tf.FuncName, d.prefix, a.Key))
+			continue
+		}
+		if err != nil {
+			ds.add(errorAt(fset, a.pos, "%s: %s%s: %v", tf.FuncName, d.prefix, a.Key, err))
+		}
+	}
+	return ds.err()
+}
+
+// applyTestOptions configures the test function data from the key=value
+// options of a test directive. It returns an error for unknown options and
+// malformed values.
+//
+// Example:
+//
+//	value: "name=TwoSumHashing timeout=2s compare=unordered tolerance=1e-9"
+func applyTestOptions(tf *testFuncData, value string) error {
+	for _, field := range strings.Fields(value) {
+		key, raw, ok := strings.Cut(field, "=")
+		if !ok || raw == "" {
+			return fmt.Errorf("expected key=value, got %q", field)
+		}
+		switch key {
+		case compareOption:
+			switch raw {
+			case "unordered":
+				tf.Unordered = []int{0}
+			case "exact":
+				tf.Tolerance = Tolerance{}
+			default:
+				return fmt.Errorf("unknown %s mode %q, expected exact or unordered", key, raw)
+			}
+		case toleranceOption:
+			tol, err := parseTolerance(strings.ReplaceAll(raw, ",", " "))
+			if err != nil {
+				return fmt.Errorf("%s: %v", key, err)
+			}
+			tf.Tolerance = tol
+		case timeoutOption:
+			timeout, err := time.ParseDuration(raw)
+			if err != nil || timeout <= 0 {
+				return fmt.Errorf("%s: invalid duration %q", key, raw)
+			}
+			tf.Timeout = timeout
+		case nameOption:
+			if !token.IsIdentifier(raw) {
+				return fmt.Errorf("%s: invalid name %q", key, raw)
+			}
+			tf.Alias = upperFirst(raw)
+		default:
+			return fmt.Errorf("unknown option %q, expected one of %s", key, strings.Join(testOptions, ", "))
+		}
+	}
+	return nil
+}
+
+// timeoutExprOf spells a timeout as a Go expression in the largest unit that
+// divides it, e.g. "1500 * time.Millisecond", or returns an empty string if
+// there is no timeout.
+func timeoutExprOf(d time.Duration) string {
+	if d <= 0 {
+		return ""
+	}
+	units := []struct {
+		unit time.Duration
+		name string
+	}{
+		{time.Hour, "time.Hour"},
+		{time.Minute, "time.Minute"},
+		{time.Second, "time.Second"},
+		{time.Millisecond, "time.Millisecond"},
+		{time.Microsecond, "time.Microsecond"},
+	}
+	for _, u := range units {
+		if d%u.unit == 0 {
+			return fmt.Sprintf("%d * %s", d/u.unit, u.name)
+		}
+	}
+	return fmt.Sprintf("%d * time.Nanosecond", d)
+}
+
+// ParseTolerance parses a tolerance spelled as the value of the tolerance
+// annotation, e.g. "1e-9" or "abs=1e-9 rel=0", see parseTolerance.
+func ParseTolerance(value string) (Tolerance, error) {
+	return parseTolerance(value)
+}
+
+// parseTolerance parses the value of a tolerance annotation.
+// A single number sets both the absolute and the relative epsilon, while
+// "abs=<number>" and "rel=<number>" set them individually; an epsilon that is
+// not mentioned keeps its default.
+//
+// Example:
+//
+//	input: "abs=1e-9 rel=0"
+//	output: Tolerance{Abs: 1e-9, Rel: 0}
+func parseTolerance(value string) (Tolerance, error) {
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return Tolerance{}, fmt.Errorf("missing value")
+	}
+	if len(fields) == 1 && !strings.Contains(fields[0], "=") {
+		eps, err := parseEpsilon(fields[0])
+		if err != nil {
+			return Tolerance{}, err
+		}
+		return Tolerance{Abs: eps, Rel: eps}, nil
+	}
+
+	tol := defaultTolerance
+	for _, field := range fields {
+		key, raw, ok := strings.Cut(field, "=")
+		if !ok {
+			return Tolerance{}, fmt.Errorf("expected abs=<number> or rel=<number>, got %q", field)
+		}
+		eps, err := parseEpsilon(raw)
+		if err != nil {
+			return Tolerance{}, err
+		}
+		switch key {
+		case "abs":
+			tol.Abs = eps
+		case "rel":
+			tol.Rel = eps
+		default:
+			return Tolerance{}, fmt.Errorf("unknown tolerance %q", key)
+		}
+	}
+	return tol, nil
+}
+
+// parseEpsilon parses a non-negative epsilon of a tolerance.
+func parseEpsilon(s string) (float64, error) {
+	eps, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q", s)
+	}
+	if eps < 0 {
+		return 0, fmt.Errorf("negative tolerance %q", s)
+	}
+	return eps, nil
+}
+
+// parseLevels parses the value of an unordered annotation: the nesting levels
+// whose slices are compared as multisets, separated by commas or spaces.
+// An empty value stands for the outermost level only.
+//
+// Example:
+//
+//	input: "1, 0"
+//	output: []int{0, 1}
+func parseLevels(value string) ([]int, error) {
+	fields := strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+	if len(fields) == 0 {
+		return []int{0}, nil
+	}
+
+	seen := make(map[int]bool)
+	levels := make([]int, 0, len(fields))
+	for _, field := range fields {
+		level, err := strconv.Atoi(field)
+		if err != nil || level < 0 {
+			return nil, fmt.Errorf("invalid nesting level %q", field)
+		}
+		if !seen[level] {
+			seen[level] = true
+			levels = append(levels, level)
+		}
+	}
+	sort.Ints(levels)
+	return levels, nil
+}
+
+// parseParamNames parses the value of an inplace annotation: the names of the
+// parameters a function modifies, separated by commas or spaces.
+// An empty value stands for every parameter that can be modified through
+// its value, see mutableParamsOf.
+//
+// Named parameters must be among them.
+//
+// Example:
+//
+//	input: "nums, board"
+//	output: []string{"nums", "board"}
+func parseParamNames(value string, params []fieldInfo) ([]string, error) {
+	fields := strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+	if len(fields) == 0 {
+		names := mutableParamsOf(params)
+		if len(names) == 0 {
+			return nil, fmt.Errorf("no parameter can be modified in place")
+		}
+		return names, nil
+	}
+
+	mutable := make(map[string]bool)
+	for _, name := range mutableParamsOf(params) {
+		mutable[name] = true
+	}
+	names := make([]string, 0, len(fields))
+	for _, field := range fields {
+		var param *fieldInfo
+		for i := range params {
+			if params[i].Name == field {
+				param = &params[i]
+			}
+		}
+		switch {
+		case param == nil:
+			return nil, fmt.Errorf("unknown parameter %q", field)
+		// Parameters of unresolved types are reported where they are skipped
+		case param.typ != nil && !mutable[field]:
+			return nil, fmt.Errorf("parameter %q cannot be modified in place, only slices, maps and pointers can", field)
+		}
+		names = append(names, field)
+	}
+	return names, nil
+}
@@ -0,0 +1,223 @@
+package codegen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAnnotationsOf(t *testing.T) {
+	doc := &ast.CommentGroup{List: []*ast.Comment{
+		{Text: "// myPow computes x^n."},
+		{Text: "//go:generate leetcode-gen-test init $GOFILE"},
+		{Text: "//leetcode:tolerance 1e-9"},
+		{Text: "//leetcode:tolerance  abs=1e-3 rel=0 "},
+	}}
+	expected := []annotation{
+		{Key: "tolerance", Value: "1e-9"},
+		{Key: "tolerance", Value: "abs=1e-3 rel=0"},
+	}
+
+	result := defaultDirective.annotationsOf(doc)
+	if len(result) != len(expected) {
+		t.Fatalf("annotationsOf() = %v; expected %v", result, expected)
+	}
+	for i := range result {
+		if result[i] != expected[i] {
+			t.Errorf("annotationsOf()[%d] = %v; expected %v", i, result[i], expected[i])
+		}
+	}
+	if result := defaultDirective.annotationsOf(nil); len(result) != 0 {
+		t.Errorf("annotationsOf(nil) = %v; expected none", result)
+	}
+}
+
+func TestApplyTestOptions(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected testFuncData
+		err      string
+	}{
+		{"", testFuncData{Tolerance: defaultTolerance}, ""},
+		{"compare=unordered", testFuncData{Tolerance: defaultTolerance, Unordered: []int{0}}, ""},
+		{"compare=exact", testFuncData{}, ""},
+		{"tolerance=abs=1e-9,rel=0", testFuncData{Tolerance: Tolerance{Abs: 1e-9}}, ""},
+		{"timeout=1500ms  name=twoSumHashing", testFuncData{Tolerance: defaultTolerance, Timeout: 1500 * time.Millisecond, Alias: "TwoSumHashing"}, ""},
+		{"compare=sorted", testFuncData{}, `unknown compare mode "sorted"`},
+		{"tolerance=abs", testFuncData{}, "tolerance: "},
+		{"timeout=-1s", testFuncData{}, `timeout: invalid duration "-1s"`},
+		{"name=two-sum", testFuncData{}, `name: invalid name "two-sum"`},
+		{"timeout", testFuncData{}, `expected key=value, got "timeout"`},
+		{"retries=3", testFuncData{}, `unknown option "retries", expected one of compare, name, timeout, tolerance`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			tf := testFuncData{Tolerance: defaultTolerance}
+			err := applyTestOptions(&tf, test.value)
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("applyTestOptions(%q) error = %v; expected %q", test.value, err, test.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("applyTestOptions(%q) error = %v", test.value, err)
+			}
+			if fmt.Sprint(tf) != fmt.Sprint(test.expected) {
+				t.Errorf("applyTestOptions(%q) = %+v; expected %+v", test.value, tf, test.expected)
+			}
+		})
+	}
+}
+
+func TestTimeoutExprOf(t *testing.T) {
+	tests := []struct {
+		timeout  time.Duration
+		expected string
+	}{
+		{0, ""},
+		{2 * time.Second, "2 * time.Second"},
+		{1500 * time.Millisecond, "1500 * time.Millisecond"},
+		{90 * time.Minute, "90 * time.Minute"},
+		{time.Nanosecond, "1 * time.Nanosecond"},
+	}
+
+	for _, test := range tests {
+		if result := timeoutExprOf(test.timeout); result != test.expected {
+			t.Errorf("timeoutExprOf(%v) = %q; expected %q", test.timeout, result, test.expected)
+		}
+	}
+}
+
+func TestParseTolerance(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected Tolerance
+		wantErr  bool
+	}{
+		{"1e-9", Tolerance{Abs: 1e-9, Rel: 1e-9}, false},
+		{"abs=1e-3", Tolerance{Abs: 1e-3, Rel: defaultTolerance.Rel}, false},
+		{"rel=0", Tolerance{Abs: defaultTolerance.Abs, Rel: 0}, false},
+		{"abs=1e-3 rel=1e-6", Tolerance{Abs: 1e-3, Rel: 1e-6}, false},
+		{"", Tolerance{}, true},
+		{"abc", Tolerance{}, true},
+		{"-1", Tolerance{}, true},
+		{"1e-3 1e-6", Tolerance{}, true},
+		{"eps=1e-3", Tolerance{}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			result, err := parseTolerance(test.value)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("parseTolerance(%q) error = %v; wantErr %v", test.value, err, test.wantErr)
+			}
+			if result != test.expected {
+				t.Errorf("parseTolerance(%q) = %v; expected %v", test.value, result, test.expected)
+			}
+		})
+	}
+}
+
+func TestParseLevels(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected []int
+		wantErr  bool
+	}{
+		{"", []int{0}, false},
+		{"0", []int{0}, false},
+		{"0,1", []int{0, 1}, false},
+		{"1, 0", []int{0, 1}, false},
+		{"1 1", []int{1}, false},
+		{"-1", nil, true},
+		{"outer", nil, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			result, err := parseLevels(test.value)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("parseLevels(%q) error = %v; wantErr %v", test.value, err, test.wantErr)
+			}
+			if fmt.Sprint(result) != fmt.Sprint(test.expected) {
+				t.Errorf("parseLevels(%q) = %v; expected %v", test.value, result, test.expected)
+			}
+		})
+	}
+}
+
+func TestParseParamNames(t *testing.T) {
+	params := []fieldInfo{
+		{Name: "nums", typ: types.NewSlice(types.Typ[types.Int])},
+		{Name: "k", typ: types.Typ[types.Int]},
+		{Name: "grid", typ: types.NewSlice(types.NewSlice(types.Typ[types.Byte]))},
+	}
+	tests := []struct {
+		value    string
+		expected []string
+		wantErr  bool
+	}{
+		{"", []string{"nums", "grid"}, false},
+		{"nums", []string{"nums"}, false},
+		{"nums, grid", []string{"nums", "grid"}, false},
+		{"nums, k", nil, true},
+		{"board", nil, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			result, err := parseParamNames(test.value, params)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("parseParamNames(%q) error = %v; wantErr %v", test.value, err, test.wantErr)
+			}
+			if fmt.Sprint(result) != fmt.Sprint(test.expected) {
+				t.Errorf("parseParamNames(%q) = %v; expected %v", test.value, result, test.expected)
+			}
+		})
+	}
+
+	if _, err := parseParamNames("", params[1:2]); err == nil {
+		t.Errorf("parseParamNames() without mutable parameters succeeded; expected an error")
+	}
+}
+
+func TestParseDirective(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+		err      bool
+	}{
+		{"leetcode:test", "//leetcode:test", false},
+		{"lc:gen2", "//lc:gen2", false},
+		{"lc", "", true},
+		{"lc:", "", true},
+		{"LC:gen", "", true},
+		{"lc:gen:x", "", true},
+		{"lc:unordered", "", true},
+	}
+
+	for _, test := range tests {
+		result, err := parseDirective(test.name)
+		if (err != nil) != test.err {
+			t.Errorf("parseDirective(%q) error = %v; expected an error: %v", test.name, err, test.err)
+			continue
+		}
+		if err == nil && result.String() != test.expected {
+			t.Errorf("parseDirective(%q) = %s; expected %s", test.name, result, test.expected)
+		}
+	}
+
+	d, _ := parseDirective("lc:gen")
+	doc := &ast.CommentGroup{List: []*ast.Comment{
+		{Text: "//leetcode:tolerance 1e-9"},
+		{Text: "//lc:unordered"},
+	}}
+	if result := d.annotationsOf(doc); len(result) != 1 || result[0].Key != "unordered" {
+		t.Errorf("annotationsOf() = %v; expected only the unordered annotation", result)
+	}
+}
@@ -0,0 +1,428 @@
+package codegen
+
+import (
+	"fmt"
+	"go/token"
+	"io"
+	"io/fs"
+	"path"
+	"time"
+
+	"github.com/Ezer015/leetcode-gen-test/utils"
+)
+
+// Comparison modes of Options.Compare, as set by the compare option of the
+// test directive.
+const (
+	// CompareExact compares floating-point results without a tolerance.
+	CompareExact = "exact"
+	// CompareUnordered compares the outermost slices of results as
+	// multisets, for problems accepting answers in any order.
+	CompareUnordered = "unordered"
+)
+
+// Options configures a Generator. The zero value generates the files the
+// command line tool does, reading the files from disk.
+type Options struct {
+	// FS is the file system the source and test case files are read from,
+	// along with the other files of their packages and modules, with
+	// slash-separated paths relative to its root. If nil, files are read
+	// from disk with the paths of the operating system.
+	FS fs.FS
+	// Naming is the naming scheme of test case files and types. Patterns
+	// that are not set follow utils.DefaultNaming.
+	Naming utils.Naming
+	// Directive is the name of the test directive tagging declarations for
+	// testing, "namespace:key", which the annotations share the namespace
+	// of, or empty for "leetcode:test".
+	Directive string
+	// Compare is the comparison mode of the results of functions and design
+	// methods whose test directive does not set one: CompareExact,
+	// CompareUnordered, or empty to compare floats within Tolerance.
+	// Unordered comparison only applies to results holding slices.
+	Compare string
+	// Tolerance is the tolerance of floating-point results unless an
+	// annotation sets another, or nil for the precision LeetCode accepts.
+	Tolerance *Tolerance
+	// Templates replace the code generated for functions, see Templates.
+	Templates Templates
+}
+
+// Generator extracts the functions and types tagged for testing from source
+// files and the test cases written for them, and generates test case
+// templates and tests from them. A Generator is safe for concurrent use.
+type Generator struct {
+	fsys      fileSystem
+	naming    utils.Naming
+	directive directive
+	// defaults holds the tolerance and unordered levels of functions and
+	// design types that their annotations do not override.
+	defaults  testFuncData
+	templates Templates
+	// packages caches the packages imported by the extracted files.
+	packages *packageCache
+}
+
+// defaultGenerator backs the package-level functions, with the zero Options.
+var defaultGenerator = &Generator{
+	naming:    utils.DefaultNaming,
+	directive: defaultDirective,
+	defaults:  testFuncData{Tolerance: defaultTolerance},
+	packages:  newPackageCache(),
+}
+
+// NewGenerator creates a generator.
+//
+// Parameters:
+//   - opts: The options of the generator
+//
+// Returns:
+//   - *Generator: The generator
+//   - error: An error if the naming scheme or the directive is malformed, or
+//     the comparison mode or tolerance is invalid
+func NewGenerator(opts Options) (*Generator, error) {
+	g := &Generator{
+		fsys:      fileSystem{fsys: opts.FS},
+		naming:    opts.Naming.WithDefaults(),
+		directive: defaultDirective,
+		defaults:  testFuncData{Tolerance: defaultTolerance},
+		templates: opts.Templates,
+		packages:  newPackageCache(),
+	}
+	if err := g.naming.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid naming: %v", err)
+	}
+	if opts.Directive != "" {
+		d, err := parseDirective(opts.Directive)
+		if err != nil {
+			return nil, fmt.Errorf("invalid directive: %v", err)
+		}
+		g.directive = d
+	}
+	if opts.Tolerance != nil {
+		if opts.Tolerance.Abs < 0 || opts.Tolerance.Rel < 0 {
+			return nil, fmt.Errorf("invalid tolerance: negative epsilon")
+		}
+		g.defaults.Tolerance = *opts.Tolerance
+	}
+	if opts.Compare != "" {
+		if err := applyTestOptions(&g.defaults, compareOption+"="+opts.Compare); err != nil {
+			return nil, fmt.Errorf("invalid comparison: %v", err)
+		}
+	}
+	return g, nil
+}
+
+// Input is a file read by a Generator.
+type Input struct {
+	// Path is the path of the file in the file system of the generator,
+	// which locates the other files of its package and its module. It may
+	// be empty for files standing on their own, whose generated files are
+	// then left unnamed.
+	Path string
+	// Content is read for the content of the file if it is not nil, taking
+	// precedence over the file in the file system.
+	Content io.Reader
+}
+
+// read returns the path and the content of an input.
+func (g *Generator) read(in Input) (string, []byte, error) {
+	name := in.Path
+	if name != "" && g.fsys.fsys != nil {
+		name = path.Clean(name)
+	}
+	if in.Content != nil {
+		content, err := io.ReadAll(in.Content)
+		if err != nil {
+			return "", nil, fmt.Errorf("reading %s: %v", in.Path, err)
+		}
+		return name, content, nil
+	}
+	if name == "" {
+		return "", nil, fmt.Errorf("input has neither a path nor content")
+	}
+	content, err := g.fsys.readFile(name)
+	if err != nil {
+		return "", nil, fmt.Errorf("reading %s: %v", in.Path, err)
+	}
+	return name, content, nil
+}
+
+// Result is the outcome of a generation.
+type Result struct {
+	// Files holds the generated files.
+	Files []File
+	// Diagnostics holds the warnings about problems that did not prevent
+	// the generation, such as type errors elsewhere in the package.
+	Diagnostics Diagnostics
+	// Package is the name of the package of the source file.
+	Package string
+	// Functions and Designs describe the declarations of the source file
+	// tagged for testing.
+	Functions []Function
+	Designs   []Design
+	// TestCases groups the test cases of the test case file by the function
+	// or design type they test, if a test case file was read.
+	TestCases []TestCases
+}
+
+// File is a generated file.
+type File struct {
+	// Name is the path of the file, next to the source file and named after
+	// it, or empty if the source file has no path.
+	Name    string
+	Content []byte
+}
+
+// Field is a parameter, result or type parameter of a function.
+type Field struct {
+	Name string
+	// Type is the type spelled as Go source, with the types of other
+	// packages qualified with their package names, or the constraint of
+	// type parameters.
+	Type string
+	// Notation reports whether test cases spell the value as a string in
+	// LeetCode notation, as for linked lists and trees.
+	Notation bool
+	// NotationExample shows how a value spelled in LeetCode notation looks,
+	// as a quoted Go string, e.g. `"[1,2,3]"`.
+	NotationExample string
+}
+
+// SchemaType returns the type of the field in test case types: a string for
+// values spelled in LeetCode notation, or else the type itself.
+func (f Field) SchemaType() string {
+	if f.Notation {
+		return "string"
+	}
+	return f.Type
+}
+
+// Function is a function or method tagged for testing.
+type Function struct {
+	// Name is the name of the function as declared, e.g. "twoSum".
+	Name string
+	// Receiver is the name of the type the function is a method of, e.g.
+	// "Solution", or empty for plain functions.
+	Receiver string
+	// CaseName is the name the test case types and the test are derived
+	// from, e.g. "SolutionTwoSum".
+	CaseName   string
+	Params     []Field
+	Results    []Field
+	TypeParams []Field
+	// Outputs are the values test cases expect: the results followed by
+	// the parameters modified in place.
+	Outputs []Field
+	// InPlace names the parameters modified in place.
+	InPlace []string
+	// Tolerance is the tolerance of floating-point outputs.
+	Tolerance Tolerance
+	// Unordered lists the nesting levels of the slices compared as
+	// multisets, starting at 0 for the outermost slice.
+	Unordered []int
+	// Timeout limits the time each test case may take, or is zero.
+	Timeout time.Duration
+	// Pos is the position of the function name in the source file.
+	Pos token.Position
+}
+
+// Design is a type tagged for testing as a LeetCode design problem, tested
+// through sequences of calls to its constructor and methods.
+type Design struct {
+	// Name is the name of the type, e.g. "LRUCache".
+	Name string
+	// CaseName is the name the test case types and the test are derived
+	// from.
+	CaseName    string
+	Constructor Operation
+	Methods     []Operation
+	Tolerance   Tolerance
+	Unordered   []int
+	Timeout     time.Duration
+	// Pos is the position of the type name in the source file.
+	Pos token.Position
+}
+
+// Operation is the constructor or a method of a design problem.
+type Operation struct {
+	// Op is the name LeetCode gives the operation: the type name for the
+	// constructor, and the method name starting with a lower-case letter for
+	// methods.
+	Op string
+	// Name is the name of the function or method as declared.
+	Name    string
+	Params  []Field
+	Results []Field
+}
+
+// TestCases are the test cases of a function or design type.
+type TestCases struct {
+	// CaseName is the name the test case types of the cases are derived
+	// from, which matches the CaseName of a Function or Design, or the name
+	// of a plain function.
+	CaseName string
+	// Checker is the name of the function checking the outputs of the
+	// cases, or empty if they are compared with the expected outputs.
+	Checker string
+	Cases   []Case
+}
+
+// Case is a single test case.
+type Case struct {
+	// Name is the name of the variable holding the case.
+	Name string
+	// Desc is the name the case is run under.
+	Desc string
+	// OutputType is the type of the output field of the case, which the
+	// checker of the case takes.
+	OutputType string
+}
+
+// GenerateTestCases extracts the functions and types tagged for testing from
+// a source file and generates their test case templates.
+//
+// Parameters:
+//   - src: The source file
+//
+// Returns:
+//   - *Result: The test case file, along with the tagged declarations and
+//     the warnings about the package
+//   - error: An error if the source file cannot be read, or Diagnostics,
+//     possibly wrapped, locating the problems that prevent the generation
+func (g *Generator) GenerateTestCases(src Input) (*Result, error) {
+	srcFile, content, err := g.read(src)
+	if err != nil {
+		return nil, err
+	}
+	tfMetadata, err := g.extractTestFuncs(srcFile, content)
+	if err != nil {
+		return nil, fmt.Errorf("extracting test function: %w", err)
+	}
+	code, err := g.testCaseFileOf(tfMetadata)
+	if err != nil {
+		return nil, err
+	}
+
+	result := resultOf(tfMetadata)
+	result.Files = []File{{Name: g.naming.TestCaseFileNameOf(srcFile), Content: code}}
+	return result, nil
+}
+
+// GenerateTests extracts the functions and types tagged for testing from a
+// source file and the test cases written for them, and generates the tests
+// running the cases.
+//
+// Parameters:
+//   - src: The source file
+//   - testCases: The test case file
+//
+// Returns:
+//   - *Result: The test file, along with the tagged declarations, the test
+//     cases and the warnings about the source package
+//   - error: An error if a file cannot be read, or Diagnostics, possibly
+//     wrapped, locating the problems that prevent the generation
+func (g *Generator) GenerateTests(src, testCases Input) (*Result, error) {
+	srcFile, srcContent, err := g.read(src)
+	if err != nil {
+		return nil, err
+	}
+	testCaseFile, testCaseContent, err := g.read(testCases)
+	if err != nil {
+		return nil, err
+	}
+	tfMetadata, err := g.extractTestFuncs(srcFile, srcContent)
+	if err != nil {
+		return nil, fmt.Errorf("extracting test function: %w", err)
+	}
+	tcMetadata, err := g.extractTestCases(testCaseFile, testCaseContent)
+	if err != nil {
+		return nil, fmt.Errorf("extracting test cases: %w", err)
+	}
+	code, err := g.testFileOf(tfMetadata, tcMetadata)
+	if err != nil {
+		return nil, err
+	}
+
+	result := resultOf(tfMetadata)
+	result.Files = []File{{Name: utils.TestFileNameOf(srcFile), Content: code}}
+	for _, tc := range tcMetadata.testCases {
+		result.TestCases = append(result.TestCases, TestCases{CaseName: tc.FuncName, Checker: tc.Checker, Cases: casesOf(tc.Cases)})
+	}
+	return result, nil
+}
+
+// resultOf describes the tagged declarations of a source file in a result.
+func resultOf(tfMetadata *testFuncMetadata) *Result {
+	result := &Result{Package: tfMetadata.pkgName, Diagnostics: tfMetadata.warnings}
+	for _, tf := range tfMetadata.testFuncs {
+		result.Functions = append(result.Functions, functionOf(tf))
+	}
+	for _, d := range tfMetadata.designs {
+		design := Design{
+			Name:        d.TypeName,
+			CaseName:    upperFirst(d.TypeName),
+			Constructor: operationOf(d.Constructor),
+			Tolerance:   d.Tolerance,
+			Unordered:   d.Unordered,
+			Timeout:     d.Timeout,
+			Pos:         d.pos,
+		}
+		for _, m := range d.Methods {
+			design.Methods = append(design.Methods, operationOf(m))
+		}
+		result.Designs = append(result.Designs, design)
+	}
+	return result
+}
+
+// functionOf describes a test function in the exported model.
+func functionOf(tf testFuncData) Function {
+	return Function{
+		Name:       tf.FuncName,
+		Receiver:   tf.Receiver,
+		CaseName:   tf.CaseName(),
+		Params:     fieldsOf(tf.Params),
+		Results:    fieldsOf(tf.Results),
+		TypeParams: fieldsOf(tf.Generics),
+		Outputs:    fieldsOf(tf.Outputs()),
+		InPlace:    tf.InPlace,
+		Tolerance:  tf.Tolerance,
+		Unordered:  tf.Unordered,
+		Timeout:    tf.Timeout,
+		Pos:        tf.pos,
+	}
+}
+
+// casesOf describes test cases in the exported model.
+func casesOf(cases []testCaseInfo) []Case {
+	if len(cases) == 0 {
+		return nil
+	}
+	result := make([]Case, len(cases))
+	for i, c := range cases {
+		result[i] = Case{Name: c.Name, Desc: c.Desc, OutputType: c.OutputType}
+	}
+	return result
+}
+
+// fieldsOf describes fields in the exported model.
+func fieldsOf(fields []fieldInfo) []Field {
+	if len(fields) == 0 {
+		return nil
+	}
+	result := make([]Field, len(fields))
+	for i, f := range fields {
+		result[i] = Field{Name: f.Name, Type: f.Type, Notation: f.Notation()}
+		if result[i].Notation {
+			result[i].NotationExample = f.NotationExample()
+		}
+	}
+	return result
+}
+
+// operationOf describes an operation of a design problem in the exported
+// model.
+func operationOf(op designOp) Operation {
+	return Operation{Op: op.Op, Name: op.FuncName, Params: fieldsOf(op.Params), Results: fieldsOf(op.Results)}
+}
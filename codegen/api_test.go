@@ -0,0 +1,115 @@
+package codegen
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/Ezer015/leetcode-gen-test/utils"
+)
+
+func TestGenerator(t *testing.T) {
+	fsys := fstest.MapFS{
+		"go.mod": {Data: []byte("module example.com/lc\n\ngo 1.23\n")},
+		"list/list.go": {Data: []byte(`package list
+
+type ListNode struct {
+	Val  int
+	Next *ListNode
+}
+`)},
+		"p/sol.go": {Data: []byte(`package p
+
+import "example.com/lc/list"
+
+//leetcode:test
+func groupAnagrams(strs []string) [][]string { return nil }
+
+//leetcode:test
+func reverseList(head *list.ListNode) *list.ListNode { return head }
+`)},
+	}
+
+	g, err := NewGenerator(Options{
+		FS:      fsys,
+		Naming:  utils.Naming{TestCaseFile: "cases_<name>.go", TestCaseType: "<func>Case"},
+		Compare: CompareUnordered,
+	})
+	if err != nil {
+		t.Fatalf("NewGenerator() error = %v", err)
+	}
+
+	result, err := g.GenerateTestCases(Input{Path: "p/sol.go"})
+	if err != nil {
+		t.Fatalf("GenerateTestCases() error = %v", err)
+	}
+	if len(result.Files) != 1 || result.Files[0].Name != "p/cases_sol.go" {
+		t.Fatalf("GenerateTestCases() files = %+v; expected p/cases_sol.go", result.Files)
+	}
+	if !bytes.Contains(result.Files[0].Content, []byte("type GroupAnagramsCase struct")) {
+		t.Errorf("GenerateTestCases() did not follow the naming scheme:\n%s", result.Files[0].Content)
+	}
+	if len(result.Functions) != 2 || result.Package != "p" {
+		t.Fatalf("GenerateTestCases() functions = %+v; expected groupAnagrams and reverseList", result.Functions)
+	}
+	if f := result.Functions[0]; f.CaseName != "GroupAnagrams" || len(f.Unordered) != 1 || f.Pos.Filename != "p/sol.go" {
+		t.Errorf("GenerateTestCases() function = %+v; expected groupAnagrams compared unordered", f)
+	}
+	if f := result.Functions[1]; f.Unordered != nil || !f.Params[0].Notation || f.Params[0].Type != "*list.ListNode" {
+		t.Errorf("GenerateTestCases() function = %+v; expected reverseList taking a list in LeetCode notation", f)
+	}
+
+	testCases := string(result.Files[0].Content) + `
+var (
+	example = GroupAnagramsCase{
+		input:  testGroupAnagramsInput{strs: []string{"ab", "ba"}},
+		output: testGroupAnagramsOutput{field0: [][]string{{"ab", "ba"}}},
+	}
+	empty = ReverseListCase{
+		name:   "empty list",
+		input:  testReverseListInput{head: "[]"},
+		output: testReverseListOutput{field0: "[]"},
+	}
+)
+`
+	fsys["p/cases_sol.go"] = &fstest.MapFile{Data: []byte(testCases)}
+
+	result, err = g.GenerateTests(Input{Path: "p/sol.go"}, Input{Path: "p/cases_sol.go", Content: strings.NewReader(testCases)})
+	if err != nil {
+		t.Fatalf("GenerateTests() error = %v", err)
+	}
+	if len(result.Files) != 1 || result.Files[0].Name != "p/sol_test.go" {
+		t.Fatalf("GenerateTests() files = %+v; expected p/sol_test.go", result.Files)
+	}
+	for _, expected := range []string{"func TestGroupAnagrams(t *testing.T)", "lctest.Diff(field0, example.output.field0, lctest.Unordered(0))", `t.Run("empty list"`} {
+		if !bytes.Contains(result.Files[0].Content, []byte(expected)) {
+			t.Errorf("GenerateTests() generated\n%s\nexpected it to contain %q", result.Files[0].Content, expected)
+		}
+	}
+	if len(result.TestCases) != 2 || result.TestCases[1].CaseName != "ReverseList" || result.TestCases[1].Cases[0].Desc != "empty list" {
+		t.Errorf("GenerateTests() test cases = %+v; expected the cases of both functions", result.TestCases)
+	}
+}
+
+func TestNewGenerator(t *testing.T) {
+	tests := []struct {
+		opts Options
+		err  bool
+	}{
+		{Options{}, false},
+		{Options{Compare: CompareExact, Tolerance: &Tolerance{Abs: 1e-9}}, false},
+		{Options{Compare: "sorted"}, true},
+		{Options{Tolerance: &Tolerance{Abs: -1}}, true},
+		{Options{Naming: utils.Naming{TestCaseType: "case"}}, true},
+		{Options{Directive: "lc:gen"}, false},
+		{Options{Directive: "lc"}, true},
+		{Options{Directive: "lc:tolerance"}, true},
+	}
+
+	for _, test := range tests {
+		if _, err := NewGenerator(test.opts); (err != nil) != test.err {
+			t.Errorf("NewGenerator(%+v) error = %v; expected an error: %v", test.opts, err, test.err)
+		}
+	}
+}
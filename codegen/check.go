@@ -0,0 +1,114 @@
+package codegen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+)
+
+// CheckTestCaseSchema reports the schema types of a test case file, such as
+// test<Func>Input, test<Func>Output and test<Func>Case, that no longer match
+// the signatures in the source file, e.g. after a parameter was added or its
+// type changed. The types are compared with those a fresh test case template
+// would declare; schema types of functions without test cases are not
+// required.
+//
+// Parameters:
+//   - srcFile: The path of the source file
+//   - srcContent: The content of the source file
+//   - testCaseFile: The path of the test case file
+//   - testCaseContent: The content of the test case file
+//
+// Returns:
+//   - []string: A description of each stale schema type, empty if all are up to date
+//   - error: An error if either file cannot be processed
+func (g *Generator) CheckTestCaseSchema(srcFile string, srcContent []byte, testCaseFile string, testCaseContent []byte) ([]string, error) {
+	templates, _, err := g.GenerateTestCaseTemplates(srcFile, srcContent)
+	if err != nil {
+		return nil, fmt.Errorf("generating test case templates: %w", err)
+	}
+
+	fset := token.NewFileSet()
+	expectedFile, err := parser.ParseFile(fset, "", templates, 0)
+	if err != nil {
+		return nil, fmt.Errorf("parsing test case templates: %v", err)
+	}
+	actualFile, err := parser.ParseFile(fset, testCaseFile, testCaseContent, 0)
+	if err != nil {
+		return nil, DiagnosticsOf(err)
+	}
+
+	expected, actual := schemaTypesOf(expectedFile), schemaTypesOf(actualFile)
+	var names []string
+	for name := range expected {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var stale []string
+	for _, name := range names {
+		got, ok := actual[name]
+		if !ok || got == expected[name] {
+			continue
+		}
+		stale = append(stale, fmt.Sprintf("%s is stale: declared as %s, the signature requires %s", name, got, expected[name]))
+	}
+	return stale, nil
+}
+
+// CheckTestCaseSchema reports the stale schema types of a test case file with
+// the default options, see Generator.CheckTestCaseSchema.
+func CheckTestCaseSchema(srcFile string, srcContent []byte, testCaseFile string, testCaseContent []byte) ([]string, error) {
+	return defaultGenerator.CheckTestCaseSchema(srcFile, srcContent, testCaseFile, testCaseContent)
+}
+
+// schemaTypesOf renders the type declarations of a file in a canonical form
+// that ignores comments and formatting, keyed by type name.
+//
+// Example:
+//
+//	input: type testSumInput[T int | float64] struct{ a, b T }
+//	output: {"testSumInput": "[T int | float64] struct{a T; b T}"}
+func schemaTypesOf(f *ast.File) map[string]string {
+	schemaTypes := make(map[string]string)
+	for _, decl := range f.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range genDecl.Specs {
+			typeSpec := spec.(*ast.TypeSpec)
+			var sb strings.Builder
+			if typeSpec.TypeParams != nil {
+				sb.WriteString("[" + fieldsString(typeSpec.TypeParams, ", ") + "] ")
+			}
+			if structType, ok := typeSpec.Type.(*ast.StructType); ok {
+				sb.WriteString("struct{" + fieldsString(structType.Fields, "; ") + "}")
+			} else {
+				sb.WriteString(types.ExprString(typeSpec.Type))
+			}
+			schemaTypes[typeSpec.Name.Name] = sb.String()
+		}
+	}
+	return schemaTypes
+}
+
+// fieldsString renders a field list with one name per field, joined by sep,
+// so that "a, b int" and "a int; b int" compare equal.
+func fieldsString(fields *ast.FieldList, sep string) string {
+	var parts []string
+	for _, field := range fields.List {
+		typ := types.ExprString(field.Type)
+		if len(field.Names) == 0 {
+			parts = append(parts, typ)
+		}
+		for _, name := range field.Names {
+			parts = append(parts, name.Name+" "+typ)
+		}
+	}
+	return strings.Join(parts, sep)
+}
@@ -0,0 +1,73 @@
+package codegen
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCheckTestCaseSchema(t *testing.T) {
+	const src = `package p
+
+//go:generate leetcode-gen-test
+func maxOf[T int | float64](a, b T) T { return max(a, b) }
+
+//go:generate leetcode-gen-test
+func twoSum(nums []int, target int) []int { return nil }
+
+//go:generate leetcode-gen-test
+func reverse(s []byte) {}
+`
+	tests := []struct {
+		name     string
+		testCase string
+		expected []string
+	}{
+		{
+			name: "up to date",
+			testCase: `package p
+
+var basic = testTwoSumCase{input: testTwoSumInput{nums: []int{2, 7}, target: 9}}
+
+type testMaxOfInput[T int | float64] struct{ a, b T }
+
+type testTwoSumInput struct {
+	nums   []int // Comments and layout do not matter
+	target int
+}
+type testTwoSumOutput struct{ field0 []int }
+type testTwoSumCase struct {
+	name   string
+	input  testTwoSumInput
+	output testTwoSumOutput
+}
+`,
+		},
+		{
+			name: "stale",
+			testCase: `package p
+
+type testMaxOfInput[T int] struct{ a, b T }
+type testTwoSumInput struct{ nums []int }
+type testTwoSumOutput struct{ field0 []int }
+type testReverseOutput struct{ field0 []byte }
+`,
+			expected: []string{
+				"testMaxOfInput is stale: declared as [T int] struct{a T; b T}, the signature requires [T int | float64] struct{a T; b T}",
+				"testReverseOutput is stale: declared as struct{field0 []byte}, the signature requires struct{s []byte}",
+				"testTwoSumInput is stale: declared as struct{nums []int}, the signature requires struct{nums []int; target int}",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := CheckTestCaseSchema("", []byte(src), "p_testcase.go", []byte(tt.testCase))
+			if err != nil {
+				t.Fatalf("CheckTestCaseSchema() error = %v", err)
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("CheckTestCaseSchema() = %q; expected %q", result, tt.expected)
+			}
+		})
+	}
+}
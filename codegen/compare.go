@@ -0,0 +1,329 @@
+package codegen
+
+import (
+	"fmt"
+	"go/types"
+	"strconv"
+	"strings"
+)
+
+// comparison describes how a generated test compares an actual value with
+// the expected one.
+type comparison int
+
+const (
+	// compareEqual compares the values with the == operator.
+	compareEqual comparison = iota
+	// compareDeep compares the values structurally with lctest.Equal, which
+	// unlike reflect.DeepEqual takes nil and empty slices and maps to be
+	// equal, as LeetCode spells both as [].
+	compareDeep
+	// compareTolerant compares the values structurally with lctest.Equal,
+	// allowing floating-point numbers to differ within a tolerance.
+	compareTolerant
+	// compareUnordered compares the values with lctest.Diff, treating the
+	// slices at some nesting levels as multisets.
+	compareUnordered
+)
+
+// String returns the operator or function comparing the values, e.g.
+// "lctest.Equal".
+func (c comparison) String() string {
+	switch c {
+	case compareDeep, compareTolerant:
+		return "lctest.Equal"
+	case compareUnordered:
+		return "lctest.Diff"
+	}
+	return "=="
+}
+
+// Prefixes of the local variables declared by generated tests.
+const (
+	// mutatedVarPrefix prefixes the local copies of parameters modified in place.
+	mutatedVarPrefix = "got"
+	// decodedVarPrefix prefixes parameters decoded from LeetCode notation.
+	decodedVarPrefix = "in"
+	// expectedVarPrefix prefixes expected values decoded from LeetCode notation.
+	expectedVarPrefix = "want"
+)
+
+// resultCheck describes how a generated test checks a single result against
+// the expected value.
+type resultCheck struct {
+	// Name is the name of the field holding the expected value.
+	Name string
+	// Var is the name of the local variable holding the actual value.
+	Var string
+	// Type is the Go type of the actual value.
+	Type string
+	// Notation is set if the expected value is spelled in LeetCode notation.
+	// It is then decoded into the local variable named by Want.
+	Notation   bool
+	Want       string
+	Comparison comparison
+	// Options holds the lctest options passed along with the values, e.g.
+	// "lctest.Unordered(0)".
+	Options []string
+
+	imports []string
+}
+
+// IsEqual reports whether the result is compared with the == operator.
+func (c resultCheck) IsEqual() bool { return c.Comparison == compareEqual }
+
+// IsDeep reports whether the result is compared with lctest.Equal without
+// options.
+func (c resultCheck) IsDeep() bool { return c.Comparison == compareDeep }
+
+// IsTolerant reports whether the result is compared with lctest.Equal.
+func (c resultCheck) IsTolerant() bool { return c.Comparison == compareTolerant }
+
+// IsUnordered reports whether the result is compared with lctest.Diff.
+func (c resultCheck) IsUnordered() bool { return c.Comparison == compareUnordered }
+
+// resultChecksOf works out how the generated test checks each output of the
+// test function, combining the output types with the function annotations.
+// The actual values of results are held in variables named after them, and
+// those of parameters modified in place in variables prefixed with "got".
+//
+// Parameters:
+//   - tf: The test function data
+//
+// Returns:
+//   - []resultCheck: One check per output, in the order of tf.Outputs()
+//   - error: An error if the unordered levels do not apply to any output
+func resultChecksOf(tf testFuncData) ([]resultCheck, error) {
+	outputs := tf.Outputs()
+	checks := make([]resultCheck, 0, len(outputs))
+	unorderedApplied := false
+	for i, r := range outputs {
+		check := resultCheckOf(r, tf.Tolerance, tf.Unordered)
+		if i >= len(tf.Results) {
+			check.Var = mutatedVarPrefix + upperFirst(r.Name)
+		}
+		unorderedApplied = unorderedApplied || check.IsUnordered()
+		checks = append(checks, check)
+	}
+
+	if len(tf.Unordered) > 0 && !unorderedApplied {
+		return nil, fmt.Errorf("%s: no output nests slices deep enough for unordered level %d", tf.FuncName, tf.Unordered[len(tf.Unordered)-1])
+	}
+	return checks, nil
+}
+
+// unorderedApplies reports whether unordered levels apply to any of the
+// outputs, which takes slices nested deeper than the deepest level.
+func unorderedApplies(outputs []fieldInfo, unordered []int) bool {
+	if len(unordered) == 0 {
+		return false
+	}
+	for _, o := range outputs {
+		if o.typ != nil && sliceDepthOf(o.typ) > unordered[len(unordered)-1] {
+			return true
+		}
+	}
+	return false
+}
+
+// resultCheckOf works out how a single value held in a variable named after
+// r is checked, given the tolerance and the unordered levels that apply.
+func resultCheckOf(r fieldInfo, tol Tolerance, unordered []int) resultCheck {
+	check := resultCheck{Name: r.Name, Var: r.Name, Type: r.Type, Comparison: comparisonOf(r.typ), imports: r.imports}
+	if r.Notation() {
+		check.Notation = true
+		check.Want = expectedVarPrefix + upperFirst(r.Name)
+	}
+	if len(unordered) > 0 && sliceDepthOf(r.typ) > unordered[len(unordered)-1] {
+		levels := make([]string, len(unordered))
+		for i, level := range unordered {
+			levels[i] = strconv.Itoa(level)
+		}
+		check.Comparison = compareUnordered
+		check.Options = append(check.Options, fmt.Sprintf("lctest.Unordered(%s)", strings.Join(levels, ", ")))
+	}
+	if containsFloat(r.typ, make(map[types.Type]bool)) {
+		check.Options = append(check.Options, fmt.Sprintf("lctest.Tolerance(%s, %s)", formatFloat(tol.Abs), formatFloat(tol.Rel)))
+	}
+	return check
+}
+
+// sliceDepthOf counts how many slices or arrays are nested in type t, e.g.
+// 2 for [][]string and 0 for map[int][]int.
+func sliceDepthOf(t types.Type) int {
+	switch u := t.(type) {
+	case nil:
+		return 0
+	case *types.Slice:
+		return 1 + sliceDepthOf(u.Elem())
+	case *types.Array:
+		return 1 + sliceDepthOf(u.Elem())
+	case *types.Named:
+		return sliceDepthOf(u.Underlying())
+	default:
+		if alias, ok := t.(*types.Alias); ok {
+			return sliceDepthOf(types.Unalias(alias))
+		}
+		return 0
+	}
+}
+
+// formatFloat renders a float64 as a Go literal.
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// comparisonOf picks the comparison used for values of type t.
+// Types containing floating-point numbers at any depth are compared with a
+// tolerance. Comparable scalar types, and arrays and structs built only from
+// them, are compared with ==. Everything else, including pointers, slices,
+// maps, interfaces and type parameters not constrained to comparable types,
+// is compared structurally. A nil type is compared structurally as well,
+// since that is valid for any value.
+func comparisonOf(t types.Type) comparison {
+	if containsFloat(t, make(map[types.Type]bool)) {
+		return compareTolerant
+	}
+	if isScalarComparable(t) {
+		return compareEqual
+	}
+	return compareDeep
+}
+
+// isScalarComparable reports whether values of type t can be compared with
+// == and the result matches a structural comparison.
+func isScalarComparable(t types.Type) bool {
+	switch t := t.(type) {
+	case nil:
+		return false
+	case *types.Basic:
+		return t.Kind() != types.Invalid && t.Kind() != types.UntypedNil && t.Kind() != types.UnsafePointer
+	case *types.Array:
+		return isScalarComparable(t.Elem())
+	case *types.Struct:
+		for i := 0; i < t.NumFields(); i++ {
+			if !isScalarComparable(t.Field(i).Type()) {
+				return false
+			}
+		}
+		return true
+	case *types.TypeParam:
+		iface, ok := t.Constraint().Underlying().(*types.Interface)
+		if !ok {
+			return false
+		}
+		terms, hasTerms := typeTermsOf(iface)
+		if !hasTerms {
+			return iface.IsComparable()
+		}
+		for _, term := range terms {
+			if !isScalarComparable(term.Type()) {
+				return false
+			}
+		}
+		return true
+	case *types.Named:
+		return isScalarComparable(t.Underlying())
+	default:
+		if alias, ok := t.(*types.Alias); ok {
+			return isScalarComparable(types.Unalias(alias))
+		}
+		return false
+	}
+}
+
+// typeTermsOf collects the union terms embedded in a constraint interface.
+// The boolean result is false if the interface does not restrict its type set
+// with any union, as is the case for any and comparable.
+func typeTermsOf(iface *types.Interface) ([]*types.Term, bool) {
+	var (
+		terms    []*types.Term
+		hasTerms bool
+	)
+	for i := 0; i < iface.NumEmbeddeds(); i++ {
+		switch embedded := iface.EmbeddedType(i).(type) {
+		case *types.Union:
+			hasTerms = true
+			for j := 0; j < embedded.Len(); j++ {
+				terms = append(terms, embedded.Term(j))
+			}
+		default:
+			if nestedIface, isIface := embedded.Underlying().(*types.Interface); isIface {
+				if nested, ok := typeTermsOf(nestedIface); ok {
+					hasTerms = true
+					terms = append(terms, nested...)
+				}
+				continue
+			}
+			// A single non-interface type embeds as a one-term type set
+			hasTerms = true
+			terms = append(terms, types.NewTerm(false, embedded))
+		}
+	}
+	return terms, hasTerms
+}
+
+// containsFloat reports whether values of type t may hold floating-point or
+// complex numbers, looking through containers, pointers, struct fields and
+// type parameter constraints.
+func containsFloat(t types.Type, seen map[types.Type]bool) bool {
+	if t == nil || seen[t] {
+		return false
+	}
+	seen[t] = true
+
+	switch t := t.(type) {
+	case *types.Basic:
+		return t.Info()&(types.IsFloat|types.IsComplex) != 0
+	case *types.Array:
+		return containsFloat(t.Elem(), seen)
+	case *types.Slice:
+		return containsFloat(t.Elem(), seen)
+	case *types.Pointer:
+		return containsFloat(t.Elem(), seen)
+	case *types.Map:
+		return containsFloat(t.Key(), seen) || containsFloat(t.Elem(), seen)
+	case *types.Struct:
+		for i := 0; i < t.NumFields(); i++ {
+			if containsFloat(t.Field(i).Type(), seen) {
+				return true
+			}
+		}
+		return false
+	case *types.TypeParam:
+		iface, ok := t.Constraint().Underlying().(*types.Interface)
+		if !ok {
+			return false
+		}
+		terms, _ := typeTermsOf(iface)
+		for _, term := range terms {
+			if containsFloat(term.Type(), seen) {
+				return true
+			}
+		}
+		return false
+	case *types.Named:
+		return containsFloat(t.Underlying(), seen)
+	default:
+		if alias, ok := t.(*types.Alias); ok {
+			return containsFloat(types.Unalias(alias), seen)
+		}
+		return false
+	}
+}
+
+// mutableParamsOf returns the names of the parameters whose values let a
+// function modify the caller's data: slices, maps and pointers.
+func mutableParamsOf(params []fieldInfo) []string {
+	var names []string
+	for _, p := range params {
+		if p.typ == nil {
+			continue
+		}
+		switch p.typ.Underlying().(type) {
+		case *types.Slice, *types.Map, *types.Pointer:
+			names = append(names, p.Name)
+		}
+	}
+	return names
+}
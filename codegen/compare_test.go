@@ -0,0 +1,171 @@
+package codegen
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"reflect"
+	"testing"
+)
+
+func TestComparisonOf(t *testing.T) {
+	const src = `package p
+
+type ListNode struct {
+	Val  int
+	Next *ListNode
+}
+type point struct{ x, y int }
+type bag struct{ items []int }
+type weighted struct{ edges [][2]float64 }
+type weightedNode struct {
+	weight float64
+	next   *weightedNode
+}
+type number interface{ ~int | ~float64 }
+
+func scalar() (int, string, bool, [3]int, point) { return 0, "", false, [3]int{}, point{} }
+func floats() (float64, []float32, complex128, weighted, *weightedNode) { return 0, nil, 0, weighted{}, nil }
+func deep() ([]int, [][]string, map[int]int, *ListNode, bag, any, [2][]int) { return nil, nil, nil, nil, bag{}, nil, [2][]int{} }
+func ordered[T int | string](T) T { panic("") }
+func approx[T number](T) T { panic("") }
+func eq[T comparable](T) T { panic("") }
+func anything[T any](T) T { panic("") }
+func nested[T any](T) []T { panic("") }
+`
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "p.go", src, 0)
+	if err != nil {
+		t.Fatalf("parsing source: %v", err)
+	}
+	info := &types.Info{Types: make(map[ast.Expr]types.TypeAndValue)}
+	if _, err := (&types.Config{}).Check("", fset, []*ast.File{f}, info); err != nil {
+		t.Fatalf("type checking source: %v", err)
+	}
+
+	tests := []struct {
+		funcName string
+		expected comparison
+	}{
+		{"scalar", compareEqual},
+		{"floats", compareTolerant},
+		{"deep", compareDeep},
+		{"ordered", compareEqual},
+		{"approx", compareTolerant},
+		{"eq", compareEqual},
+		{"anything", compareDeep},
+		{"nested", compareDeep},
+	}
+
+	for _, test := range tests {
+		t.Run(test.funcName, func(t *testing.T) {
+			var decl *ast.FuncDecl
+			for _, d := range f.Decls {
+				if fd, ok := d.(*ast.FuncDecl); ok && fd.Name.Name == test.funcName {
+					decl = fd
+				}
+			}
+			if decl == nil {
+				t.Fatalf("function %s not found", test.funcName)
+			}
+			for _, result := range extractFields(decl.Type.Results, info) {
+				if got := comparisonOf(result.typ); got != test.expected {
+					t.Errorf("comparisonOf(%s) = %v; expected %v", result.Type, got, test.expected)
+				}
+			}
+		})
+	}
+
+	if got := comparisonOf(nil); got != compareDeep {
+		t.Errorf("comparisonOf(nil) = %v; expected %v", got, compareDeep)
+	}
+}
+
+func TestResultChecksOf(t *testing.T) {
+	strs := types.NewSlice(types.NewSlice(types.Typ[types.String]))
+	floats := types.NewSlice(types.Typ[types.Float64])
+	tests := []struct {
+		name     string
+		tf       testFuncData
+		expected []resultCheck
+		wantErr  bool
+	}{
+		{
+			name: "default comparisons",
+			tf: testFuncData{
+				FuncName:  "f",
+				Results:   []fieldInfo{{Name: "a", typ: types.Typ[types.Int]}, {Name: "b", typ: strs}, {Name: "c", typ: floats}},
+				Tolerance: defaultTolerance,
+			},
+			expected: []resultCheck{
+				{Name: "a", Var: "a", Comparison: compareEqual},
+				{Name: "b", Var: "b", Comparison: compareDeep},
+				{Name: "c", Var: "c", Comparison: compareTolerant, Options: []string{"lctest.Tolerance(1e-05, 1e-05)"}},
+			},
+		},
+		{
+			name: "unordered levels",
+			tf: testFuncData{
+				FuncName:  "f",
+				Results:   []fieldInfo{{Name: "a", typ: types.Typ[types.Int]}, {Name: "b", typ: strs}},
+				Unordered: []int{0, 1},
+			},
+			expected: []resultCheck{
+				{Name: "a", Var: "a", Comparison: compareEqual},
+				{Name: "b", Var: "b", Comparison: compareUnordered, Options: []string{"lctest.Unordered(0, 1)"}},
+			},
+		},
+		{
+			name: "unordered floats",
+			tf: testFuncData{
+				FuncName:  "f",
+				Results:   []fieldInfo{{Name: "a", typ: floats}},
+				Tolerance: Tolerance{Abs: 1e-9, Rel: 0},
+				Unordered: []int{0},
+			},
+			expected: []resultCheck{
+				{Name: "a", Var: "a", Comparison: compareUnordered, Options: []string{"lctest.Unordered(0)", "lctest.Tolerance(1e-09, 0)"}},
+			},
+		},
+		{
+			name: "modified in place",
+			tf: testFuncData{
+				FuncName: "f",
+				Params:   []fieldInfo{{Name: "nums", typ: types.NewSlice(types.Typ[types.Int])}, {Name: "k", typ: types.Typ[types.Int]}},
+				Results:  []fieldInfo{{Name: "field0", typ: types.Typ[types.Int]}},
+				InPlace:  []string{"nums"},
+			},
+			expected: []resultCheck{
+				{Name: "field0", Var: "field0", Comparison: compareEqual},
+				{Name: "nums", Var: "gotNums", Comparison: compareDeep},
+			},
+		},
+		{
+			name: "levels too deep",
+			tf: testFuncData{
+				FuncName:  "f",
+				Results:   []fieldInfo{{Name: "a", typ: floats}},
+				Unordered: []int{1},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := resultChecksOf(test.tf)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("resultChecksOf() error = %v; wantErr %v", err, test.wantErr)
+			}
+			if len(result) != len(test.expected) {
+				t.Fatalf("resultChecksOf() = %v; expected %v", result, test.expected)
+			}
+			for i := range result {
+				if !reflect.DeepEqual(result[i], test.expected[i]) {
+					t.Errorf("resultChecksOf()[%d] = %v; expected %v", i, result[i], test.expected[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,380 @@
+package codegen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// designData describes a type tested as a LeetCode design problem, such as
+// LRU Cache: test cases construct an object and call its methods in sequence.
+type designData struct {
+	TypeName string
+	// ObjType is the type of the constructed object, either TypeName or a
+	// pointer to it.
+	ObjType     string
+	Constructor designOp
+	Methods     []designOp
+	Tolerance   Tolerance
+	Unordered   []int
+	Timeout     time.Duration
+
+	// pos is the position of the type name in the source file.
+	pos token.Position
+}
+
+// designOp is a constructor or method called by the test cases of a design
+// problem.
+type designOp struct {
+	// Op is the name LeetCode gives the operation: the type name for the
+	// constructor, and the method name starting with a lower-case letter for
+	// methods.
+	Op       string
+	FuncName string
+	Params   []fieldInfo
+	Results  []fieldInfo
+}
+
+// Ops returns the names of all operations, starting with the constructor.
+func (d designData) Ops() []string {
+	ops := []string{d.Constructor.Op}
+	for _, m := range d.Methods {
+		ops = append(ops, m.Op)
+	}
+	return ops
+}
+
+// designCall describes how a generated test performs an operation.
+type designCall struct {
+	designOp
+	IsConstructor bool
+	// Check describes how the result is checked, or is nil if the operation
+	// returns nothing.
+	Check *resultCheck
+}
+
+// designCallsOf works out how the generated test performs each operation of
+// a design problem. The result of a method is held in a variable named "got".
+func designCallsOf(d designData) []designCall {
+	calls := []designCall{{designOp: d.Constructor, IsConstructor: true}}
+	for _, m := range d.Methods {
+		call := designCall{designOp: m}
+		if len(m.Results) == 1 {
+			result := m.Results[0]
+			result.Name = "got"
+			check := resultCheckOf(result, d.Tolerance, d.Unordered)
+			call.Check = &check
+		}
+		calls = append(calls, call)
+	}
+	return calls
+}
+
+// extractDesign collects the constructor and the exported methods of a type
+// tagged as a design problem. The constructor is the function returning the
+// type or a pointer to it, preferably named "Constructor" as in LeetCode.
+//
+// Parameters:
+//   - fset: The file set the files were parsed into, locating the problems
+//   - files: The parsed files of the package
+//   - spec: The declaration of the tagged type
+//   - doc: The doc comment of the declaration, holding its annotations
+//   - info: The type information of the source file
+//
+// Returns:
+//   - designData: The design problem metadata
+//   - error: A Diagnostic if the type is generic, lacks a constructor or
+//     methods, or has a method returning several values
+func (g *Generator) extractDesign(fset *token.FileSet, files []*ast.File, spec *ast.TypeSpec, doc *ast.CommentGroup, info *types.Info) (designData, error) {
+	typeName := spec.Name.Name
+	if spec.TypeParams != nil {
+		return designData{}, errorAt(fset, spec.Name.Pos(), "%s: generic design types are not supported", typeName)
+	}
+	obj := info.Defs[spec.Name]
+	if obj == nil {
+		return designData{}, errorAt(fset, spec.Name.Pos(), "%s: unknown type", typeName)
+	}
+
+	// The tolerance and unordered levels of the generator apply unless the
+	// annotations set others
+	tf := testFuncData{FuncName: typeName, Tolerance: g.defaults.Tolerance, Unordered: g.defaults.Unordered}
+	if err := g.directive.applyAnnotations(fset, &tf, g.directive.annotationsOf(doc)); err != nil {
+		return designData{}, err
+	}
+	if tf.InPlace != nil {
+		return designData{}, errorAt(fset, spec.Name.Pos(), "%s: %s%s is not supported on design types", typeName, g.directive.prefix, inPlaceAnnotation)
+	}
+	if tf.Alias != "" {
+		return designData{}, errorAt(fset, spec.Name.Pos(), "%s: the %s option of %v is not supported on design types", typeName, nameOption, g.directive)
+	}
+	d := designData{
+		TypeName:  typeName,
+		Tolerance: tf.Tolerance,
+		Unordered: tf.Unordered,
+		Timeout:   tf.Timeout,
+		pos:       fset.Position(spec.Name.Pos()),
+	}
+
+	var (
+		constructors []designOp
+		decls        []ast.Decl
+	)
+	for _, f := range files {
+		decls = append(decls, f.Decls...)
+	}
+	for _, decl := range decls {
+		fd, ok := decl.(*ast.FuncDecl)
+		if !ok {
+			continue
+		}
+		op := designOp{
+			FuncName: fd.Name.Name,
+			Params:   extractFields(fd.Type.Params, info),
+			Results:  extractFields(fd.Type.Results, info),
+		}
+
+		if fd.Recv == nil {
+			if len(op.Results) == 1 && op.Results[0].typ != nil &&
+				(types.Identical(op.Results[0].typ, obj.Type()) || types.Identical(op.Results[0].typ, types.NewPointer(obj.Type()))) {
+				op.Op = typeName
+				constructors = append(constructors, op)
+			}
+			continue
+		}
+		if receiverTypeName(fd.Recv) != typeName || !fd.Name.IsExported() {
+			continue
+		}
+		if len(op.Results) > 1 {
+			return designData{}, errorAt(fset, fd.Name.Pos(), "%s.%s: design methods must return at most one value", typeName, fd.Name.Name)
+		}
+		op.Op = strings.ToLower(op.FuncName[:1]) + op.FuncName[1:]
+		d.Methods = append(d.Methods, op)
+	}
+
+	switch {
+	case len(constructors) == 0:
+		return designData{}, errorAt(fset, spec.Name.Pos(), "%s: no constructor returning %s or *%s", typeName, typeName, typeName)
+	case len(constructors) == 1:
+		d.Constructor = constructors[0]
+	default:
+		for _, c := range constructors {
+			if c.FuncName == designConstructorName {
+				d.Constructor = c
+			}
+		}
+		if d.Constructor.FuncName == "" {
+			return designData{}, errorAt(fset, spec.Name.Pos(), "%s: several constructors, name the one to use %s", typeName, designConstructorName)
+		}
+	}
+	d.ObjType = d.Constructor.Results[0].Type
+	if len(d.Methods) == 0 {
+		return designData{}, errorAt(fset, spec.Name.Pos(), "%s: no exported methods", typeName)
+	}
+	return d, nil
+}
+
+// designConstructorName is the name LeetCode gives the constructors of
+// design problems.
+const designConstructorName = "Constructor"
+
+// receiverTypeName returns the name of the type a method is declared on.
+func receiverTypeName(recv *ast.FieldList) string {
+	if recv == nil || len(recv.List) == 0 {
+		return ""
+	}
+	expr := recv.List[0].Type
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	if ident, ok := expr.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
+
+// operationsExampleOf renders the operation names of a design problem as a
+// LeetCode array, e.g. `["LRUCache","get","put"]`.
+func operationsExampleOf(d designData) string {
+	ops := d.Ops()
+	for i, op := range ops {
+		ops[i] = strconv.Quote(op)
+	}
+	return "`[" + strings.Join(ops, ",") + "]`"
+}
+
+// designSchemaOf describes the test case template of a design problem, whose
+// cases spell the operations, their arguments and their results in LeetCode
+// notation.
+func (g *Generator) designSchemaOf(d designData) caseSchema {
+	s := g.namedSchema(upperFirst(d.TypeName))
+	s.doc = []string{
+		"Auto-generated test case template for " + generatedFrom(d.TypeName, d.pos),
+		"Operations:",
+	}
+	s.inputs = []templateField{
+		{name: "operations", typ: "string", comment: "Constructor and methods called in order, e.g. " + operationsExampleOf(d)},
+		{name: "arguments", typ: "string", comment: "Arguments of each call in LeetCode notation, one array per call"},
+	}
+	s.outputs = []templateField{
+		{name: "expected", typ: "string", comment: "Result of each call in LeetCode notation, null for calls without one"},
+	}
+	// The constructor is listed without the object it returns
+	constructor := d.Constructor
+	constructor.Results = nil
+	for _, op := range append([]designOp{constructor}, d.Methods...) {
+		s.doc = append(s.doc, "  - "+operationSignatureOf(op))
+	}
+	if d.Timeout > 0 {
+		s.doc = append(s.doc, fmt.Sprintf("Each case must finish within %v", d.Timeout))
+	}
+	return s
+}
+
+// operationSignatureOf spells the signature of an operation under the name
+// LeetCode gives it, e.g. "put(key int, value int)" or "get(key int) int".
+func operationSignatureOf(op designOp) string {
+	params := make([]string, len(op.Params))
+	for i, p := range op.Params {
+		params[i] = p.Name + " " + p.Type
+	}
+	signature := op.Op + "(" + strings.Join(params, ", ") + ")"
+	for _, r := range op.Results {
+		signature += " " + r.Type
+	}
+	return signature
+}
+
+// designTestCodeOf lays out and prints the test of a design problem, which
+// runs each of its cases as a subtest performing the operations in order:
+// the constructor sets the object, and the results of methods are checked
+// against the expected ones.
+//
+// Parameters:
+//   - d: The design problem
+//   - tc: The test cases of the design problem
+//   - calls: How the operations are performed, see designCallsOf
+//
+// Returns:
+//   - []byte: The printed test
+//   - error: An error if a type of the design problem cannot be spelled
+func designTestCodeOf(d designData, tc testCaseData, calls []designCall) ([]byte, error) {
+	l := newLayout()
+	decl := &ast.FuncDecl{
+		Doc:  l.comment("Auto-generated test for " + generatedFrom(d.TypeName, d.pos)),
+		Name: ident("Test" + upperFirst(d.TypeName)),
+	}
+	pos := l.next()
+	decl.Type = testFuncTypeAt(pos)
+	decl.Body = &ast.BlockStmt{Lbrace: pos}
+
+	for _, c := range tc.Cases {
+		run, err := subtestOf(l, c, func() ([]ast.Stmt, error) {
+			var stmts []ast.Stmt
+			if d.Timeout > 0 {
+				stmt, err := timeoutStmtOf(l, d.Timeout)
+				if err != nil {
+					return nil, err
+				}
+				stmts = append(stmts, stmt)
+			}
+			pos := l.next()
+			objType, err := exprAt(pos, d.ObjType)
+			if err != nil {
+				return nil, err
+			}
+			stmts = append(stmts, &ast.DeclStmt{Decl: &ast.GenDecl{
+				TokPos: pos,
+				Tok:    token.VAR,
+				Specs:  []ast.Spec{&ast.ValueSpec{Names: []*ast.Ident{ident("obj")}, Type: objType}},
+			}})
+
+			loopPos := l.next()
+			switchPos := l.next()
+			dispatch := &ast.SwitchStmt{Switch: switchPos, Tag: sel(ident("op"), "Name"), Body: &ast.BlockStmt{Lbrace: switchPos}}
+			for _, dc := range calls {
+				clause, err := operationClauseOf(l, dc)
+				if err != nil {
+					return nil, err
+				}
+				dispatch.Body.List = append(dispatch.Body.List, clause)
+			}
+			defaultPos := l.next()
+			unknown := at(l.next(), errorfStmtOf("Fatalf", "operation %d: unknown operation %q", sel(ident("op"), "Index"), sel(ident("op"), "Name")))
+			dispatch.Body.List = append(dispatch.Body.List, &ast.CaseClause{Case: defaultPos, Body: []ast.Stmt{unknown}})
+			dispatch.Body.Rbrace = l.next()
+
+			operations := lctestCall("MustOperations", ident("t"),
+				sel(ident(c.Name), inputAttrName, "operations"),
+				sel(ident(c.Name), inputAttrName, "arguments"),
+				sel(ident(c.Name), outputAttrName, "expected"))
+			return append(stmts, &ast.RangeStmt{
+				For:   loopPos,
+				Key:   ident("_"),
+				Value: ident("op"),
+				Tok:   token.DEFINE,
+				X:     operations,
+				Body:  l.block(loopPos, dispatch),
+			}), nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		decl.Body.List = append(decl.Body.List, run)
+	}
+	decl.Body.Rbrace = l.next()
+	return l.printDecls([]ast.Decl{decl})
+}
+
+// operationClauseOf lays out the case of the operation dispatch performing
+// an operation of a design problem: it checks the number of arguments,
+// decodes them, calls the constructor or method and checks its result.
+func operationClauseOf(l *layout, dc designCall) (*ast.CaseClause, error) {
+	clause := &ast.CaseClause{Case: l.next(), List: []ast.Expr{str(dc.Op)}}
+	clause.Body = append(clause.Body, at(l.next(), &ast.ExprStmt{
+		X: call(sel(ident("op"), "CheckArgs"), ident("t"), &ast.BasicLit{Kind: token.INT, Value: strconv.Itoa(len(dc.Params))}),
+	}))
+
+	pos := l.next()
+	args := make([]ast.Expr, len(dc.Params))
+	for i, p := range dc.Params {
+		typ, err := exprAt(pos, p.Type)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = mustDecodeOf(typ, &ast.IndexExpr{X: sel(ident("op"), "Args"), Index: &ast.BasicLit{Kind: token.INT, Value: strconv.Itoa(i)}})
+	}
+	switch {
+	case dc.IsConstructor:
+		clause.Body = append(clause.Body, at(pos, &ast.AssignStmt{Lhs: []ast.Expr{ident("obj")}, Tok: token.ASSIGN, Rhs: []ast.Expr{call(ident(dc.FuncName), args...)}}))
+	case dc.Check != nil:
+		clause.Body = append(clause.Body, at(pos, define(call(sel(ident("obj"), dc.FuncName), args...), "got")))
+	default:
+		clause.Body = append(clause.Body, at(pos, &ast.ExprStmt{X: call(sel(ident("obj"), dc.FuncName), args...)}))
+	}
+	if dc.Check == nil {
+		return clause, nil
+	}
+
+	pos = l.next()
+	typ, err := exprAt(pos, dc.Check.Type)
+	if err != nil {
+		return nil, err
+	}
+	clause.Body = append(clause.Body, at(pos, define(mustDecodeOf(typ, sel(ident("op"), "Expected")), "want")))
+	check, err := checkStmtOf(l, *dc.Check, ident("got"), ident("want"), func(diff ast.Expr) ast.Stmt {
+		args := []ast.Expr{sel(ident("op"), "Index"), ident("op"), lctestCall("Encode", ident("got")), sel(ident("op"), "Expected")}
+		if diff != nil {
+			return errorfStmtOf("Fatalf", "operation %d: %s = %s, want %s\n%s", append(args, diff)...)
+		}
+		return errorfStmtOf("Fatalf", "operation %d: %s = %s, want %s", args...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	clause.Body = append(clause.Body, check)
+	return clause, nil
+}
@@ -0,0 +1,128 @@
+package codegen
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExtractDesign(t *testing.T) {
+	const src = `package p
+
+//go:generate leetcode-gen-test
+//leetcode:tolerance 1e-9
+type MinStack struct{ items []float64 }
+
+func Constructor() MinStack { return MinStack{} }
+
+func (s *MinStack) Push(val float64) { s.items = append(s.items, val) }
+func (s *MinStack) Pop()              { s.items = s.items[:len(s.items)-1] }
+func (s *MinStack) GetMin() float64   { return s.min() }
+func (s *MinStack) min() float64      { return 0 }
+
+type (
+	//go:generate leetcode-gen-test
+	Trie struct{ words map[string]bool }
+)
+
+func NewTrie() *Trie                        { return &Trie{words: make(map[string]bool)} }
+func (t *Trie) Insert(word string)          { t.words[word] = true }
+func (t *Trie) Search(word string) bool     { return t.words[word] }
+`
+	tfMetadata, err := defaultGenerator.extractTestFuncs("", []byte(src))
+	if err != nil {
+		t.Fatalf("extractTestFuncs() error = %v", err)
+	}
+	if len(tfMetadata.designs) != 2 {
+		t.Fatalf("extractTestFuncs() found %d designs; expected 2", len(tfMetadata.designs))
+	}
+
+	tests := []struct {
+		design            designData
+		expectedObjType   string
+		expectedFuncName  string
+		expectedOps       []string
+		expectedTolerance Tolerance
+	}{
+		{tfMetadata.designs[0], "MinStack", "Constructor", []string{"MinStack", "push", "pop", "getMin"}, Tolerance{Abs: 1e-9, Rel: 1e-9}},
+		{tfMetadata.designs[1], "*Trie", "NewTrie", []string{"Trie", "insert", "search"}, defaultTolerance},
+	}
+	for _, test := range tests {
+		t.Run(test.design.TypeName, func(t *testing.T) {
+			if test.design.ObjType != test.expectedObjType {
+				t.Errorf("ObjType = %q; expected %q", test.design.ObjType, test.expectedObjType)
+			}
+			if test.design.Constructor.FuncName != test.expectedFuncName {
+				t.Errorf("Constructor = %q; expected %q", test.design.Constructor.FuncName, test.expectedFuncName)
+			}
+			if result := test.design.Ops(); !reflect.DeepEqual(result, test.expectedOps) {
+				t.Errorf("Ops() = %v; expected %v", result, test.expectedOps)
+			}
+			if test.design.Tolerance != test.expectedTolerance {
+				t.Errorf("Tolerance = %+v; expected %+v", test.design.Tolerance, test.expectedTolerance)
+			}
+		})
+	}
+
+	calls := designCallsOf(tfMetadata.designs[0])
+	if calls[2].Check != nil || calls[3].Check == nil || !calls[3].Check.IsTolerant() {
+		t.Errorf("designCallsOf() checks = %+v, %+v; expected no check for pop and a tolerant one for getMin", calls[2].Check, calls[3].Check)
+	}
+}
+
+func TestExtractDesignErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		expected string
+	}{
+		{
+			name: "no constructor",
+			src: `package p
+//go:generate leetcode-gen-test
+type Counter struct{ n int }
+func (c *Counter) Inc() { c.n++ }
+`,
+			expected: "no constructor",
+		},
+		{
+			name: "no methods",
+			src: `package p
+//go:generate leetcode-gen-test
+type Counter struct{ n int }
+func Constructor() Counter { return Counter{} }
+`,
+			expected: "no exported methods",
+		},
+		{
+			name: "several results",
+			src: `package p
+//go:generate leetcode-gen-test
+type Counter struct{ n int }
+func Constructor() Counter { return Counter{} }
+func (c *Counter) Inc() (int, bool) { c.n++; return c.n, true }
+`,
+			expected: "at most one value",
+		},
+		{
+			name: "in place",
+			src: `package p
+//go:generate leetcode-gen-test
+//leetcode:inplace
+type Counter struct{ n int }
+func Constructor() Counter { return Counter{} }
+func (c *Counter) Inc(nums []int) { c.n++ }
+`,
+			expected: "inplace",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := defaultGenerator.extractTestFuncs("", []byte(test.src))
+			if err == nil || !strings.Contains(err.Error(), test.expected) {
+				t.Errorf("extractTestFuncs() error = %v; expected it to mention %q", err, test.expected)
+			}
+		})
+	}
+}
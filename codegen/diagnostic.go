@@ -0,0 +1,130 @@
+package codegen
+
+import (
+	"errors"
+	"fmt"
+	"go/scanner"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+)
+
+// Diagnostic is a problem found in a file, located by its position so that
+// editors can jump to it.
+type Diagnostic struct {
+	// Pos is the position of the problem. Its line and column are zero for
+	// problems concerning the file as a whole.
+	Pos     token.Position
+	Message string
+}
+
+// Error formats the diagnostic as "file:line:col: message", leaving out the
+// parts of the position that are unknown.
+func (d Diagnostic) Error() string {
+	if pos := d.Pos.String(); pos != "-" {
+		return pos + ": " + d.Message
+	}
+	return d.Message
+}
+
+// Diagnostics is a list of problems found in one or more files. As an error,
+// it lists the problems one per line.
+type Diagnostics []Diagnostic
+
+// Error formats the diagnostics one per line, see Diagnostic.Error.
+func (ds Diagnostics) Error() string {
+	lines := make([]string, len(ds))
+	for i, d := range ds {
+		lines[i] = d.Error()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// DiagnosticsOf returns the diagnostics an error carries, possibly wrapped
+// by other errors. An error without diagnostics is turned into a single
+// diagnostic without a position.
+//
+// Parameters:
+//   - err: The error, which may be nil
+//
+// Returns:
+//   - Diagnostics: The diagnostics, or nil if err is nil
+func DiagnosticsOf(err error) Diagnostics {
+	if err == nil {
+		return nil
+	}
+	var ds Diagnostics
+	ds.add(err)
+	return ds
+}
+
+// errorAt returns a diagnostic located at a position of a file set as an error.
+func errorAt(fset *token.FileSet, pos token.Pos, format string, args ...any) error {
+	return Diagnostic{Pos: fset.Position(pos), Message: fmt.Sprintf(format, args...)}
+}
+
+// errorIn returns a diagnostic concerning a whole file as an error.
+func errorIn(filename string, format string, args ...any) error {
+	return Diagnostic{Pos: token.Position{Filename: filename}, Message: fmt.Sprintf(format, args...)}
+}
+
+// add appends the problems an error reports: the diagnostics it carries, the
+// syntax errors of the parser and the errors of the type checker keep their
+// positions, while other errors are added without one.
+func (ds *Diagnostics) add(err error) {
+	var (
+		diagnostics Diagnostics
+		diagnostic  Diagnostic
+		syntaxErrs  scanner.ErrorList
+		syntaxErr   *scanner.Error
+		typeErr     types.Error
+	)
+	switch {
+	case errors.As(err, &diagnostics):
+		*ds = append(*ds, diagnostics...)
+	case errors.As(err, &diagnostic):
+		*ds = append(*ds, diagnostic)
+	case errors.As(err, &syntaxErrs):
+		for _, e := range syntaxErrs {
+			*ds = append(*ds, Diagnostic{Pos: e.Pos, Message: e.Msg})
+		}
+	case errors.As(err, &syntaxErr):
+		*ds = append(*ds, Diagnostic{Pos: syntaxErr.Pos, Message: syntaxErr.Msg})
+	case errors.As(err, &typeErr):
+		pos := typeErr.Fset.Position(typeErr.Pos)
+		// Messages starting with a tab continue the previous error, e.g.
+		// with the other declaration of a redeclared name
+		if rest, ok := strings.CutPrefix(typeErr.Msg, "\t"); ok && len(*ds) > 0 {
+			(*ds)[len(*ds)-1].Message += fmt.Sprintf("\n\t%v: %s", pos, rest)
+			return
+		}
+		*ds = append(*ds, Diagnostic{Pos: pos, Message: typeErr.Msg})
+	default:
+		*ds = append(*ds, Diagnostic{Message: err.Error()})
+	}
+}
+
+// err returns the diagnostics sorted by position as an error, or nil if
+// there are none.
+func (ds Diagnostics) err() error {
+	if len(ds) == 0 {
+		return nil
+	}
+	ds.sort()
+	return ds
+}
+
+// sort sorts the diagnostics by position.
+func (ds Diagnostics) sort() {
+	sort.SliceStable(ds, func(i, j int) bool {
+		a, b := ds[i].Pos, ds[j].Pos
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+}
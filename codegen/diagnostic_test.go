@@ -0,0 +1,111 @@
+package codegen
+
+import (
+	"errors"
+	"fmt"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiagnosticError(t *testing.T) {
+	tests := []struct {
+		pos      token.Position
+		expected string
+	}{
+		{token.Position{Filename: "a.go", Line: 3, Column: 7}, "a.go:3:7: broken"},
+		{token.Position{Filename: "a.go", Line: 3}, "a.go:3: broken"},
+		{token.Position{Filename: "a.go"}, "a.go: broken"},
+		{token.Position{}, "broken"},
+	}
+
+	for _, tt := range tests {
+		if result := (Diagnostic{Pos: tt.pos, Message: "broken"}).Error(); result != tt.expected {
+			t.Errorf("Diagnostic{%v}.Error() = %q; expected %q", tt.pos, result, tt.expected)
+		}
+	}
+}
+
+func TestDiagnosticsOf(t *testing.T) {
+	at := func(line int, msg string) Diagnostic {
+		return Diagnostic{Pos: token.Position{Filename: "a.go", Line: line, Column: 1}, Message: msg}
+	}
+	wrapped := fmt.Errorf("extracting test function: %w", Diagnostics{at(1, "first"), at(2, "second")})
+
+	if result := DiagnosticsOf(wrapped); len(result) != 2 || result[1] != at(2, "second") {
+		t.Errorf("DiagnosticsOf() = %v; expected both wrapped diagnostics", result)
+	}
+	if result := DiagnosticsOf(errors.New("plain")); len(result) != 1 || result[0].Error() != "plain" {
+		t.Errorf("DiagnosticsOf() = %v; expected a diagnostic without position", result)
+	}
+	if result := DiagnosticsOf(nil); result != nil {
+		t.Errorf("DiagnosticsOf(nil) = %v; expected nil", result)
+	}
+
+	// Sorted by position when returned as an error
+	err := Diagnostics{at(9, "late"), at(2, "early")}.err()
+	if expected := "a.go:2:1: early\na.go:9:1: late"; err == nil || err.Error() != expected {
+		t.Errorf("Diagnostics.err() = %v; expected %q", err, expected)
+	}
+	if err := Diagnostics(nil).err(); err != nil {
+		t.Errorf("Diagnostics(nil).err() = %v; expected nil", err)
+	}
+}
+
+func TestExtractDiagnostics(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "sol.go")
+	content := `package p
+
+//leetcode:test retries=3
+func a(x int) int { return x }
+
+//leetcode:test
+func b(x int) int { return y }
+
+//leetcode:test
+func c(x int) int { return x }
+
+var (
+	c1 = 1
+	c1 = 2
+)
+
+//leetcode:test
+type Stack struct{}
+`
+	if err := os.WriteFile(src, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Type errors do not stop the other problems from being found
+	_, err := defaultGenerator.extractTestFuncs(src, []byte(content))
+	expected := []string{
+		src + `:3:1: a: //leetcode:test: unknown option "retries", expected one of compare, name, timeout, tolerance`,
+		src + ":18:6: Stack: no constructor returning Stack or *Stack",
+	}
+	if result := DiagnosticsOf(err); result.Error() != strings.Join(expected, "\n") {
+		t.Errorf("extractTestFuncs() error =\n%v\nexpected\n%s", err, strings.Join(expected, "\n"))
+	}
+
+	content = strings.Replace(content, "retries=3", "timeout=1s", 1)
+	content = strings.Replace(content, "//leetcode:test\ntype Stack", "type Stack", 1)
+	tfMetadata, err := defaultGenerator.extractTestFuncs(src, []byte(content))
+	if err != nil {
+		t.Fatalf("extractTestFuncs() error = %v", err)
+	}
+	expected = []string{
+		src + ":7:28: undefined: y",
+		src + ":14:2: c1 redeclared in this block\n\t" + src + ":13:2: other declaration of c1",
+	}
+	if result := tfMetadata.warnings.Error(); result != strings.Join(expected, "\n") {
+		t.Errorf("extractTestFuncs() warnings =\n%s\nexpected\n%s", result, strings.Join(expected, "\n"))
+	}
+
+	_, err = defaultGenerator.extractTestFuncs(src, []byte("package p\n"))
+	if expected := src + ": no functions found in leetcode block"; err == nil || err.Error() != expected {
+		t.Errorf("extractTestFuncs() error = %v; expected %q", err, expected)
+	}
+}
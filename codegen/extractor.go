@@ -23,7 +23,9 @@ const fieldPrefix = "field"
 //
 // Returns:
 //   - []fieldInfo: A slice containing the extracted field information.
-//     Each fieldInfo contains the field's name and type as strings.
+//     Each fieldInfo contains the field's name and type as strings, along with
+//     the resolved type and the imports needed to spell it when it is known.
+//     Types of other packages are qualified with their package names.
 //     For unnamed fields, generates names using fieldPrefix + index.
 func extractFields(fields *ast.FieldList, info *types.Info) []fieldInfo {
 	params := make([]fieldInfo, 0)
@@ -32,22 +34,31 @@ func extractFields(fields *ast.FieldList, info *types.Info) []fieldInfo {
 	}
 
 	for i, field := range fields.List {
-		typStr := fmt.Sprintf("%v", field.Type)
+		var (
+			typ     types.Type
+			imports []string
+		)
+		typStr := types.ExprString(field.Type)
 		if typeAndValue, ok := info.Types[field.Type]; ok {
-			typStr = typeAndValue.Type.String()
+			typ = typeAndValue.Type
+			typStr, imports = qualifiedTypeString(typ)
 		}
 
 		if len(field.Names) > 0 {
 			for _, name := range field.Names {
 				params = append(params, fieldInfo{
-					Name: name.Name,
-					Type: typStr,
+					Name:    name.Name,
+					Type:    typStr,
+					typ:     typ,
+					imports: imports,
 				})
 			}
 		} else {
 			params = append(params, fieldInfo{
-				Name: fieldPrefix + strconv.Itoa(i),
-				Type: typStr,
+				Name:    fieldPrefix + strconv.Itoa(i),
+				Type:    typStr,
+				typ:     typ,
+				imports: imports,
 			})
 		}
 	}
@@ -55,118 +66,409 @@ func extractFields(fields *ast.FieldList, info *types.Info) []fieldInfo {
 }
 
 // extractTestFuncs parses Go source code content and extracts test function metadata.
-// It looks for functions marked with a specific test tag in their documentation comments.
+// It looks for functions marked with a test tag in their documentation comments,
+// see hasTestTag.
 //
 // Parameters:
+//   - filename: The path of the source file, used to resolve the imports of
+//     the enclosing module; it may be empty if the file imports no module packages
 //   - content: The source code content as a byte slice
 //
 // Returns:
 //   - *testFuncMetadata: Contains the package name and metadata for all found test functions
-//   - error: Returns an error if parsing fails, type checking fails, or no test functions are found
+//   - error: Returns Diagnostics locating the problems if parsing fails, type
+//     checking fails, annotations are malformed, or no test functions are found
 //
 // The function performs the following steps:
-// 1. Parses the Go source code
-// 2. Sets up and runs the type checker
-// 3. Traverses the AST looking for functions with test tags
+// 1. Parses the Go source code along with the other source files of its package
+// 2. Sets up and runs the type checker on the package
+// 3. Traverses the AST of the file looking for functions with test tags
 // 4. Extracts function metadata including name, parameters, results and generics
+// 5. Applies the "//leetcode:" annotations found in the function's doc comment
 //
-// If no functions with test tags are found, it returns an error.
-func extractTestFuncs(content []byte) (*testFuncMetadata, error) {
-	// Parse file content
+// Tagged methods, such as those of a Solution type, record their receiver
+// type. Types with test tags are design problems, tested through sequences of
+// calls to their constructor and methods, see extractDesign.
+//
+// Functions without results are assumed to modify their slice, map and pointer
+// parameters in place unless an inplace annotation names the parameters.
+//
+// Type errors do not stop the extraction, so that templates can be generated
+// while other parts of the package are still being written: they are kept as
+// warnings, and tagged declarations whose signatures did not resolve are
+// skipped with a warning. Only imports that cannot be resolved are errors.
+//
+// If no functions or types with test tags are found, it returns an error.
+func (g *Generator) extractTestFuncs(filename string, content []byte) (*testFuncMetadata, error) {
+	// Parse file content along with the other source files of the package,
+	// leaving out test files and test case files which may be stale
 	fset := token.NewFileSet()
-	f, err := parser.ParseFile(fset, "", content, parser.ParseComments)
+	f, files, err := parsePackage(fset, g.fsys, filename, content, func(name string) bool {
+		return !strings.HasSuffix(name, "_test.go") && g.naming.SrcFileNameOf(name) == ""
+	})
 	if err != nil {
-		return nil, fmt.Errorf("parsing file: %v", err)
+		return nil, err
 	}
 
-	// Create a type checker
-	conf := types.Config{Importer: nil}
+	// Create a type checker resolving imports from local sources and
+	// collecting all errors
+	var typeErrs Diagnostics
+	conf := types.Config{
+		Importer: newLocalImporter(g.packages, g.fsys, g.fsys.dir(filename)),
+		Error:    func(err error) { typeErrs.add(err) },
+	}
 	info := &types.Info{
 		Types: make(map[ast.Expr]types.TypeAndValue),
+		Defs:  make(map[*ast.Ident]types.Object),
 	}
 
-	// Type check the file
-	_, err = conf.Check("", fset, []*ast.File{f}, info)
-	if err != nil {
-		return nil, fmt.Errorf("type checking: %v", err)
+	// Type check the package, going on with the partial type information
+	// unless imports cannot be resolved
+	conf.Check("", fset, files, info)
+	var ds, warnings Diagnostics
+	for _, d := range typeErrs {
+		if strings.HasPrefix(d.Message, "could not import ") {
+			ds = append(ds, d)
+		} else {
+			warnings = append(warnings, d)
+		}
+	}
+	if err := ds.err(); err != nil {
+		return nil, err
 	}
 
-	// Traverse the AST to find functions in the leetcode block
-	tfMetadata := testFuncMetadata{pkgName: f.Name.Name}
+	// Traverse the AST to find functions in the leetcode block, collecting
+	// the problems of all of them
+	tfMetadata := testFuncMetadata{pkgName: f.Name.Name, skipped: make(map[string]bool)}
+	skip := func(pos token.Pos, names ...string) {
+		warnings.add(errorAt(fset, pos, "%s: skipped, the types of its signature do not resolve", names[0]))
+		for _, name := range names {
+			tfMetadata.skipped[name] = true
+		}
+	}
 	ast.Inspect(f, func(n ast.Node) bool {
-		if decl, ok := n.(*ast.FuncDecl); ok {
-			if decl.Doc != nil {
-				for _, comment := range decl.Doc.List {
-					if strings.Contains(comment.Text, testTag) {
-						goto func_extraction
-					}
+		// Tagged types are tested as design problems
+		if decl, ok := n.(*ast.GenDecl); ok && decl.Tok == token.TYPE {
+			for _, spec := range decl.Specs {
+				ts := spec.(*ast.TypeSpec)
+				doc := typeSpecDocOf(decl, ts)
+				if !g.directive.hasTestTag(doc) {
+					continue
+				}
+				d, err := g.extractDesign(fset, files, ts, doc, info)
+				if err != nil {
+					ds.add(err)
+					continue
+				}
+				signature := [][]fieldInfo{d.Constructor.Params, d.Constructor.Results}
+				for _, m := range d.Methods {
+					signature = append(signature, m.Params, m.Results)
+				}
+				if !resolved(signature...) {
+					skip(ts.Name.Pos(), d.TypeName, upperFirst(d.TypeName))
+					continue
 				}
+				tfMetadata.designs = append(tfMetadata.designs, d)
+			}
+			return true
+		}
+
+		if decl, ok := n.(*ast.FuncDecl); ok {
+			if g.directive.hasTestTag(decl.Doc) {
+				goto func_extraction
 			}
 			return true
 
 		func_extraction:
-			tfMetadata.testFuncs = append(tfMetadata.testFuncs, testFuncData{
-				FuncName: decl.Name.Name,
-				Params:   extractFields(decl.Type.Params, info),
-				Results:  extractFields(decl.Type.Results, info),
-				Generics: extractFields(decl.Type.TypeParams, info),
-			})
+			tf := testFuncData{
+				FuncName:  decl.Name.Name,
+				Params:    extractFields(decl.Type.Params, info),
+				Results:   extractFields(decl.Type.Results, info),
+				Generics:  extractFields(decl.Type.TypeParams, info),
+				Tolerance: g.defaults.Tolerance,
+				pos:       fset.Position(decl.Name.Pos()),
+			}
+			if decl.Recv != nil {
+				tf.Receiver = receiverTypeName(decl.Recv)
+				if tf.Receiver == "" {
+					ds.add(errorAt(fset, decl.Recv.Pos(), "%s: methods of generic types are not supported", decl.Name.Name))
+					return true
+				}
+				if recv, ok := info.Types[decl.Recv.List[0].Type]; ok {
+					base := recv.Type
+					if ptr, isPtr := base.(*types.Pointer); isPtr {
+						base = ptr.Elem()
+					}
+					tf.receiverComparable = isScalarComparable(base)
+				}
+			}
+			annotationErr := g.directive.applyAnnotations(fset, &tf, g.directive.annotationsOf(decl.Doc))
+			if annotationErr != nil {
+				ds.add(annotationErr)
+			}
+			if !resolved(extractFields(decl.Recv, info), tf.Params, tf.Results, tf.Generics) {
+				skip(decl.Name.Pos(), tf.FuncName, tf.CaseName())
+				return true
+			}
+			// Functions without results are checked through the parameters they
+			// modify, unless their annotations are already reported as wrong
+			if len(tf.Results) == 0 && tf.InPlace == nil {
+				if annotationErr != nil {
+					return true
+				}
+				tf.InPlace = mutableParamsOf(tf.Params)
+			}
+			if len(tf.Outputs()) == 0 {
+				ds.add(errorAt(fset, decl.Name.Pos(), "%s: no results and no parameters it can modify to check, add a result or annotate it with %s%s <param>",
+					tf.displayName(), g.directive.prefix, inPlaceAnnotation))
+				return true
+			}
+			// The default comparison mode applies to the outputs it can
+			if tf.Unordered == nil && unorderedApplies(tf.Outputs(), g.defaults.Unordered) {
+				tf.Unordered = g.defaults.Unordered
+			}
+			tfMetadata.testFuncs = append(tfMetadata.testFuncs, tf)
 		}
 		return true
 	})
+	if err := ds.err(); err != nil {
+		return nil, err
+	}
 
-	if len(tfMetadata.testFuncs) == 0 {
-		return nil, fmt.Errorf("no functions found in leetcode block")
+	if len(tfMetadata.testFuncs) == 0 && len(tfMetadata.designs) == 0 {
+		// The warnings tell why all tagged declarations were skipped
+		if len(tfMetadata.skipped) > 0 {
+			return nil, warnings.err()
+		}
+		return nil, errorIn(filename, "no functions found in leetcode block")
 	}
+	tfMetadata.qualify()
+	warnings.sort()
+	tfMetadata.warnings = warnings
 	return &tfMetadata, nil
 }
 
-const nameAttrName = "name"
+// qualify spells the field types again if packages of the same name are
+// referred to, so that the generated files import them under aliases, see
+// qualifierOf.
+func (m *testFuncMetadata) qualify() {
+	var fields []*fieldInfo
+	addFields := func(lists ...[]fieldInfo) {
+		for _, list := range lists {
+			for i := range list {
+				fields = append(fields, &list[i])
+			}
+		}
+	}
+	for _, tf := range m.testFuncs {
+		addFields(tf.Params, tf.Results, tf.Generics)
+	}
+	for _, d := range m.designs {
+		addFields(d.Constructor.Params, d.Constructor.Results)
+		for _, op := range d.Methods {
+			addFields(op.Params, op.Results)
+		}
+	}
+
+	ts := make([]types.Type, len(fields))
+	for i, f := range fields {
+		ts[i] = f.typ
+	}
+	q := qualifierOf(ts)
+	if q == nil {
+		return
+	}
+	for _, f := range fields {
+		if f.typ != nil {
+			f.Type, f.imports = q.typeString(f.typ)
+		}
+	}
+}
+
+// resolved reports whether the types of all fields resolved despite the type
+// errors of their package.
+func resolved(fieldLists ...[]fieldInfo) bool {
+	for _, fields := range fieldLists {
+		for _, field := range fields {
+			if field.typ == nil || containsInvalid(field.typ) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// containsInvalid reports whether type t is or is composed of the invalid
+// type, which the type checker records for types it cannot resolve.
+func containsInvalid(t types.Type) bool {
+	switch u := t.(type) {
+	case *types.Basic:
+		return u.Kind() == types.Invalid
+	case *types.Pointer:
+		return containsInvalid(u.Elem())
+	case *types.Slice:
+		return containsInvalid(u.Elem())
+	case *types.Array:
+		return containsInvalid(u.Elem())
+	case *types.Map:
+		return containsInvalid(u.Key()) || containsInvalid(u.Elem())
+	case *types.Chan:
+		return containsInvalid(u.Elem())
+	case *types.Named:
+		for i := 0; i < u.TypeArgs().Len(); i++ {
+			if containsInvalid(u.TypeArgs().At(i)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// HasTestTags reports whether a source file declares functions or types
+// tagged for testing. Unlike extracting them, it only parses the file, which
+// makes it cheap enough to look for source files across a whole module.
+//
+// Parameters:
+//   - content: The content of the source file
+//
+// Returns:
+//   - bool: Whether the file has tagged declarations
+//   - error: An error if the file cannot be parsed
+func (g *Generator) HasTestTags(content []byte) (bool, error) {
+	f, err := parser.ParseFile(token.NewFileSet(), "", content, parser.ParseComments)
+	if err != nil {
+		return false, fmt.Errorf("parsing file: %v", err)
+	}
+	for _, decl := range f.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			if g.directive.hasTestTag(decl.Doc) {
+				return true, nil
+			}
+		case *ast.GenDecl:
+			if decl.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range decl.Specs {
+				if g.directive.hasTestTag(typeSpecDocOf(decl, spec.(*ast.TypeSpec))) {
+					return true, nil
+				}
+			}
+		}
+	}
+	return false, nil
+}
+
+// HasTestTags reports whether a source file declares functions or types
+// tagged with the default test directive, see Generator.HasTestTags.
+func HasTestTags(content []byte) (bool, error) {
+	return defaultGenerator.HasTestTags(content)
+}
+
+// typeSpecDocOf returns the doc comment of a type spec, which is the doc
+// comment of its declaration if the declaration has no other specs.
+func typeSpecDocOf(decl *ast.GenDecl, ts *ast.TypeSpec) *ast.CommentGroup {
+	if ts.Doc == nil && len(decl.Specs) == 1 {
+		return decl.Doc
+	}
+	return ts.Doc
+}
+
+// hasTestTag reports whether a doc comment tags its declaration for testing,
+// either with the test directive, e.g. "//leetcode:test", or with the legacy
+// "//go:generate leetcode-gen-test" line. Other go:generate lines, such as
+// those running stringer, do not tag declarations.
+func (d directive) hasTestTag(doc *ast.CommentGroup) bool {
+	if doc == nil {
+		return false
+	}
+	for _, comment := range doc.List {
+		for _, tag := range []string{d.String(), legacyTestTag} {
+			rest, ok := strings.CutPrefix(comment.Text, tag)
+			if ok && (rest == "" || rest[0] == ' ' || rest[0] == '\t') {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Names of the fields of test case types.
+const (
+	nameAttrName     = "name"
+	receiverAttrName = "receiver"
+	inputAttrName    = "input"
+	outputAttrName   = "output"
+)
 
 // extractTestCases analyzes Go source code to find and extract test case metadata.
 // It parses the given content as Go source code and looks for variables that represent test cases.
 //
 // Parameters:
+//   - filename: path of the test case file, used to resolve the imports of the
+//     enclosing module; it may be empty if the file imports no module packages
 //   - content: byte slice containing the Go source code to analyze
 //
 // Returns:
 //   - *testCaseMetadata: Contains extracted test case information including package name and test cases
-//   - error: Returns an error if parsing fails, type checking fails, or no test cases are found
+//   - error: Returns Diagnostics locating the problems if parsing fails, type
+//     checking fails, a checker is malformed, or no test cases are found
 //
 // The function performs the following steps:
-// 1. Parses the Go source code
-// 2. Type checks the parsed AST
-// 3. Traverses the AST looking for variable declarations
+// 1. Parses the Go source code along with the other non-test files of its
+// package, including the test case files of other source files. Test-only
+// case files (<name>_testcase_test.go) also see the other test-only case
+// files, as they are compiled together into the test binary
+// 2. Type checks the package
+// 3. Traverses the AST of the file looking for variable declarations
 // 4. For each variable, checks if it represents a test case by examining its type
 // 5. Extracts test case metadata including names and descriptions
 //
-// Test cases are identified by their type name using the utils.IsTestCase() function.
+// Test cases are identified by their type name following the naming scheme
+// of the generator, see utils.Naming.
 // For each test case, it extracts:
 // - The function name it tests (derived from the type name)
 // - The test case name (variable name)
 // - The test case description (from the "name" field or generated from variable name)
-func extractTestCases(content []byte) (*testCaseMetadata, error) {
-	// Parse file content
+// - The type of the test case's output field
+//
+// Functions named check<Func>, or as the naming scheme names checkers, are
+// recorded as the checkers of the test cases for <Func>.
+//
+// Unlike extractTestFuncs, it fails on type errors anywhere in the package,
+// since the generated tests are compiled along with it.
+func (g *Generator) extractTestCases(filename string, content []byte) (*testCaseMetadata, error) {
+	// Parse file content along with the source and test case files of the
+	// package
+	testOnly := g.naming.IsTestOnlyTestCaseFile(filename)
 	fset := token.NewFileSet()
-	f, err := parser.ParseFile(fset, "", content, parser.ParseComments)
+	f, files, err := parsePackage(fset, g.fsys, filename, content, func(name string) bool {
+		return !strings.HasSuffix(name, "_test.go") || testOnly && g.naming.IsTestOnlyTestCaseFile(name)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("parsing file: %v", err)
+		return nil, err
 	}
 
-	// Create a type checker
-	conf := types.Config{Importer: nil}
+	// Create a type checker resolving imports from local sources and
+	// collecting all errors
+	var ds Diagnostics
+	conf := types.Config{
+		Importer: newLocalImporter(g.packages, g.fsys, g.fsys.dir(filename)),
+		Error:    func(err error) { ds.add(err) },
+	}
 	info := &types.Info{
 		Types: make(map[ast.Expr]types.TypeAndValue),
+		Defs:  make(map[*ast.Ident]types.Object),
 	}
 
-	// Type check the file
-	_, err = conf.Check("", fset, []*ast.File{f}, info)
-	if err != nil {
-		return nil, fmt.Errorf("type checking: %v", err)
+	// Type check the package
+	conf.Check("", fset, files, info)
+	if err := ds.err(); err != nil {
+		return nil, err
 	}
 
 	// Traverse the AST to find variables and their types
-	tcMetadata := testCaseMetadata{pkgName: f.Name.Name}
+	tcMetadata := testCaseMetadata{pkgName: f.Name.Name, pkgPos: fset.Position(f.Name.Pos())}
 	ast.Inspect(f, func(n ast.Node) bool {
 		decl, ok := n.(*ast.GenDecl)
 		if !ok || decl.Tok != token.VAR {
@@ -209,12 +511,18 @@ func extractTestCases(content []byte) (*testCaseMetadata, error) {
 				}
 
 				// Check if the variable is a test case
-				if typeStr == "" || !utils.IsTestCase(typeStr) {
+				if typeStr == "" || !g.naming.IsTestCase(typeStr) {
 					continue
 				}
-				funcStr := utils.FuncNameOf(typeStr)
+				funcStr := g.naming.FuncNameOf(typeStr)
 
 				tcInfo := testCaseInfo{Name: name.Name}
+				if obj := info.Defs[name]; obj != nil {
+					tcInfo.OutputType = fieldTypeOf(obj.Type(), outputAttrName)
+				}
+				if tcInfo.OutputType == "" {
+					tcInfo.OutputType = g.naming.TestCaseOutputTypeNameOf(funcStr)
+				}
 				if compositeLit, ok := vs.Values[i].(*ast.CompositeLit); ok {
 					for _, elt := range compositeLit.Elts {
 						if kv, ok := elt.(*ast.KeyValueExpr); ok {
@@ -223,7 +531,9 @@ func extractTestCases(content []byte) (*testCaseMetadata, error) {
 									if value.Kind != token.STRING {
 										continue
 									}
-									tcInfo.Desc = value.Value
+									if desc, err := strconv.Unquote(value.Value); err == nil {
+										tcInfo.Desc = desc
+									}
 									break
 								}
 							}
@@ -250,8 +560,76 @@ func extractTestCases(content []byte) (*testCaseMetadata, error) {
 		return true
 	})
 
+	// Attach the user-written checkers to the test cases of their functions
+	for _, decl := range f.Decls {
+		fd, ok := decl.(*ast.FuncDecl)
+		if !ok || fd.Recv != nil {
+			continue
+		}
+		funcStr := g.naming.FuncNameOfChecker(fd.Name.Name)
+		if funcStr == "" {
+			continue
+		}
+		if err := validateChecker(fd, info, funcStr, g.naming); err != nil {
+			ds.add(errorAt(fset, fd.Name.Pos(), "%v", err))
+			continue
+		}
+		for i, tc := range tcMetadata.testCases {
+			if tc.FuncName == funcStr {
+				tcMetadata.testCases[i].Checker = fd.Name.Name
+				tcMetadata.testCases[i].checkerPos = fset.Position(fd.Name.Pos())
+			}
+		}
+	}
+	if err := ds.err(); err != nil {
+		return nil, err
+	}
+
 	if len(tcMetadata.testCases) == 0 {
-		return nil, fmt.Errorf("no test cases found in leetcode block")
+		return nil, errorIn(filename, "no test cases found in leetcode block")
 	}
 	return &tcMetadata, nil
 }
+
+// fieldTypeOf returns the type of the named field of a test case struct type,
+// rendered as Go source, or an empty string if there is no such field.
+func fieldTypeOf(t types.Type, fieldName string) string {
+	st, ok := t.Underlying().(*types.Struct)
+	if !ok {
+		return ""
+	}
+	for i := 0; i < st.NumFields(); i++ {
+		if st.Field(i).Name() == fieldName {
+			typStr, _ := qualifiedTypeString(st.Field(i).Type())
+			return typStr
+		}
+	}
+	return ""
+}
+
+// validateChecker verifies that a checker function has the signature
+//
+//	func check<Func>(input test<Func>Input, got test<Func>Output) error
+//
+// where the input and output types, named after naming, may be instantiated
+// generic types.
+func validateChecker(decl *ast.FuncDecl, info *types.Info, funcStr string, naming utils.Naming) error {
+	usage := fmt.Sprintf("checker %s must have the signature func(input %s, got %s) error",
+		decl.Name.Name, naming.TestCaseInputTypeNameOf(funcStr), naming.TestCaseOutputTypeNameOf(funcStr))
+
+	params := extractFields(decl.Type.Params, info)
+	results := extractFields(decl.Type.Results, info)
+	if len(params) != 2 || len(results) != 1 {
+		return fmt.Errorf("%s", usage)
+	}
+	for i, isExpected := range []func(string) bool{naming.IsTestCaseInput, naming.IsTestCaseOutput} {
+		named, ok := params[i].typ.(*types.Named)
+		if !ok || !isExpected(named.Obj().Name()) || naming.FuncNameOf(named.Obj().Name()) != funcStr {
+			return fmt.Errorf("%s", usage)
+		}
+	}
+	if results[0].typ == nil || results[0].typ.String() != "error" {
+		return fmt.Errorf("%s", usage)
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package codegen
 import (
 	"go/ast"
 	"go/types"
+	"strings"
 	"testing"
 )
 
@@ -73,3 +74,277 @@ func TestExtractFields(t *testing.T) {
 		})
 	}
 }
+
+func TestExtractTestCasesChecker(t *testing.T) {
+	const content = `package p
+
+var (
+	diamond = testFindOrderCase{input: testFindOrderInput{n: 2}}
+	pair    = testMaxCase[int]{}
+)
+
+type testFindOrderInput struct{ n int }
+type testFindOrderOutput struct{ order []int }
+type testFindOrderCase struct {
+	name   string
+	input  testFindOrderInput
+	output testFindOrderOutput
+}
+
+type testMaxInput[T int | float64] struct{ a, b T }
+type testMaxOutput[T int | float64] struct{ max T }
+type testMaxCase[T int | float64] struct {
+	name   string
+	input  testMaxInput[T]
+	output testMaxOutput[T]
+}
+
+func checkFindOrder(input testFindOrderInput, got testFindOrderOutput) error { return nil }
+`
+	tcMetadata, err := defaultGenerator.extractTestCases("", []byte(content))
+	if err != nil {
+		t.Fatalf("extractTestCases() error = %v", err)
+	}
+
+	expected := map[string]struct {
+		checker    string
+		outputType string
+	}{
+		"FindOrder": {"checkFindOrder", "testFindOrderOutput"},
+		"Max":       {"", "testMaxOutput[int]"},
+	}
+	if len(tcMetadata.testCases) != len(expected) {
+		t.Fatalf("extractTestCases() found %d functions; expected %d", len(tcMetadata.testCases), len(expected))
+	}
+	for _, tc := range tcMetadata.testCases {
+		want, ok := expected[tc.FuncName]
+		if !ok {
+			t.Errorf("extractTestCases() found unexpected function %s", tc.FuncName)
+			continue
+		}
+		if tc.Checker != want.checker {
+			t.Errorf("%s checker = %q; expected %q", tc.FuncName, tc.Checker, want.checker)
+		}
+		if got := tc.Cases[0].OutputType; got != want.outputType {
+			t.Errorf("%s output type = %q; expected %q", tc.FuncName, got, want.outputType)
+		}
+	}
+
+	badChecker := content + "\nfunc checkMax(input testFindOrderInput, got testMaxOutput[int]) bool { return true }\n"
+	if _, err := defaultGenerator.extractTestCases("", []byte(badChecker)); err == nil {
+		t.Errorf("extractTestCases() with a malformed checker succeeded; expected an error")
+	}
+}
+
+func TestExtractTestFuncsMethods(t *testing.T) {
+	const src = `package p
+
+type Solution struct{}
+type Solution2 struct{ memo map[int]int }
+type Pair[T any] struct{ a, b T }
+
+//go:generate leetcode-gen-test
+func (Solution) twoSum(nums []int, target int) []int { return nil }
+
+//go:generate leetcode-gen-test
+func (s *Solution2) twoSum(nums []int, target int) []int { return nil }
+
+//go:generate leetcode-gen-test
+func twoSum(nums []int, target int) []int { return nil }
+`
+	tfMetadata, err := defaultGenerator.extractTestFuncs("", []byte(src))
+	if err != nil {
+		t.Fatalf("extractTestFuncs() error = %v", err)
+	}
+
+	tests := []struct {
+		receiver           string
+		caseName           string
+		receiverComparable bool
+	}{
+		{"Solution", "SolutionTwoSum", true},
+		{"Solution2", "Solution2TwoSum", false},
+		{"", "TwoSum", false},
+	}
+	if len(tfMetadata.testFuncs) != len(tests) {
+		t.Fatalf("extractTestFuncs() found %d functions; expected %d", len(tfMetadata.testFuncs), len(tests))
+	}
+	for i, test := range tests {
+		tf := tfMetadata.testFuncs[i]
+		if tf.Receiver != test.receiver || tf.CaseName() != test.caseName || tf.receiverComparable != test.receiverComparable {
+			t.Errorf("testFuncs[%d] = {Receiver: %q, CaseName: %q, receiverComparable: %v}; expected {%q, %q, %v}",
+				i, tf.Receiver, tf.CaseName(), tf.receiverComparable, test.receiver, test.caseName, test.receiverComparable)
+		}
+		if result := findTestFunc(tfMetadata.testFuncs, test.caseName); result != &tfMetadata.testFuncs[i] {
+			t.Errorf("findTestFunc(%q) did not find testFuncs[%d]", test.caseName, i)
+		}
+	}
+
+	generic := src + "\n//go:generate leetcode-gen-test\nfunc (p Pair[T]) sum() T { return p.a }\n"
+	if _, err := defaultGenerator.extractTestFuncs("", []byte(generic)); err == nil {
+		t.Errorf("extractTestFuncs() with a method of a generic type succeeded; expected an error")
+	}
+}
+
+func TestHasTestTags(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected bool
+	}{
+		{"function", "package p\n\n//go:generate leetcode-gen-test\nfunc f() int { return 0 }\n", true},
+		{"method", "package p\n\ntype S struct{}\n\n//go:generate leetcode-gen-test\nfunc (S) f() int { return 0 }\n", true},
+		{"type", "package p\n\n//go:generate leetcode-gen-test\ntype Stack struct{}\n", true},
+		{"type in group", "package p\n\ntype (\n\t//go:generate leetcode-gen-test\n\tStack struct{}\n\tQueue struct{}\n)\n", true},
+		{"directive", "package p\n\n//leetcode:test timeout=1s\nfunc f() int { return 0 }\n", true},
+		{"directive without options", "package p\n\n//leetcode:test\nfunc f() int { return 0 }\n", true},
+		{"other generator", "package p\n\n//go:generate stringer -type=Color\ntype Color int\n", false},
+		{"similar names", "package p\n\n//leetcode:tests\n//go:generate leetcode-gen-tests\nfunc f() int { return 0 }\n", false},
+		{"untagged", "package p\n\n// f is not tagged\nfunc f() int { return 0 }\n\ntype (\n\tStack struct{}\n\tQueue struct{}\n)\n", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := HasTestTags([]byte(tt.content))
+			if err != nil {
+				t.Fatalf("HasTestTags() error = %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("HasTestTags() = %v; expected %v", result, tt.expected)
+			}
+		})
+	}
+
+	if _, err := HasTestTags([]byte("package p\n\nfunc f( {}\n")); err == nil {
+		t.Errorf("HasTestTags() with a syntax error succeeded; expected an error")
+	}
+}
+
+func TestExtractTestFuncsTolerant(t *testing.T) {
+	const src = `package p
+
+//leetcode:test
+func half(nums []int) []int { return nums[:len(nums)/2] }
+
+//leetcode:test
+func pending(grid [][]Cell) int { return 0 }
+
+//leetcode:test
+type Stack struct{ items []int }
+
+func Constructor() Stack       { return Stack{} }
+func (s *Stack) Push(x Item)   {}
+
+func unfinished() int {
+	return total +
+}
+`
+	if _, err := defaultGenerator.extractTestFuncs("sol.go", []byte(src)); err == nil {
+		t.Fatalf("extractTestFuncs() with a syntax error succeeded; expected an error")
+	}
+
+	fixed := strings.Replace(src, "return total +", "return total", 1)
+	tfMetadata, err := defaultGenerator.extractTestFuncs("sol.go", []byte(fixed))
+	if err != nil {
+		t.Fatalf("extractTestFuncs() error = %v", err)
+	}
+	if len(tfMetadata.testFuncs) != 1 || tfMetadata.testFuncs[0].FuncName != "half" || len(tfMetadata.designs) != 0 {
+		t.Errorf("extractTestFuncs() found %+v and %+v; expected only half", tfMetadata.testFuncs, tfMetadata.designs)
+	}
+	for _, name := range []string{"pending", "Pending", "Stack"} {
+		if !tfMetadata.skipped[name] {
+			t.Errorf("extractTestFuncs() did not skip %s", name)
+		}
+	}
+	expected := []string{
+		"sol.go:7:6: pending: skipped, the types of its signature do not resolve",
+		"sol.go:7:23: undefined: Cell",
+		"sol.go:10:6: Stack: skipped, the types of its signature do not resolve",
+		"sol.go:13:24: undefined: Item",
+		"sol.go:16:9: undefined: total",
+	}
+	if result := tfMetadata.warnings.Error(); result != strings.Join(expected, "\n") {
+		t.Errorf("extractTestFuncs() warnings =\n%s\nexpected\n%s", result, strings.Join(expected, "\n"))
+	}
+
+	// Test cases of a skipped function cannot be generated
+	const testCase = `package p
+
+var basic = testPendingCase{input: testPendingInput{grid: nil}, output: testPendingOutput{field0: 0}}
+
+type testPendingInput struct{ grid [][]int }
+type testPendingOutput struct{ field0 int }
+type testPendingCase struct {
+	name   string
+	input  testPendingInput
+	output testPendingOutput
+}
+`
+	_, _, err = defaultGenerator.GenerateTestTemplates("sol.go", []byte(fixed), "sol_testcase.go", []byte(testCase))
+	if err == nil || !strings.Contains(err.Error(), "sol.go:7:23: undefined: Cell") {
+		t.Errorf("GenerateTestTemplates() error = %v; expected the reason pending was skipped", err)
+	}
+
+	// Nothing is left to extract if all tagged declarations are skipped
+	onlyPending := strings.Replace(fixed, "//leetcode:test\nfunc half", "func half", 1)
+	onlyPending = strings.Replace(onlyPending, "//leetcode:test\ntype Stack", "type Stack", 1)
+	if _, err := defaultGenerator.extractTestFuncs("sol.go", []byte(onlyPending)); err == nil || !strings.Contains(err.Error(), "pending: skipped") {
+		t.Errorf("extractTestFuncs() error = %v; expected pending to be skipped", err)
+	}
+}
+
+func TestExtractTestFuncsWithoutOutputs(t *testing.T) {
+	const src = `package p
+
+//leetcode:test
+func noop(n int) {}
+`
+	_, err := defaultGenerator.extractTestFuncs("sol.go", []byte(src))
+	expected := "sol.go:4:6: noop: no results and no parameters it can modify to check, add a result or annotate it with //leetcode:inplace <param>"
+	if err == nil || err.Error() != expected {
+		t.Errorf("extractTestFuncs() error = %v; expected %s", err, expected)
+	}
+
+	const inPlace = `package p
+
+//leetcode:test
+func sortColors(nums []int) {}
+`
+	tfMetadata, err := defaultGenerator.extractTestFuncs("sol.go", []byte(inPlace))
+	if err != nil {
+		t.Fatalf("extractTestFuncs() error = %v", err)
+	}
+	if outputs := tfMetadata.testFuncs[0].Outputs(); len(outputs) != 1 || outputs[0].Name != "nums" {
+		t.Errorf("Outputs() = %v; expected nums", outputs)
+	}
+}
+
+func TestExtractTestFuncsInPlaceErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		expected string
+	}{
+		{"by-value parameter", `package p
+
+//leetcode:test
+//leetcode:inplace k
+func rotate(nums []int, k int) {}
+`, `sol.go:4:1: rotate: //leetcode:inplace: parameter "k" cannot be modified in place, only slices, maps and pointers can`},
+		{"no mutable parameter", `package p
+
+//leetcode:test
+//leetcode:inplace
+func fill(board [3]int) {}
+`, "sol.go:4:1: fill: //leetcode:inplace: no parameter can be modified in place"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := defaultGenerator.extractTestFuncs("sol.go", []byte(test.src))
+			if err == nil || err.Error() != test.expected {
+				t.Errorf("extractTestFuncs() error = %v; expected %s", err, test.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package codegen
+
+import (
+	"go/build"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// fileSystem reads the files of packages and modules: from disk, with the
+// paths of the operating system, or from an fs.FS, with slash-separated
+// paths relative to its root.
+type fileSystem struct {
+	// fsys is the file system, or nil for the disk.
+	fsys fs.FS
+}
+
+// readFile reads a whole file.
+func (fsys fileSystem) readFile(name string) ([]byte, error) {
+	if fsys.fsys == nil {
+		return os.ReadFile(name)
+	}
+	return fs.ReadFile(fsys.fsys, name)
+}
+
+// readDir reads the entries of a directory, sorted by name.
+func (fsys fileSystem) readDir(name string) ([]fs.DirEntry, error) {
+	if fsys.fsys == nil {
+		return os.ReadDir(name)
+	}
+	return fs.ReadDir(fsys.fsys, name)
+}
+
+// join joins path elements.
+func (fsys fileSystem) join(elem ...string) string {
+	if fsys.fsys == nil {
+		return filepath.Join(elem...)
+	}
+	return path.Join(elem...)
+}
+
+// dir returns the directory of a file path, or an empty string if the path
+// is empty.
+func (fsys fileSystem) dir(name string) string {
+	switch {
+	case name == "":
+		return ""
+	case fsys.fsys == nil:
+		return filepath.Dir(name)
+	default:
+		return path.Dir(name)
+	}
+}
+
+// base returns the last element of a path.
+func (fsys fileSystem) base(name string) string {
+	if fsys.fsys == nil {
+		return filepath.Base(name)
+	}
+	return path.Base(name)
+}
+
+// fromSlash turns a slash-separated relative path, such as the path of a
+// package within its module, into a path of the file system.
+func (fsys fileSystem) fromSlash(name string) string {
+	if fsys.fsys == nil {
+		return filepath.FromSlash(name)
+	}
+	return name
+}
+
+// abs returns the absolute form of a path, which is the cleaned path itself
+// for an fs.FS, whose paths are relative to its root.
+func (fsys fileSystem) abs(name string) (string, error) {
+	if fsys.fsys == nil {
+		return filepath.Abs(name)
+	}
+	return path.Clean(name), nil
+}
+
+// buildContext returns the build context matching and importing the files of
+// packages in the file system.
+func (fsys fileSystem) buildContext() *build.Context {
+	ctxt := build.Default
+	if fsys.fsys == nil {
+		return &ctxt
+	}
+	ctxt.GOPATH = ""
+	ctxt.JoinPath = path.Join
+	ctxt.IsAbsPath = path.IsAbs
+	ctxt.SplitPathList = func(list string) []string { return strings.Split(list, ":") }
+	ctxt.HasSubdir = func(root, dir string) (string, bool) { return "", false }
+	ctxt.IsDir = func(name string) bool {
+		info, err := fs.Stat(fsys.fsys, name)
+		return err == nil && info.IsDir()
+	}
+	ctxt.ReadDir = func(dir string) ([]fs.FileInfo, error) {
+		entries, err := fs.ReadDir(fsys.fsys, dir)
+		if err != nil {
+			return nil, err
+		}
+		infos := make([]fs.FileInfo, 0, len(entries))
+		for _, entry := range entries {
+			info, err := entry.Info()
+			if err != nil {
+				return nil, err
+			}
+			infos = append(infos, info)
+		}
+		return infos, nil
+	}
+	ctxt.OpenFile = func(name string) (io.ReadCloser, error) {
+		return fsys.fsys.Open(name)
+	}
+	return &ctxt
+}
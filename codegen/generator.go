@@ -1,159 +1,531 @@
 package codegen
 
 import (
+	"bytes"
 	"fmt"
-	"go/format"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
 	"strings"
-	"text/template"
-
-	"github.com/Ezer015/leetcode-gen-test/utils"
+	"time"
 )
 
-const testTag = "go:generate"
+// generatedHeader marks test files as generated, following the convention of
+// https://go.dev/s/generatedcode, so that tools and users leave them alone.
+const generatedHeader = "// Code generated by leetcode-gen-test. DO NOT EDIT."
+
+// legacyTestComment starts the comment of each test in files generated before
+// they carried generatedHeader.
+const legacyTestComment = "// Auto-generated test for "
+
+// lctestImportPath is the import path of the runtime helpers used by
+// generated tests.
+const lctestImportPath = "github.com/Ezer015/leetcode-gen-test/lctest"
 
 type fieldInfo struct {
 	Name string
 	Type string
+
+	typ     types.Type
+	imports []string
 }
+
+// Notation reports whether test cases spell the field in LeetCode notation.
+func (f fieldInfo) Notation() bool {
+	return hasNodes(f.typ)
+}
+
+// NotationExample shows how the field is spelled in LeetCode notation.
+func (f fieldInfo) NotationExample() string {
+	return notationExampleOf(f.typ)
+}
+
+// SchemaType returns the type of the field in the test case structs: a string
+// for values spelled in LeetCode notation, or else the field type itself.
+func (f fieldInfo) SchemaType() string {
+	if f.Notation() {
+		return "string"
+	}
+	return f.Type
+}
+
 type testCaseInfo struct {
 	Name string
+	// Desc is the name the case is run under: the name field of the case,
+	// or else its variable name split into words.
 	Desc string
+	// OutputType is the type of the output field of the case, or else the
+	// name of the output type of its function.
+	OutputType string
 }
 
 type testFuncData struct {
 	FuncName string
-	Params   []fieldInfo
-	Results  []fieldInfo
-	Generics []fieldInfo
+	// Receiver is the name of the type the function is a method of, e.g.
+	// "Solution", or empty for plain functions.
+	Receiver  string
+	Params    []fieldInfo
+	Results   []fieldInfo
+	Generics  []fieldInfo
+	Tolerance Tolerance
+	Unordered []int
+	InPlace   []string
+	// Alias is the name set with the name option of the test directive,
+	// which replaces CaseName, or empty.
+	Alias string
+	// Timeout limits the time each test case may take, or is zero.
+	Timeout time.Duration
+
+	// receiverComparable is set if the receiver holds no references, so
+	// that copying it leaves the receiver of the test case untouched.
+	receiverComparable bool
+	// pos is the position of the function name in the source file.
+	pos token.Position
 }
+
+// CaseName returns the name the test case types of the function are derived
+// from: the function name with its first letter capitalized, prefixed with
+// the receiver type name for methods so that same-named methods of different
+// types do not collide, e.g. "SolutionTwoSum". A name set with the test
+// directive takes precedence.
+func (tf testFuncData) CaseName() string {
+	if tf.Alias != "" {
+		return tf.Alias
+	}
+	return upperFirst(tf.Receiver) + upperFirst(tf.FuncName)
+}
+
+// displayName returns the name of the function as declared, qualified with
+// the receiver type for methods, e.g. "Solution.twoSum".
+func (tf testFuncData) displayName() string {
+	if tf.Receiver != "" {
+		return tf.Receiver + "." + tf.FuncName
+	}
+	return tf.FuncName
+}
+
+// Outputs returns the values a test case expects after calling the function:
+// its results followed by the parameters it modifies in place.
+func (tf testFuncData) Outputs() []fieldInfo {
+	return append(append([]fieldInfo{}, tf.Results...), tf.mutatedParams()...)
+}
+
+// mutatedParams returns the parameters the function modifies in place.
+func (tf testFuncData) mutatedParams() []fieldInfo {
+	var params []fieldInfo
+	for _, name := range tf.InPlace {
+		for _, p := range tf.Params {
+			if p.Name == name {
+				params = append(params, p)
+			}
+		}
+	}
+	return params
+}
+
 type testCaseData struct {
 	FuncName string
+	Checker  string
 	Cases    []testCaseInfo
+
+	// checkerPos is the position of the checker name in the test case file.
+	checkerPos token.Position
 }
 
 type testFuncMetadata struct {
 	pkgName   string
 	testFuncs []testFuncData
+	designs   []designData
+
+	// skipped holds the names of the tagged declarations whose signatures
+	// did not resolve, both as declared and as their case names.
+	skipped map[string]bool
+	// warnings holds the type errors that did not prevent the extraction.
+	warnings Diagnostics
 }
 type testCaseMetadata struct {
 	pkgName   string
 	testCases []testCaseData
+
+	// pkgPos is the position of the package name in the test case file.
+	pkgPos token.Position
+}
+
+// caseSchema describes the declarations of a test case template: the input,
+// output and case types that test case variables are built from, preceded by
+// a commented-out example case.
+type caseSchema struct {
+	// caseName is the name the types are derived from, and typeName,
+	// inputTypeName and outputTypeName are the names of the types.
+	caseName       string
+	typeName       string
+	inputTypeName  string
+	outputTypeName string
+	// doc holds the lines of the doc comment of the template.
+	doc []string
+	// receiver is the type of the receiver of the cases, or empty for plain
+	// functions.
+	receiver        string
+	receiverComment string
+	// generics are the type parameters of the case type, of which the input
+	// and output types take those they use.
+	generics       []fieldInfo
+	inputGenerics  []fieldInfo
+	outputGenerics []fieldInfo
+	inputs         []templateField
+	outputs        []templateField
 }
 
-const testCaseTemplate = `// Auto-generated test case template for {{.FuncName}}
-{{- $paramGenerics := FilterGenerics .Generics .Params}}
-{{- $resultGenerics := FilterGenerics .Generics .Results}}
-{{- $standardizedFuncName := .FuncName | UpperFirst}}
-{{- $testCaseInputTypeName := TestCaseInputTypeNameOf $standardizedFuncName}}
-{{- $testCaseOutputTypeName := TestCaseOutputTypeNameOf $standardizedFuncName}}
-{{- $testCaseTypeName := TestCaseTypeNameOf $standardizedFuncName}}
-var (
-/* 	
-	_ = {{$testCaseTypeName}}{{TypeListOf .Generics}}{
-		input: {{$testCaseInputTypeName}}{{TypeListOf $paramGenerics}}{
-			{{- range .Params}}
-			{{.Name}}: ...,
-			{{- end}}
-			},
-		output: {{$testCaseOutputTypeName}}{{TypeListOf $resultGenerics}}{
-			{{- range .Results}}
-			{{.Name}}: ...,
-			{{- end}}
+// templateField is a field of a type declared by a test case template.
+type templateField struct {
+	name string
+	typ  string
+	// comment is the trailing comment of the field, or empty.
+	comment string
+}
+
+// namedSchema returns a test case template whose types are named after
+// caseName.
+func (g *Generator) namedSchema(caseName string) caseSchema {
+	return caseSchema{
+		caseName:       caseName,
+		typeName:       g.naming.TestCaseTypeNameOf(caseName),
+		inputTypeName:  g.naming.TestCaseInputTypeNameOf(caseName),
+		outputTypeName: g.naming.TestCaseOutputTypeNameOf(caseName),
+	}
+}
+
+// schemaOf describes the test case template of a test function. Fields
+// spelled in LeetCode notation are strings, commented with their Go type.
+func (g *Generator) schemaOf(tf testFuncData) caseSchema {
+	s := g.namedSchema(tf.CaseName())
+	s.doc = []string{"Auto-generated test case template for " + generatedFrom(tf.displayName(), tf.pos)}
+	s.generics = tf.Generics
+	s.inputGenerics = filterGenerics(tf.Generics, tf.Params)
+	s.outputGenerics = filterGenerics(tf.Generics, tf.Outputs())
+	if tf.Receiver != "" {
+		s.receiver = tf.Receiver
+		s.receiverComment = fmt.Sprintf("Receiver of %s, the zero value if omitted", tf.FuncName)
+	}
+	for _, p := range tf.Params {
+		s.inputs = append(s.inputs, templateFieldOf(p))
+	}
+	for _, o := range tf.Outputs() {
+		s.outputs = append(s.outputs, templateFieldOf(o))
+	}
+
+	if tf.Timeout > 0 {
+		s.doc = append(s.doc, fmt.Sprintf("Each case must finish within %v", tf.Timeout))
+	}
+	checker := &ast.FuncDecl{
+		Name: ident(g.naming.CheckerFuncNameOf(s.caseName)),
+		Type: &ast.FuncType{
+			Params: &ast.FieldList{List: []*ast.Field{
+				{Names: []*ast.Ident{ident("input")}, Type: instantiate(ident(s.inputTypeName), identsOf(s.inputGenerics)...)},
+				{Names: []*ast.Ident{ident("got")}, Type: instantiate(ident(s.outputTypeName), identsOf(s.outputGenerics)...)},
+			}},
+			Results: &ast.FieldList{List: []*ast.Field{{Type: ident("error")}}},
 		},
-	} 
-*/
-	
-)
+	}
+	s.doc = append(s.doc, "To accept any valid answer instead of comparing with output, define:", render(checker))
+	return s
+}
+
+// templateFieldOf describes the field of a test case template holding the
+// value of a parameter or an output.
+func templateFieldOf(f fieldInfo) templateField {
+	field := templateField{name: f.Name, typ: f.SchemaType()}
+	if f.Notation() {
+		field.comment = fmt.Sprintf("%s in LeetCode notation, e.g. %s", f.Type, f.NotationExample())
+	}
+	return field
+}
+
+// identsOf returns the names of fields as identifiers, e.g. to instantiate a
+// generic type with its own type parameters.
+func identsOf(fields []fieldInfo) []ast.Expr {
+	idents := make([]ast.Expr, len(fields))
+	for i, f := range fields {
+		idents[i] = ident(f.Name)
+	}
+	return idents
+}
+
+// testCaseCodeOf lays out and prints a test case template: its doc comment
+// above an empty variable declaration holding the example case as a comment,
+// followed by the input, output and case types.
+//
+// Parameters:
+//   - s: The schema of the template
+//
+// Returns:
+//   - []byte: The printed template
+//   - error: An error if a type of the schema cannot be spelled
+func testCaseCodeOf(s caseSchema) ([]byte, error) {
+	example, err := exampleCaseOf(s)
+	if err != nil {
+		return nil, err
+	}
+
+	l := newLayout()
+	doc := l.comment(s.doc...)
+	pos := l.next()
+	l.blockComment(example)
+	l.blank()
+	decls := []ast.Decl{&ast.GenDecl{Doc: doc, TokPos: pos, Tok: token.VAR, Lparen: pos, Rparen: l.next()}}
+
+	caseFields := []templateField{{name: nameAttrName, typ: "string"}}
+	if s.receiver != "" {
+		caseFields = append(caseFields, templateField{name: receiverAttrName, typ: s.receiver, comment: s.receiverComment})
+	}
+	caseFields = append(caseFields,
+		templateField{name: inputAttrName, typ: s.inputTypeName + nameListOf(s.inputGenerics)},
+		templateField{name: outputAttrName, typ: s.outputTypeName + nameListOf(s.outputGenerics)},
+	)
+	for _, typ := range []struct {
+		name     string
+		generics []fieldInfo
+		fields   []templateField
+	}{
+		{s.inputTypeName, s.inputGenerics, s.inputs},
+		{s.outputTypeName, s.outputGenerics, s.outputs},
+		{s.typeName, s.generics, caseFields},
+	} {
+		l.blank()
+		decl, err := structDeclOf(l, typ.name, typ.generics, typ.fields)
+		if err != nil {
+			return nil, err
+		}
+		decls = append(decls, decl)
+	}
+	return l.printDecls(decls)
+}
 
-type {{$testCaseInputTypeName}}{{FieldListOf $paramGenerics}} struct {
-    {{- range .Params}}
-    {{.Name}} {{.Type}}
-    {{- end}}}
-
-type {{$testCaseOutputTypeName}}{{FieldListOf $resultGenerics}} struct {
-    {{- range .Results}}
-    {{.Name}} {{.Type}}
-    {{- end}}}
-
-type {{$testCaseTypeName}}{{FieldListOf .Generics}} struct {
-	name   string
-	input  {{$testCaseInputTypeName}}{{NameListOf $paramGenerics}}
-	output {{$testCaseOutputTypeName}}{{NameListOf $resultGenerics}}
-}`
-
-const testTemplate = `// Auto-generated test for {{.FuncName}}
-{{- $standardizedFuncName := .FuncName | UpperFirst}}
-{{- $results := .Results}}
-func Test{{$standardizedFuncName}}(t *testing.T) {
-    {{- range $_, $c := .Cases}}
-    t.Run("{{.Desc}}", func(t *testing.T) {
-        {{- if $.Results}}{{range $i, $r := $.Results}}{{if $i}}, {{end}}{{$r.Name}}{{end}} := {{end}}{{$.FuncName}}({{range $i, $p := $.Params}}{{if $i}}, {{end}}{{$c.Name}}.input.{{$p.Name}}{{end}})
-        {{- range $.Results}}
-        if {{.Name}} != {{with $c}}{{.Name}}{{end}}.output.{{.Name}} {
-            t.Errorf("{{$.FuncName}}() {{.Name}} = %+v, want {{.Name}} = %+v", {{.Name}}, {{with $c}}{{.Name}}{{end}}.output.{{.Name}})
-        }
-        {{- end}}
-    })
-    {{- end}}
-}`
+// exampleCaseOf prints the example case of a test case template, with "..."
+// in place of the values, indented to be held in a block comment.
+func exampleCaseOf(s caseSchema) (string, error) {
+	l := newLayout()
+	pos := l.next()
+	typeArgs, err := typesAt(pos, s.generics)
+	if err != nil {
+		return "", err
+	}
+	lit := &ast.CompositeLit{Type: instantiate(ident(s.typeName), typeArgs...), Lbrace: pos}
+	if s.receiver != "" {
+		lit.Elts = append(lit.Elts, at(l.next(), &ast.KeyValueExpr{Key: ident(receiverAttrName), Value: ident("...")}))
+		l.trailingComment("optional")
+	}
+	for _, attr := range []struct {
+		name     string
+		typeName string
+		generics []fieldInfo
+		fields   []templateField
+	}{
+		{inputAttrName, s.inputTypeName, s.inputGenerics, s.inputs},
+		{outputAttrName, s.outputTypeName, s.outputGenerics, s.outputs},
+	} {
+		attrPos := l.next()
+		typeArgs, err := typesAt(attrPos, attr.generics)
+		if err != nil {
+			return "", err
+		}
+		value := &ast.CompositeLit{Type: instantiate(ident(attr.typeName), typeArgs...), Lbrace: attrPos, Rbrace: attrPos}
+		for _, f := range attr.fields {
+			value.Elts = append(value.Elts, at(l.next(), &ast.KeyValueExpr{Key: ident(f.name), Value: ident("...")}))
+		}
+		if len(value.Elts) > 0 {
+			value.Rbrace = l.next()
+		}
+		lit.Elts = append(lit.Elts, at(attrPos, &ast.KeyValueExpr{Key: ident(attr.name), Value: value}))
+	}
+	lit.Rbrace = l.next()
+
+	code, err := l.print(at(pos, &ast.AssignStmt{Lhs: []ast.Expr{ident("_")}, Tok: token.ASSIGN, Rhs: []ast.Expr{lit}}))
+	if err != nil {
+		return "", err
+	}
+	return "\t" + strings.ReplaceAll(string(code), "\n", "\n\t"), nil
+}
+
+// typesAt parses the types of fields, such as the constraints of type
+// parameters, placing them on the line of pos.
+func typesAt(pos token.Pos, fields []fieldInfo) ([]ast.Expr, error) {
+	exprs := make([]ast.Expr, len(fields))
+	for i, f := range fields {
+		expr, err := exprAt(pos, f.Type)
+		if err != nil {
+			return nil, err
+		}
+		exprs[i] = expr
+	}
+	return exprs, nil
+}
+
+// structDeclOf lays out the declaration of a struct type with one field per
+// line, e.g. "type testSumInput[T int | float64] struct { a T; b T }".
+func structDeclOf(l *layout, name string, typeParams []fieldInfo, fields []templateField) (*ast.GenDecl, error) {
+	pos := l.next()
+	spec := &ast.TypeSpec{Name: ident(name)}
+	if len(typeParams) > 0 {
+		constraints, err := typesAt(pos, typeParams)
+		if err != nil {
+			return nil, err
+		}
+		spec.TypeParams = &ast.FieldList{}
+		for i, p := range typeParams {
+			spec.TypeParams.List = append(spec.TypeParams.List, &ast.Field{Names: []*ast.Ident{ident(p.Name)}, Type: constraints[i]})
+		}
+	}
+
+	st := &ast.StructType{Struct: pos, Fields: &ast.FieldList{Opening: pos, Closing: pos}}
+	for _, f := range fields {
+		fieldPos := l.next()
+		typ, err := exprAt(fieldPos, f.typ)
+		if err != nil {
+			return nil, err
+		}
+		field := &ast.Field{Names: []*ast.Ident{at(fieldPos, ident(f.name))}, Type: typ}
+		if f.comment != "" {
+			field.Comment = l.trailingComment(f.comment)
+		}
+		st.Fields.List = append(st.Fields.List, field)
+	}
+	if len(fields) > 0 {
+		st.Fields.Closing = l.next()
+	}
+	spec.Type = st
+	return &ast.GenDecl{TokPos: pos, Tok: token.TYPE, Specs: []ast.Spec{spec}}, nil
+}
 
 // GenerateTestCaseTemplates generates test case template code from the given source content.
-// It extracts test function metadata from the content, applies the template to generate test cases,
-// and formats the generated code.
+// It extracts test function metadata from the content, builds the syntax tree of the test
+// cases of each tagged declaration and prints it.
 //
 // The function:
 // 1. Extracts test function metadata using extractTestFuncs
-// 2. Creates a new template with custom functions for test case generation
-// 3. Generates formatted test case code for each test function
-// 4. Includes a go:generate directive and package declaration
+// 2. Lays out the test case template of each test function and design type,
+// see testCaseCodeOf
+// 3. Prints a go:generate directive, package declaration and the imports
+// needed by the types of the test case fields, followed by the templates
 //
 // Parameters:
+//   - srcFile: The path of the source file, used to resolve its imports
 //   - content: The source code content as a byte slice
 //
 // Returns:
 //   - []byte: The generated and formatted test case code
+//   - Diagnostics: Warnings about type errors in the package that did not
+//     prevent the generation, including tagged declarations that were skipped
 //   - error: An error if test case generation fails
-func GenerateTestCaseTemplates(content []byte) ([]byte, error) {
-	tfMetadata, err := extractTestFuncs(content)
+func (g *Generator) GenerateTestCaseTemplates(srcFile string, content []byte) ([]byte, Diagnostics, error) {
+	tfMetadata, err := g.extractTestFuncs(srcFile, content)
 	if err != nil {
-		return nil, fmt.Errorf("extracting test function: %v", err)
-	}
-
-	// Generate test case template
-	tmpl, err := template.New("testcase").Funcs(template.FuncMap{
-		"UpperFirst":               upperFirst,
-		"FilterGenerics":           filterGenerics,
-		"TestCaseTypeNameOf":       utils.TestCaseTypeNameOf,
-		"TestCaseInputTypeNameOf":  utils.TestCaseInputTypeNameOf,
-		"TestCaseOutputTypeNameOf": utils.TestCaseOutputTypeNameOf,
-		"FieldListOf":              fieldListOf,
-		"NameListOf":               nameListOf,
-		"TypeListOf":               typeListOf,
-	}).Parse(testCaseTemplate)
+		return nil, nil, fmt.Errorf("extracting test function: %w", err)
+	}
+	result, err := g.testCaseFileOf(tfMetadata)
 	if err != nil {
-		return nil, fmt.Errorf("parsing test case template: %v", err)
+		return nil, nil, err
 	}
+	return result, tfMetadata.warnings, nil
+}
 
-	var result strings.Builder
-	result.WriteString(fmt.Sprintf(`//go:generate leetcode-gen-test generate --test-case=$GOFILE
-package %s
+// GenerateTestCaseTemplates generates the test case templates of source code
+// with the default options, see Generator.GenerateTestCaseTemplates. Since
+// the source file is unnamed, it may only import standard library packages,
+// and the warnings about its package are dropped.
+func GenerateTestCaseTemplates(content []byte) ([]byte, error) {
+	result, _, err := defaultGenerator.GenerateTestCaseTemplates("", content)
+	return result, err
+}
+
+// testCaseFileOf prints the test case file holding the templates of the
+// tagged functions and types of a source file.
+func (g *Generator) testCaseFileOf(tfMetadata *testFuncMetadata) ([]byte, error) {
+	chunks, err := g.testCaseChunksOf(tfMetadata)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		sections [][]byte
+		imports  []string
+	)
+	for _, chunk := range chunks {
+		sections = append(sections, chunk.code)
+		imports = append(imports, chunk.imports...)
+	}
+
+	l := newLayout()
+	l.verbatim("//go:generate leetcode-gen-test generate --test-case=$GOFILE")
+	return fileOf(l, tfMetadata.pkgName, uniqueSorted(imports), sections)
+}
 
-`, tfMetadata.pkgName))
+// testCaseChunk is the test case template of a single test function or
+// design type.
+type testCaseChunk struct {
+	// caseName is the name the test case types are derived from
+	caseName string
+	code     []byte
+	// imports are the import paths needed by the types of the test case fields
+	imports []string
+}
+
+// testCaseChunksOf renders the test case templates of the tagged functions
+// and types of a source file.
+//
+// Parameters:
+//   - tfMetadata: The tagged functions and types extracted from the source file
+//
+// Returns:
+//   - []testCaseChunk: The formatted templates, in declaration order with
+//     functions first
+//   - error: Diagnostics located at the declarations whose templates cannot
+//     be generated
+func (g *Generator) testCaseChunksOf(tfMetadata *testFuncMetadata) ([]testCaseChunk, error) {
+	var (
+		chunks []testCaseChunk
+		ds     Diagnostics
+	)
 	for _, tf := range tfMetadata.testFuncs {
-		var buf strings.Builder
-		if err := tmpl.Execute(&buf, tf); err != nil {
-			return nil, fmt.Errorf("executing test case template: %v", err)
+		// Fields spelled in LeetCode notation are strings
+		var imports []string
+		for _, field := range append(tf.Params, tf.Outputs()...) {
+			if !field.Notation() {
+				imports = append(imports, field.imports...)
+			}
 		}
+		imports = append(imports, importsOf(tf.Generics...)...)
 
-		formattedCode, err := format.Source([]byte(buf.String()))
+		if g.templates.TestCase != nil {
+			code, imports, err := g.render(g.templates.TestCase, tf.displayName(), functionOf(tf), imports)
+			if err != nil {
+				ds.add(err)
+				continue
+			}
+			chunks = append(chunks, testCaseChunk{caseName: tf.CaseName(), code: code, imports: imports})
+			continue
+		}
+		code, err := testCaseCodeOf(g.schemaOf(tf))
 		if err != nil {
-			return nil, fmt.Errorf("formatting test case template: %v", err)
+			ds.add(Diagnostic{Pos: tf.pos, Message: fmt.Sprintf("%s: generating test case template: %v", tf.displayName(), err)})
+			continue
 		}
-
-		result.Write(formattedCode)
+		chunks = append(chunks, testCaseChunk{caseName: tf.CaseName(), code: code, imports: uniqueSorted(imports)})
 	}
 
-	return []byte(result.String()), nil
+	for _, d := range tfMetadata.designs {
+		code, err := testCaseCodeOf(g.designSchemaOf(d))
+		if err != nil {
+			ds.add(Diagnostic{Pos: d.pos, Message: fmt.Sprintf("%s: generating test case template: %v", d.TypeName, err)})
+			continue
+		}
+		chunks = append(chunks, testCaseChunk{caseName: upperFirst(d.TypeName), code: code})
+	}
+	if err := ds.err(); err != nil {
+		return nil, err
+	}
+	return chunks, nil
 }
 
 // GenerateTestTemplates generates test function templates based on source code and test case content.
@@ -164,90 +536,568 @@ package %s
 // 1. Extracts test functions metadata from the source code
 // 2. Extracts test cases metadata from the test case content
 // 3. Verifies that package names match between source and test files
-// 4. Generates test templates using Go's template package
-// 5. Formats the generated code
+// 4. Lays out the test of each function and design type with its cases, see
+// testCodeOf and designTestCodeOf
+// 5. Prints the tests, marking the file as generated with a header
+//
+// Problems found in the source and test case files are reported as
+// Diagnostics, possibly wrapped, see DiagnosticsOf.
 //
 // Parameters:
+//   - srcFile: path of the source file, used to resolve its imports
 //   - srcContent: byte slice containing the source code
+//   - testCaseFile: path of the test case file, used to resolve its imports
 //   - testCaseContent: byte slice containing the test case definitions
 //
 // Returns:
 //   - []byte: formatted test template code
+//   - Diagnostics: warnings about type errors in the source package that did
+//     not prevent the generation
 //   - error: an error if any step in the generation process fails
 //
-// The generated tests follow a standard template format and include proper package declaration,
-// test function signatures, and test cases with input parameters and expected results.
-func GenerateTestTemplates(srcContent []byte, testCaseContent []byte) ([]byte, error) {
-	tfMetadata, err := extractTestFuncs(srcContent)
+// The generated tests include proper package declaration, test function signatures, and
+// test cases with input parameters and expected results. Each test is commented with the
+// declaration it was generated from.
+func (g *Generator) GenerateTestTemplates(srcFile string, srcContent []byte, testCaseFile string, testCaseContent []byte) ([]byte, Diagnostics, error) {
+	tfMetadata, err := g.extractTestFuncs(srcFile, srcContent)
 	if err != nil {
-		return nil, fmt.Errorf("extracting test function: %v", err)
+		return nil, nil, fmt.Errorf("extracting test function: %w", err)
 	}
-	tcMetadata, err := extractTestCases(testCaseContent)
+	tcMetadata, err := g.extractTestCases(testCaseFile, testCaseContent)
 	if err != nil {
-		return nil, fmt.Errorf("extracting test cases: %v", err)
+		return nil, nil, fmt.Errorf("extracting test cases: %w", err)
 	}
+	result, err := g.testFileOf(tfMetadata, tcMetadata)
+	if err != nil {
+		return nil, nil, err
+	}
+	return result, tfMetadata.warnings, nil
+}
+
+// GenerateTestTemplates generates the tests of source code from its test
+// cases with the default options, see Generator.GenerateTestTemplates. Since
+// the files are unnamed, they may only import standard library packages, and
+// the warnings about the source package are dropped.
+func GenerateTestTemplates(srcContent []byte, testCaseContent []byte) ([]byte, error) {
+	result, _, err := defaultGenerator.GenerateTestTemplates("", srcContent, "", testCaseContent)
+	return result, err
+}
+
+// testFileOf prints the test file running the test cases of the tagged
+// functions and types of a source file.
+//
+// Parameters:
+//   - tfMetadata: The tagged functions and types extracted from the source file
+//   - tcMetadata: The test cases extracted from the test case file
+//
+// Returns:
+//   - []byte: The printed test file
+//   - error: Diagnostics located at the problems of either file
+func (g *Generator) testFileOf(tfMetadata *testFuncMetadata, tcMetadata *testCaseMetadata) ([]byte, error) {
 	if tfMetadata.pkgName != tcMetadata.pkgName {
-		return nil, fmt.Errorf("package name mismatch: %s != %s", tfMetadata.pkgName, tcMetadata.pkgName)
+		return nil, Diagnostic{Pos: tcMetadata.pkgPos, Message: fmt.Sprintf("package name mismatch: %s != %s", tfMetadata.pkgName, tcMetadata.pkgName)}
 	}
 
-	// Generate test template
-	tmpl, err := template.New("test").Funcs(template.FuncMap{
-		"UpperFirst": upperFirst,
-	}).Parse(testTemplate)
+	var (
+		sections      [][]byte
+		ds            Diagnostics
+		skippedCases  bool
+		lctestHelpers bool
+		timeouts      bool
+		// typeImports holds the packages of the types spelled in the tests,
+		// along with the packages the tests written by templates need
+		typeImports []string
+	)
+	for _, tc := range tcMetadata.testCases {
+		// The warnings tell why the declaration was skipped
+		if tfMetadata.skipped[tc.FuncName] {
+			if !skippedCases {
+				ds = append(ds, tfMetadata.warnings...)
+			}
+			skippedCases = true
+			continue
+		}
+
+		if d := findDesign(tfMetadata.designs, tc.FuncName); d != nil {
+			if tc.Checker != "" {
+				ds.add(Diagnostic{Pos: tc.checkerPos, Message: fmt.Sprintf("%s: checkers are not supported for design problems", d.TypeName)})
+				continue
+			}
+			calls := designCallsOf(*d)
+			typeImports = append(typeImports, importsOf(d.Constructor.Results...)...)
+			for _, call := range calls {
+				typeImports = append(typeImports, importsOf(call.Params...)...)
+				if call.Check != nil {
+					typeImports = append(typeImports, call.Check.imports...)
+				}
+			}
+			lctestHelpers = true
+			timeouts = timeouts || d.Timeout > 0
+
+			code, err := designTestCodeOf(*d, tc, calls)
+			if err != nil {
+				ds.add(Diagnostic{Pos: d.pos, Message: fmt.Sprintf("%s: generating test: %v", d.TypeName, err)})
+				continue
+			}
+			sections = append(sections, code)
+			continue
+		}
+
+		var tf testFuncData
+		if matched := findTestFunc(tfMetadata.testFuncs, tc.FuncName); matched != nil {
+			tf = *matched
+		} else {
+			tf.FuncName = tc.FuncName
+		}
+		// Receivers holding references are copied so that cases stay intact
+		cloneReceiver := tf.Receiver != "" && !tf.receiverComparable
+		outputs, err := resultChecksOf(tf)
+		if err != nil {
+			ds.add(Diagnostic{Pos: tf.pos, Message: err.Error()})
+			continue
+		}
+		args := argsOf(tf)
+		if g.templates.Test != nil {
+			candidates := []string{lctestImportPath, "reflect", "time"}
+			for _, field := range append(tf.Params, tf.Outputs()...) {
+				candidates = append(candidates, field.imports...)
+			}
+			code, imports, err := g.render(g.templates.Test, tf.displayName(), testTemplateDataOf(tf, tc, args, outputs, cloneReceiver), candidates)
+			if err != nil {
+				ds.add(err)
+				continue
+			}
+			sections = append(sections, code)
+			typeImports = append(typeImports, imports...)
+			continue
+		}
+		lctestHelpers = lctestHelpers || cloneReceiver || tf.Timeout > 0
+		timeouts = timeouts || tf.Timeout > 0
+		for _, arg := range args {
+			lctestHelpers = lctestHelpers || arg.Decode || arg.Clone
+			if arg.Decode {
+				typeImports = append(typeImports, arg.imports...)
+			}
+		}
+		for _, output := range outputs {
+			lctestHelpers = lctestHelpers || output.Notation
+		}
+		// Outputs are passed to the checker, if any, instead of being compared
+		if tc.Checker == "" {
+			for _, output := range outputs {
+				lctestHelpers = lctestHelpers || output.IsDeep() || output.IsTolerant() || output.IsUnordered()
+				if output.Notation {
+					typeImports = append(typeImports, output.imports...)
+				}
+			}
+		}
+
+		code, err := testCodeOf(tf, tc, args, outputs, cloneReceiver)
+		if err != nil {
+			ds.add(Diagnostic{Pos: tf.pos, Message: fmt.Sprintf("%s: generating test: %v", tf.displayName(), err)})
+			continue
+		}
+		sections = append(sections, code)
+	}
+	if err := ds.err(); err != nil {
+		return nil, err
+	}
+
+	var imports []string
+	if lctestHelpers {
+		imports = append(imports, lctestImportPath)
+	}
+	if timeouts {
+		imports = append(imports, "time")
+	}
+	imports = append(imports, "testing")
+
+	l := newLayout()
+	l.verbatim(generatedHeader)
+	l.blank()
+	return fileOf(l, tcMetadata.pkgName, uniqueSorted(append(imports, typeImports...)), sections)
+}
+
+// fileOf prints a generated file: the package clause and the import
+// declaration, laid out on l after the comments preceding them, followed by
+// the printed sections separated by blank lines.
+//
+// Parameters:
+//   - l: The layout holding the comments preceding the package clause
+//   - pkgName: The package name
+//   - imports: The import paths, sorted
+//   - sections: The printed declarations, each ending with a newline
+//
+// Returns:
+//   - []byte: The printed file
+//   - error: An error if the file cannot be printed
+func fileOf(l *layout, pkgName string, imports []string, sections [][]byte) ([]byte, error) {
+	f := &ast.File{Package: l.next(), Name: ident(pkgName)}
+	if len(imports) > 0 {
+		l.blank()
+		f.Decls = append(f.Decls, importDeclOf(l, imports))
+	}
+	code, err := l.print(f)
 	if err != nil {
-		return nil, fmt.Errorf("parsing test template: %v", err)
+		return nil, fmt.Errorf("printing file: %v", err)
+	}
+
+	var result bytes.Buffer
+	result.Write(code)
+	for _, section := range sections {
+		result.WriteString("\n")
+		result.Write(section)
+	}
+	return result.Bytes(), nil
+}
+
+// testCodeOf lays out and prints the test of a function, which runs each of
+// its cases as a subtest: it decodes and copies the inputs as needed, calls
+// the function and checks the outputs, or passes them to the checker of the
+// cases if there is one.
+//
+// Parameters:
+//   - tf: The test function
+//   - tc: The test cases of the function
+//   - args: How the parameters are passed, see argsOf
+//   - outputs: How the outputs are checked, see resultChecksOf
+//   - cloneReceiver: Whether the receiver of each case is copied first
+//
+// Returns:
+//   - []byte: The printed test
+//   - error: An error if a type of the function cannot be spelled
+func testCodeOf(tf testFuncData, tc testCaseData, args []argInfo, outputs []resultCheck, cloneReceiver bool) ([]byte, error) {
+	l := newLayout()
+	doc := []string{"Auto-generated test for " + generatedFrom(tf.displayName(), tf.pos)}
+	if tc.Checker != "" {
+		doc = append(doc, "Outputs are checked by "+generatedFrom(tc.Checker, tc.checkerPos))
 	}
+	decl := &ast.FuncDecl{Doc: l.comment(doc...), Name: ident("Test" + tf.CaseName())}
+	pos := l.next()
+	decl.Type = testFuncTypeAt(pos)
+	decl.Body = &ast.BlockStmt{Lbrace: pos}
 
-	var result strings.Builder
-	result.WriteString(fmt.Sprintf(`
-package %s
+	for _, c := range tc.Cases {
+		run, err := subtestOf(l, c, func() ([]ast.Stmt, error) {
+			var stmts []ast.Stmt
+			if tf.Timeout > 0 {
+				stmt, err := timeoutStmtOf(l, tf.Timeout)
+				if err != nil {
+					return nil, err
+				}
+				stmts = append(stmts, stmt)
+			}
+			if tf.Receiver != "" {
+				var recv ast.Expr = sel(ident(c.Name), receiverAttrName)
+				if cloneReceiver {
+					recv = lctestCall("Clone", recv)
+				}
+				stmts = append(stmts, at(l.next(), define(recv, "recv")))
+			}
 
-import "testing"
+			callArgs := make([]ast.Expr, 0, len(args))
+			for _, arg := range args {
+				switch {
+				case arg.Decode:
+					pos := l.next()
+					typ, err := exprAt(pos, arg.Type)
+					if err != nil {
+						return nil, err
+					}
+					stmts = append(stmts, at(pos, define(mustDecodeOf(typ, sel(ident(c.Name), inputAttrName, arg.Name)), arg.Var)))
+				case arg.Clone:
+					stmts = append(stmts, at(l.next(), define(lctestCall("Clone", sel(ident(c.Name), inputAttrName, arg.Name)), arg.Var)))
+				}
+				if arg.Var != "" {
+					callArgs = append(callArgs, ident(arg.Var))
+				} else {
+					callArgs = append(callArgs, sel(ident(c.Name), inputAttrName, arg.Name))
+				}
+			}
+			var fun ast.Expr = ident(tf.FuncName)
+			if tf.Receiver != "" {
+				fun = sel(ident("recv"), tf.FuncName)
+			}
+			if len(tf.Results) > 0 {
+				names := make([]string, len(tf.Results))
+				for i, r := range tf.Results {
+					names[i] = r.Name
+				}
+				stmts = append(stmts, at(l.next(), define(call(fun, callArgs...), names...)))
+			} else {
+				stmts = append(stmts, at(l.next(), &ast.ExprStmt{X: call(fun, callArgs...)}))
+			}
 
-`, tcMetadata.pkgName))
-	for _, tc := range tcMetadata.testCases {
-		var params, results []fieldInfo
-		for _, tf := range tfMetadata.testFuncs {
-			if tf.FuncName == tc.FuncName {
-				params = tf.Params
-				results = tf.Results
-				break
-			}
-		}
-		if len(params) == 0 || len(results) == 0 {
-			for _, tf := range tfMetadata.testFuncs {
-				if upperFirst(tf.FuncName) == tc.FuncName {
-					tc.FuncName = tf.FuncName
-					params = tf.Params
-					results = tf.Results
-					break
+			if tc.Checker != "" {
+				stmt, err := checkerStmtsOf(l, tf, tc.Checker, c, outputs)
+				if err != nil {
+					return nil, err
+				}
+				return append(stmts, stmt...), nil
+			}
+			for _, o := range outputs {
+				var (
+					want      ast.Expr = sel(ident(c.Name), outputAttrName, o.Name)
+					shownGot  ast.Expr = ident(o.Var)
+					shownWant ast.Expr = sel(ident(c.Name), outputAttrName, o.Name)
+				)
+				if o.Notation {
+					pos := l.next()
+					typ, err := exprAt(pos, o.Type)
+					if err != nil {
+						return nil, err
+					}
+					stmts = append(stmts, at(pos, define(mustDecodeOf(typ, sel(ident(c.Name), outputAttrName, o.Name)), o.Want)))
+					want = ident(o.Want)
+					shownGot = lctestCall("Encode", ident(o.Var))
 				}
+				format := fmt.Sprintf("%s() %s = %%+v, want %s = %%+v", tf.FuncName, o.Name, o.Name)
+				stmt, err := checkStmtOf(l, o, ident(o.Var), want, func(diff ast.Expr) ast.Stmt {
+					if diff != nil {
+						return errorfStmtOf("Errorf", format+"\n%s", shownGot, shownWant, diff)
+					}
+					return errorfStmtOf("Errorf", format, shownGot, shownWant)
+				})
+				if err != nil {
+					return nil, err
+				}
+				stmts = append(stmts, stmt)
 			}
+			return stmts, nil
+		})
+		if err != nil {
+			return nil, err
 		}
+		decl.Body.List = append(decl.Body.List, run)
+	}
+	decl.Body.Rbrace = l.next()
+	return l.printDecls([]ast.Decl{decl})
+}
 
-		var buf strings.Builder
-		if err := tmpl.Execute(&buf, struct {
-			FuncName string
-			Cases    []testCaseInfo
-			Params   []fieldInfo
-			Results  []fieldInfo
-		}{
-			FuncName: tc.FuncName,
-			Cases:    tc.Cases,
-			Params:   params,
-			Results:  results,
-		}); err != nil {
-			return nil, fmt.Errorf("executing test template: %v", err)
+// checkerStmtsOf lays out the statements passing the outputs of a function
+// to the checker of its cases, e.g.
+//
+//	got := testTwoSumOutput{field0: field0}
+//	if err := checkTwoSum(c.input, got); err != nil {
+//		t.Errorf("twoSum() = %+v: %v", got, err)
+//	}
+func checkerStmtsOf(l *layout, tf testFuncData, checker string, c testCaseInfo, outputs []resultCheck) ([]ast.Stmt, error) {
+	pos := l.next()
+	typ, err := exprAt(pos, c.OutputType)
+	if err != nil {
+		return nil, err
+	}
+	got := &ast.CompositeLit{Type: typ}
+	for _, o := range outputs {
+		var value ast.Expr = ident(o.Var)
+		if o.Notation {
+			value = lctestCall("Encode", value)
 		}
+		got.Elts = append(got.Elts, &ast.KeyValueExpr{Key: ident(o.Name), Value: value})
+	}
+	assign := at(pos, define(got, "got"))
 
-		formattedCode, err := format.Source([]byte(buf.String()))
+	pos = l.next()
+	check := &ast.IfStmt{
+		If:   pos,
+		Init: define(call(ident(checker), sel(ident(c.Name), inputAttrName), ident("got")), "err"),
+		Cond: &ast.BinaryExpr{X: ident("err"), Op: token.NEQ, Y: ident("nil")},
+		Body: l.block(pos, at(l.next(), errorfStmtOf("Errorf", tf.FuncName+"() = %+v: %v", ident("got"), ident("err")))),
+	}
+	return []ast.Stmt{assign, check}, nil
+}
+
+// checkStmtOf lays out the statement comparing the actual value got with the
+// expected value want as the check describes, e.g.
+//
+//	if !lctest.Equal(got, want) {
+//		...
+//	}
+//
+// Parameters:
+//   - l: The layout
+//   - check: How the values are compared
+//   - got: The actual value
+//   - want: The expected value
+//   - report: Returns the statement reporting a mismatch, given the diff
+//     of the values for unordered comparisons, or else nil
+//
+// Returns:
+//   - *ast.IfStmt: The statement
+//   - error: An error if the options of the comparison are malformed
+func checkStmtOf(l *layout, check resultCheck, got, want ast.Expr, report func(diff ast.Expr) ast.Stmt) (*ast.IfStmt, error) {
+	args := []ast.Expr{got, want}
+	for _, option := range check.Options {
+		expr, err := exprAt(token.NoPos, option)
 		if err != nil {
-			return nil, fmt.Errorf("formatting test template: %v", err)
+			return nil, err
 		}
+		args = append(args, expr)
+	}
 
-		result.Write(formattedCode)
-		result.WriteString("\n")
+	pos := l.next()
+	stmt := &ast.IfStmt{If: pos}
+	var diff ast.Expr
+	switch {
+	case check.IsUnordered():
+		stmt.Init = define(lctestCall("Diff", args...), "diff")
+		stmt.Cond = &ast.BinaryExpr{X: ident("diff"), Op: token.NEQ, Y: str("")}
+		diff = ident("diff")
+	case check.IsTolerant():
+		stmt.Cond = &ast.UnaryExpr{Op: token.NOT, X: lctestCall("Equal", args...)}
+	case check.IsDeep():
+		stmt.Cond = &ast.UnaryExpr{Op: token.NOT, X: lctestCall("Equal", got, want)}
+	default:
+		stmt.Cond = &ast.BinaryExpr{X: got, Op: token.NEQ, Y: want}
+	}
+	stmt.Body = l.block(pos, at(l.next(), report(diff)))
+	return stmt, nil
+}
+
+// subtestOf lays out the statement running a test case as a subtest named
+// after its description, whose statements are laid out by body.
+func subtestOf(l *layout, c testCaseInfo, body func() ([]ast.Stmt, error)) (ast.Stmt, error) {
+	pos := l.next()
+	block := &ast.BlockStmt{Lbrace: pos}
+	stmts, err := body()
+	if err != nil {
+		return nil, err
+	}
+	block.List = stmts
+	block.Rbrace = l.next()
+	run := call(sel(ident("t"), "Run"), str(c.Desc), &ast.FuncLit{Type: testFuncTypeAt(token.NoPos), Body: block})
+	return at(pos, &ast.ExprStmt{X: run}), nil
+}
+
+// timeoutStmtOf lays out the statement failing a test case that takes longer
+// than timeout, e.g. "defer lctest.Timeout(t, 2*time.Second)()".
+func timeoutStmtOf(l *layout, timeout time.Duration) (ast.Stmt, error) {
+	pos := l.next()
+	d, err := exprAt(pos, timeoutExprOf(timeout))
+	if err != nil {
+		return nil, err
 	}
+	return &ast.DeferStmt{Defer: pos, Call: call(lctestCall("Timeout", ident("t"), d))}, nil
+}
+
+// testFuncTypeAt returns the type of test functions, func(t *testing.T),
+// starting at pos.
+func testFuncTypeAt(pos token.Pos) *ast.FuncType {
+	return &ast.FuncType{Func: pos, Params: &ast.FieldList{List: []*ast.Field{
+		{Names: []*ast.Ident{ident("t")}, Type: &ast.StarExpr{X: sel(ident("testing"), "T")}},
+	}}}
+}
+
+// mustDecodeOf returns the expression decoding a value in LeetCode notation,
+// lctest.MustDecode[typ](t, value).
+func mustDecodeOf(typ, value ast.Expr) ast.Expr {
+	return call(instantiate(sel(ident("lctest"), "MustDecode"), typ), ident("t"), value)
+}
+
+// errorfStmtOf returns the statement reporting a failure with a method of
+// t such as Errorf, e.g. `t.Errorf("twoSum() = %+v", got)`.
+func errorfStmtOf(method, format string, args ...ast.Expr) ast.Stmt {
+	return &ast.ExprStmt{X: call(sel(ident("t"), method), append([]ast.Expr{str(format)}, args...)...)}
+}
 
-	return []byte(result.String()), nil
+// IsGeneratedTestFile reports whether a test file was generated and can be
+// regenerated without losing hand-written code: it carries the "Code
+// generated ... DO NOT EDIT." header, or it was generated by a version that
+// did not add the header yet.
+//
+// Parameters:
+//   - content: The content of the test file
+//
+// Returns:
+//   - bool: Whether the test file was generated
+func IsGeneratedTestFile(content []byte) bool {
+	f, err := parser.ParseFile(token.NewFileSet(), "", content, parser.PackageClauseOnly|parser.ParseComments)
+	if err == nil && ast.IsGenerated(f) {
+		return true
+	}
+	return bytes.HasPrefix(bytes.TrimLeft(content, "\n"), []byte("package ")) && bytes.Contains(content, []byte("\n"+legacyTestComment))
+}
+
+// findTestFunc looks up the test function that the test cases named funcName
+// belong to. The name is matched exactly against plain functions first, and
+// then against the case names of all functions, which is how test case types
+// are named.
+//
+// Parameters:
+//   - testFuncs: The test functions extracted from the source
+//   - funcName: The function name derived from the test case type
+//
+// Returns:
+//   - *testFuncData: The matching test function, or nil if there is none
+func findTestFunc(testFuncs []testFuncData, funcName string) *testFuncData {
+	for i, tf := range testFuncs {
+		if tf.Receiver == "" && tf.FuncName == funcName {
+			return &testFuncs[i]
+		}
+	}
+	for i, tf := range testFuncs {
+		if tf.CaseName() == funcName {
+			return &testFuncs[i]
+		}
+	}
+	return nil
+}
+
+// findDesign looks up the design problem that the test cases named typeName
+// belong to, matching the type name like findTestFunc.
+func findDesign(designs []designData, typeName string) *designData {
+	for i, d := range designs {
+		if d.TypeName == typeName {
+			return &designs[i]
+		}
+	}
+	for i, d := range designs {
+		if upperFirst(d.TypeName) == typeName {
+			return &designs[i]
+		}
+	}
+	return nil
+}
+
+// argInfo describes how a generated test passes a parameter to the function.
+type argInfo struct {
+	Name string
+	Type string
+	// Var is the name of the local variable passed instead of the test case
+	// input field, or empty if the field is passed directly.
+	Var string
+	// Decode is set if the local variable is decoded from LeetCode notation.
+	Decode bool
+	// Clone is set if the local variable is a deep copy of the input field,
+	// which the function modifies in place.
+	Clone bool
+
+	imports []string
+}
+
+// argsOf works out how the generated test passes each parameter of the test
+// function. Parameters modified in place are copied into variables prefixed
+// with "got", and other parameters spelled in LeetCode notation are decoded
+// into variables prefixed with "in".
+func argsOf(tf testFuncData) []argInfo {
+	mutated := make(map[string]bool)
+	for _, name := range tf.InPlace {
+		mutated[name] = true
+	}
+
+	args := make([]argInfo, 0, len(tf.Params))
+	for _, p := range tf.Params {
+		arg := argInfo{Name: p.Name, Type: p.Type, imports: p.imports}
+		switch {
+		case mutated[p.Name]:
+			arg.Var = mutatedVarPrefix + upperFirst(p.Name)
+			arg.Decode = p.Notation()
+			arg.Clone = !arg.Decode
+		case p.Notation():
+			arg.Var = decodedVarPrefix + upperFirst(p.Name)
+			arg.Decode = true
+		}
+		args = append(args, arg)
+	}
+	return args
 }
@@ -0,0 +1,114 @@
+package codegen
+
+import (
+	"bytes"
+	"go/format"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIsGeneratedTestFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected bool
+	}{
+		{"header", generatedHeader + "\n\npackage p\n", true},
+		{"header of another generator", "// Code generated by stringer. DO NOT EDIT.\n\npackage p\n", true},
+		{"legacy", "\npackage p\n\nimport \"testing\"\n\n" + legacyTestComment + "twoSum\nfunc TestTwoSum(t *testing.T) {}\n", true},
+		{"hand-written", "package p\n\nimport \"testing\"\n\nfunc BenchmarkTwoSum(b *testing.B) {}\n", false},
+		{"header after package clause", "package p\n\n" + generatedHeader + "\n", false},
+		{"legacy comment after other comments", "// Copyright\npackage p\n\n" + legacyTestComment + "twoSum\n", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := IsGeneratedTestFile([]byte(tt.content)); result != tt.expected {
+				t.Errorf("IsGeneratedTestFile() = %v; expected %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGenerateTestTemplates(t *testing.T) {
+	const src = `package p
+
+type Solution struct{ memo map[int]int }
+
+//leetcode:test timeout=500ms
+func (s Solution) twoSum(nums []int, target int) []int { return nil }
+
+//leetcode:test
+type Counter struct{ n int }
+
+func NewCounter() *Counter      { return &Counter{} }
+func (c *Counter) Add(k int) int { c.n += k; return c.n }
+`
+	dir := t.TempDir()
+	srcFile := filepath.Join(dir, "sol.go")
+	if err := os.WriteFile(srcFile, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	templates, _, err := defaultGenerator.GenerateTestCaseTemplates(srcFile, []byte(src))
+	if err != nil {
+		t.Fatalf("GenerateTestCaseTemplates() error = %v", err)
+	}
+	if formatted, err := format.Source(templates); err != nil || !bytes.Equal(formatted, templates) {
+		t.Errorf("GenerateTestCaseTemplates() is not formatted:\n%s", templates)
+	}
+	for _, expected := range []string{
+		"// Auto-generated test case template for Solution.twoSum (sol.go:6)\n",
+		"}\n\n// Auto-generated test case template for Counter (sol.go:9)\n",
+	} {
+		if !strings.Contains(string(templates), expected) {
+			t.Errorf("GenerateTestCaseTemplates() = %s; expected it to contain %q", templates, expected)
+		}
+	}
+
+	testCases := string(templates) + "\n" + `var (
+	quoted = testSolutionTwoSumCase{name: "a ` + "`raw`" + ` \"quoted\" case", output: testSolutionTwoSumOutput{field0: []int{}}}
+	ops    = testCounterCase{input: testCounterInput{operations: "[\"Counter\",\"add\"]", arguments: "[[],[1]]"}, output: testCounterOutput{expected: "[null,1]"}}
+)
+`
+	tests, _, err := defaultGenerator.GenerateTestTemplates(srcFile, []byte(src), filepath.Join(dir, "sol_testcase.go"), []byte(testCases))
+	if err != nil {
+		t.Fatalf("GenerateTestTemplates() error = %v", err)
+	}
+	if formatted, err := format.Source(tests); err != nil || !bytes.Equal(formatted, tests) {
+		t.Errorf("GenerateTestTemplates() is not formatted:\n%s", tests)
+	}
+	for _, expected := range []string{
+		"// Auto-generated test for Solution.twoSum (sol.go:6)\nfunc TestSolutionTwoSum(t *testing.T) {\n",
+		"\tt.Run(\"a `raw` \\\"quoted\\\" case\", func(t *testing.T) {\n",
+		"\t\tdefer lctest.Timeout(t, 500*time.Millisecond)()\n",
+		"}\n\n// Auto-generated test for Counter (sol.go:9)\nfunc TestCounter(t *testing.T) {\n",
+		"\t\t\tcase \"add\":\n\t\t\t\top.CheckArgs(t, 1)\n",
+		"\t\tif !lctest.Equal(field0, quoted.output.field0) {\n",
+	} {
+		if !strings.Contains(string(tests), expected) {
+			t.Errorf("GenerateTestTemplates() = %s; expected it to contain %q", tests, expected)
+		}
+	}
+	// Nil and empty slices are equal, as LeetCode spells both as []
+	if bytes.Contains(tests, []byte(`"reflect"`)) {
+		t.Errorf("GenerateTestTemplates() = %s; expected no reflect import", tests)
+	}
+}
+
+func TestGenerateTemplatesOfUnnamedFiles(t *testing.T) {
+	const src = "package p\n\n//leetcode:test\nfunc add(a, b int) int { return a + b }\n"
+	templates, err := GenerateTestCaseTemplates([]byte(src))
+	if err != nil {
+		t.Fatalf("GenerateTestCaseTemplates() error = %v", err)
+	}
+	testCases := string(templates) + "\nvar sum = testAddCase{input: testAddInput{a: 1, b: 2}, output: testAddOutput{field0: 3}}\n"
+	tests, err := GenerateTestTemplates([]byte(src), []byte(testCases))
+	if err != nil {
+		t.Fatalf("GenerateTestTemplates() error = %v", err)
+	}
+	if expected := "func TestAdd(t *testing.T) {\n"; !strings.Contains(string(tests), expected) {
+		t.Errorf("GenerateTestTemplates() = %s; expected it to contain %q", tests, expected)
+	}
+}
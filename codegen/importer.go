@@ -0,0 +1,414 @@
+package codegen
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// packageCache holds the packages type-checked by the importers of a
+// Generator, so that extractions share them instead of type-checking the
+// standard library and the module packages again. Packages are type-checked
+// into a file set of their own, since only their types are used.
+type packageCache struct {
+	mu   sync.Mutex
+	fset *token.FileSet
+	// std type-checks standard library packages from the GOROOT sources and
+	// keeps them.
+	std types.ImporterFrom
+	// modules maps module packages to their last type-checked version.
+	modules map[packageKey]cachedPackage
+}
+
+// packageKey identifies a module package by its import path and directory.
+type packageKey struct {
+	path string
+	dir  string
+}
+
+// cachedPackage is a module package type-checked from source files whose
+// names and contents hash to sum.
+type cachedPackage struct {
+	pkg *types.Package
+	sum [sha256.Size]byte
+}
+
+// newPackageCache creates an empty package cache.
+func newPackageCache() *packageCache {
+	fset := token.NewFileSet()
+	return &packageCache{
+		fset:    fset,
+		std:     importer.ForCompiler(fset, "source", nil).(types.ImporterFrom),
+		modules: make(map[packageKey]cachedPackage),
+	}
+}
+
+// importStd imports a standard library package.
+func (c *packageCache) importStd(path, dir string, mode types.ImportMode) (*types.Package, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.std.ImportFrom(path, dir, mode)
+}
+
+// module returns the cached version of a module package, if any.
+func (c *packageCache) module(key packageKey) (cachedPackage, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cp, ok := c.modules[key]
+	return cp, ok
+}
+
+// setModule caches the version of a module package.
+func (c *packageCache) setModule(key packageKey, cp cachedPackage) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.modules[key] = cp
+}
+
+// localImporter resolves imports from local data only, without consulting
+// the network or the module cache: standard library packages are type-checked
+// from the GOROOT sources, and packages of the module enclosing the source
+// file from the module directory. Packages are shared through the cache of
+// the Generator, module packages as long as their files are unchanged.
+type localImporter struct {
+	fsys  fileSystem
+	cache *packageCache
+	// modPath and modDir locate the enclosing module, if any.
+	modPath string
+	modDir  string
+	pkgs    map[string]*types.Package
+	// importing holds the module packages being type-checked, each importing
+	// the next, to catch import cycles.
+	importing []string
+}
+
+// newLocalImporter creates an importer for source files in srcDir.
+//
+// Parameters:
+//   - cache: The cache of the packages shared with other importers
+//   - fsys: The file system holding the module
+//   - srcDir: The directory of the source files, used to find the enclosing
+//     module; imports of module packages fail if it is empty
+//
+// Returns:
+//   - *localImporter: The importer
+func newLocalImporter(cache *packageCache, fsys fileSystem, srcDir string) *localImporter {
+	imp := &localImporter{
+		fsys:  fsys,
+		cache: cache,
+		pkgs:  make(map[string]*types.Package),
+	}
+	if srcDir != "" {
+		imp.modPath, imp.modDir = findModule(fsys, srcDir)
+	}
+	return imp
+}
+
+// Import implements types.Importer.
+func (imp *localImporter) Import(path string) (*types.Package, error) {
+	return imp.ImportFrom(path, "", 0)
+}
+
+// ImportFrom implements types.ImporterFrom.
+func (imp *localImporter) ImportFrom(path, dir string, mode types.ImportMode) (*types.Package, error) {
+	if pkg, ok := imp.pkgs[path]; ok {
+		return pkg, nil
+	}
+
+	var (
+		pkg *types.Package
+		err error
+	)
+	switch {
+	case imp.inModule(path):
+		for i, importing := range imp.importing {
+			if importing == path {
+				return nil, fmt.Errorf("import cycle: %s", strings.Join(append(imp.importing[i:], path), " -> "))
+			}
+		}
+		imp.importing = append(imp.importing, path)
+		rel := strings.TrimPrefix(strings.TrimPrefix(path, imp.modPath), "/")
+		pkg, err = imp.importDir(path, imp.fsys.join(imp.modDir, imp.fsys.fromSlash(rel)))
+		imp.importing = imp.importing[:len(imp.importing)-1]
+	case isStdImportPath(path):
+		pkg, err = imp.cache.importStd(path, dir, mode)
+	default:
+		return nil, fmt.Errorf("cannot resolve import %q: only standard library and module packages are supported", path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	imp.pkgs[path] = pkg
+	return pkg, nil
+}
+
+// inModule reports whether path is the import path of a package of the
+// enclosing module.
+func (imp *localImporter) inModule(path string) bool {
+	return imp.modPath != "" && (path == imp.modPath || strings.HasPrefix(path, imp.modPath+"/"))
+}
+
+// importDir type-checks the package in dir, which is imported as path,
+// unless the cached version is still current: its files are unchanged and so
+// are the module packages it imports.
+func (imp *localImporter) importDir(path, dir string) (*types.Package, error) {
+	bp, err := imp.fsys.buildContext().ImportDir(dir, 0)
+	if err != nil {
+		return nil, fmt.Errorf("importing %q: %v", path, err)
+	}
+
+	contents := make([][]byte, 0, len(bp.GoFiles))
+	h := sha256.New()
+	for _, name := range bp.GoFiles {
+		content, err := imp.fsys.readFile(imp.fsys.join(dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("importing %q: %v", path, err)
+		}
+		contents = append(contents, content)
+		fmt.Fprintf(h, "%s\x00%d\x00", name, len(content))
+		h.Write(content)
+	}
+	key := packageKey{path: path, dir: dir}
+	var sum [sha256.Size]byte
+	h.Sum(sum[:0])
+	if cp, ok := imp.cache.module(key); ok && cp.sum == sum && imp.importsCurrent(cp.pkg) {
+		return cp.pkg, nil
+	}
+
+	files := make([]*ast.File, 0, len(bp.GoFiles))
+	for i, name := range bp.GoFiles {
+		f, err := parser.ParseFile(imp.cache.fset, imp.fsys.join(dir, name), contents[i], 0)
+		if err != nil {
+			return nil, fmt.Errorf("importing %q: %v", path, err)
+		}
+		files = append(files, f)
+	}
+
+	conf := types.Config{Importer: imp}
+	pkg, err := conf.Check(path, imp.cache.fset, files, nil)
+	if err != nil {
+		return nil, fmt.Errorf("importing %q: %v", path, err)
+	}
+	imp.cache.setModule(key, cachedPackage{pkg: pkg, sum: sum})
+	return pkg, nil
+}
+
+// importsCurrent reports whether the module packages imported by a cached
+// package are still the ones the importer resolves.
+func (imp *localImporter) importsCurrent(pkg *types.Package) bool {
+	for _, dep := range pkg.Imports() {
+		if !imp.inModule(dep.Path()) {
+			continue
+		}
+		if current, err := imp.ImportFrom(dep.Path(), "", 0); err != nil || current != dep {
+			return false
+		}
+	}
+	return true
+}
+
+// findModule walks up from dir to the nearest go.mod file and returns the
+// module path it declares along with its directory. It returns empty strings
+// if there is no such file.
+func findModule(fsys fileSystem, dir string) (modPath, modDir string) {
+	dir, err := fsys.abs(dir)
+	if err != nil {
+		return "", ""
+	}
+	for {
+		if content, err := fsys.readFile(fsys.join(dir, "go.mod")); err == nil {
+			return modulePathOf(content), dir
+		}
+		parent := fsys.dir(dir)
+		if parent == dir {
+			return "", ""
+		}
+		dir = parent
+	}
+}
+
+// modulePathOf extracts the module path from the content of a go.mod file.
+//
+// Example:
+//
+//	input: "module example.com/leetcode\n\ngo 1.23\n"
+//	output: "example.com/leetcode"
+func modulePathOf(content []byte) string {
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		rest, ok := strings.CutPrefix(line, "module")
+		if !ok || rest == "" || (rest[0] != ' ' && rest[0] != '\t') {
+			continue
+		}
+		rest, _, _ = strings.Cut(rest, "//")
+		rest = strings.TrimSpace(rest)
+		if unquoted, err := strconv.Unquote(rest); err == nil {
+			return unquoted
+		}
+		return rest
+	}
+	return ""
+}
+
+// qualifiedTypeString renders t as Go source in the package being checked,
+// whose path is empty: types of other packages are qualified with their
+// package names, and the paths of these packages are returned for the import
+// declarations of generated files.
+func qualifiedTypeString(t types.Type) (string, []string) {
+	return qualifier(nil).typeString(t)
+}
+
+// qualifier maps the import paths of packages sharing their name with other
+// packages referred to by the same generated files to the aliases they are
+// imported with. Other packages are qualified with their package names.
+type qualifier map[string]string
+
+// qualifierOf works out the aliases of the packages referred to by types
+// whose names collide. Of the packages sharing a name, the one with the
+// smallest import path keeps it, and the others are named after the elements
+// of their paths as well.
+//
+// Example:
+//
+//	input: types of "crypto/rand" and "math/rand"
+//	output: qualifier{"math/rand": "mathrand"}
+func qualifierOf(ts []types.Type) qualifier {
+	pathsByName := make(map[string][]string)
+	seen := make(map[string]bool)
+	for _, t := range ts {
+		if t == nil {
+			continue
+		}
+		types.TypeString(t, func(pkg *types.Package) string {
+			if pkg.Path() != "" && !seen[pkg.Path()] {
+				seen[pkg.Path()] = true
+				pathsByName[pkg.Name()] = append(pathsByName[pkg.Name()], pkg.Path())
+			}
+			return ""
+		})
+	}
+
+	var q qualifier
+	for name, paths := range pathsByName {
+		if len(paths) < 2 {
+			continue
+		}
+		sort.Strings(paths)
+		for _, path := range paths[1:] {
+			if q == nil {
+				q = make(qualifier)
+			}
+			q[path] = aliasOf(path, name, func(alias string) bool {
+				if len(pathsByName[alias]) > 0 {
+					return true
+				}
+				for _, taken := range q {
+					if taken == alias {
+						return true
+					}
+				}
+				return false
+			})
+		}
+	}
+	return q
+}
+
+// aliasOf makes an alias for the package named name at an import path that
+// is not taken yet, by prefixing the name with the elements of the path that
+// precede it, or else by appending a number to the name.
+//
+// Example:
+//
+//	input: "math/rand", "rand"
+//	output: "mathrand"
+func aliasOf(importPath, name string, taken func(string) bool) string {
+	elems := strings.Split(importPath, "/")
+	alias := name
+	for i := len(elems) - 2; i >= 0; i-- {
+		alias = strings.Map(func(r rune) rune {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+				return r
+			}
+			return -1
+		}, elems[i]) + alias
+		if !unicode.IsDigit(rune(alias[0])) && !taken(alias) {
+			return alias
+		}
+	}
+	for n := 2; ; n++ {
+		if numbered := name + strconv.Itoa(n); !taken(numbered) {
+			return numbered
+		}
+	}
+}
+
+// typeString renders t as Go source in the package being checked, like
+// qualifiedTypeString, qualifying the types of aliased packages with their
+// aliases. Aliased packages are returned as "alias path", see importSpecOf.
+func (q qualifier) typeString(t types.Type) (string, []string) {
+	var imports []string
+	s := types.TypeString(t, func(pkg *types.Package) string {
+		if pkg.Path() == "" {
+			return ""
+		}
+		if alias, ok := q[pkg.Path()]; ok {
+			imports = append(imports, alias+" "+pkg.Path())
+			return alias
+		}
+		imports = append(imports, pkg.Path())
+		return pkg.Name()
+	})
+	return s, uniqueSorted(imports)
+}
+
+// importSpecOf splits an import of a generated file into the name it is
+// imported with, which is empty unless the package is aliased, and its path.
+//
+// Example:
+//
+//	input: "mathrand math/rand"
+//	output: "mathrand", "math/rand"
+func importSpecOf(imp string) (name, path string) {
+	if name, path, ok := strings.Cut(imp, " "); ok {
+		return name, path
+	}
+	return "", imp
+}
+
+// importsOf collects the import paths needed to spell the types of fields.
+func importsOf(fields ...fieldInfo) []string {
+	var imports []string
+	for _, f := range fields {
+		imports = append(imports, f.imports...)
+	}
+	return uniqueSorted(imports)
+}
+
+// uniqueSorted sorts strings and removes duplicates.
+func uniqueSorted(ss []string) []string {
+	if len(ss) == 0 {
+		return nil
+	}
+	ss = append([]string(nil), ss...)
+	sort.Strings(ss)
+	unique := ss[:1]
+	for _, s := range ss[1:] {
+		if s != unique[len(unique)-1] {
+			unique = append(unique, s)
+		}
+	}
+	return unique
+}
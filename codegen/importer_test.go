@@ -0,0 +1,238 @@
+package codegen
+
+import (
+	"go/types"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestModulePathOf(t *testing.T) {
+	tests := []struct {
+		content  string
+		expected string
+	}{
+		{"module example.com/leetcode\n\ngo 1.23\n", "example.com/leetcode"},
+		{"// comment\nmodule \"example.com/quoted\"\n", "example.com/quoted"},
+		{"module leetcode // trailing comment\n", "leetcode"},
+		{"modules example.com/x\n", ""},
+		{"go 1.23\n", ""},
+	}
+
+	for _, test := range tests {
+		if result := modulePathOf([]byte(test.content)); result != test.expected {
+			t.Errorf("modulePathOf(%q) = %q; expected %q", test.content, result, test.expected)
+		}
+	}
+}
+
+func TestQualifierOf(t *testing.T) {
+	named := func(path, name string) types.Type {
+		pkg := types.NewPackage(path, name)
+		return types.NewNamed(types.NewTypeName(0, pkg, "T", nil), types.NewStruct(nil, nil), nil)
+	}
+	tests := []struct {
+		name     string
+		types    []types.Type
+		expected []string
+	}{
+		{"distinct names", []types.Type{named("sort", "sort"), named("example.com/geom", "geom")}, []string{"sort.T", "geom.T"}},
+		{"same package", []types.Type{named("sort", "sort"), types.NewSlice(named("sort", "sort"))}, []string{"sort.T", "[]sort.T"}},
+		{"same name", []types.Type{named("math/rand", "rand"), types.NewMap(named("crypto/rand", "rand"), named("example.com/rand", "rand"))},
+			[]string{"mathrand.T", "map[rand.T]examplecomrand.T"}},
+		{"alias taken", []types.Type{named("a/rand", "rand"), named("b/rand", "rand"), named("x/brand", "brand")},
+			[]string{"rand.T", "rand2.T", "brand.T"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			q := qualifierOf(test.types)
+			var result []string
+			for _, typ := range test.types {
+				s, _ := q.typeString(typ)
+				result = append(result, s)
+			}
+			if !reflect.DeepEqual(result, test.expected) {
+				t.Errorf("typeString() = %v; expected %v", result, test.expected)
+			}
+		})
+	}
+
+	q := qualifierOf([]types.Type{types.NewMap(named("crypto/rand", "rand"), named("math/rand", "rand"))})
+	if _, imports := q.typeString(named("math/rand", "rand")); !reflect.DeepEqual(imports, []string{"mathrand math/rand"}) {
+		t.Errorf("typeString() imports = %v; expected the aliased import", imports)
+	}
+}
+
+func TestExtractTestFuncsImports(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":         "module example.com/leetcode\n\ngo 1.23\n",
+		"geom/geom.go":   "package geom\n\ntype Point struct{ X, Y int }\n",
+		"graph/graph.go": "package graph\n\nimport \"example.com/leetcode/geom\"\n\ntype Path []geom.Point\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	const src = `package solution
+
+import (
+	"container/heap"
+	"sort"
+	"strings"
+
+	"example.com/leetcode/geom"
+	"example.com/leetcode/graph"
+)
+
+var _ heap.Interface
+
+//go:generate leetcode-gen-test
+func f(words sort.StringSlice, path graph.Path, sep string) (map[string][]geom.Point, error) {
+	return nil, nil
+}
+
+func g() string { return strings.Repeat("a", 2) }
+`
+	tfMetadata, err := defaultGenerator.extractTestFuncs(filepath.Join(dir, "solution", "sol.go"), []byte(src))
+	if err != nil {
+		t.Fatalf("extractTestFuncs() error = %v", err)
+	}
+	tf := tfMetadata.testFuncs[0]
+
+	expectedTypes := []string{"sort.StringSlice", "graph.Path", "string", "map[string][]geom.Point", "error"}
+	var resultTypes []string
+	for _, field := range append(tf.Params, tf.Results...) {
+		resultTypes = append(resultTypes, field.Type)
+	}
+	if !reflect.DeepEqual(resultTypes, expectedTypes) {
+		t.Errorf("field types = %v; expected %v", resultTypes, expectedTypes)
+	}
+
+	expectedImports := []string{"example.com/leetcode/geom", "example.com/leetcode/graph", "sort"}
+	if result := importsOf(append(tf.Params, tf.Results...)...); !reflect.DeepEqual(result, expectedImports) {
+		t.Errorf("importsOf() = %v; expected %v", result, expectedImports)
+	}
+
+	if _, err := defaultGenerator.extractTestFuncs("", []byte("package p\n\nimport \"example.com/elsewhere\"\n\n//go:generate leetcode-gen-test\nfunc f() {}\n")); err == nil {
+		t.Errorf("extractTestFuncs() with an unresolvable import succeeded; expected an error")
+	}
+}
+
+func TestPackageCache(t *testing.T) {
+	fsys := fstest.MapFS{
+		"go.mod":       {Data: []byte("module example.com/leetcode\n\ngo 1.23\n")},
+		"geom/geom.go": {Data: []byte("package geom\n\ntype Point struct{ X, Y int }\n")},
+		"path/path.go": {Data: []byte("package path\n\nimport \"example.com/leetcode/geom\"\n\ntype Path []geom.Point\n")},
+	}
+	g, err := NewGenerator(Options{FS: fsys})
+	if err != nil {
+		t.Fatalf("NewGenerator() error = %v", err)
+	}
+	const src = `package solution
+
+import (
+	"strings"
+
+	"example.com/leetcode/path"
+)
+
+//leetcode:test
+func f(p path.Path) string { return strings.Repeat("a", len(p)) }
+`
+	imports := func() map[string]*types.Package {
+		t.Helper()
+		imp := newLocalImporter(g.packages, g.fsys, "solution")
+		pkgs := make(map[string]*types.Package)
+		for _, path := range []string{"strings", "example.com/leetcode/geom", "example.com/leetcode/path"} {
+			pkg, err := imp.Import(path)
+			if err != nil {
+				t.Fatalf("Import(%s) error = %v", path, err)
+			}
+			pkgs[path] = pkg
+		}
+		return pkgs
+	}
+
+	if _, err := g.extractTestFuncs("solution/sol.go", []byte(src)); err != nil {
+		t.Fatalf("extractTestFuncs() error = %v", err)
+	}
+	first := imports()
+	second := imports()
+	for path, pkg := range first {
+		if second[path] != pkg {
+			t.Errorf("Import(%s) type-checked the package again; expected the cached one", path)
+		}
+	}
+
+	// Editing a module package type-checks it again along with the module
+	// packages importing it
+	fsys["geom/geom.go"] = &fstest.MapFile{Data: []byte("package geom\n\ntype Point struct{ X, Y, Z int }\n")}
+	third := imports()
+	if third["strings"] != first["strings"] {
+		t.Errorf("Import(strings) type-checked the package again; expected the cached one")
+	}
+	for _, path := range []string{"example.com/leetcode/geom", "example.com/leetcode/path"} {
+		if third[path] == first[path] {
+			t.Errorf("Import(%s) returned the stale package; expected it to be type-checked again", path)
+		}
+	}
+	if point := third["example.com/leetcode/geom"].Scope().Lookup("Point").Type().Underlying().(*types.Struct); point.NumFields() != 3 {
+		t.Errorf("Point has %d fields after the edit; expected 3", point.NumFields())
+	}
+}
+
+func TestGenerateTestCaseTemplatesAliasedImports(t *testing.T) {
+	const src = `package p
+
+import (
+	htemplate "html/template"
+	"text/template"
+)
+
+//leetcode:test
+func same(a *template.Template, b *htemplate.Template) bool { return true }
+`
+	content, err := GenerateTestCaseTemplates([]byte(src))
+	if err != nil {
+		t.Fatalf("GenerateTestCaseTemplates() error = %v", err)
+	}
+	for _, expected := range []string{"import (\n\t\"html/template\"\n\ttexttemplate \"text/template\"\n)", "a *texttemplate.Template\n\tb *template.Template\n"} {
+		if !strings.Contains(string(content), expected) {
+			t.Errorf("GenerateTestCaseTemplates() = %s; expected it to contain %q", content, expected)
+		}
+	}
+}
+
+func TestImportCycle(t *testing.T) {
+	fsys := fstest.MapFS{
+		"go.mod": {Data: []byte("module example.com/leetcode\n\ngo 1.23\n")},
+		"a/a.go": {Data: []byte("package a\n\nimport \"example.com/leetcode/b\"\n\ntype A struct{ B *b.B }\n")},
+		"b/b.go": {Data: []byte("package b\n\nimport \"example.com/leetcode/a\"\n\ntype B struct{ A *a.A }\n")},
+	}
+	g, err := NewGenerator(Options{FS: fsys})
+	if err != nil {
+		t.Fatalf("NewGenerator() error = %v", err)
+	}
+	const src = `package solution
+
+import "example.com/leetcode/a"
+
+//leetcode:test
+func f(x *a.A) bool { return x != nil }
+`
+	_, err = g.extractTestFuncs("solution/sol.go", []byte(src))
+	if err == nil || !strings.Contains(err.Error(), "import cycle: example.com/leetcode/a -> example.com/leetcode/b -> example.com/leetcode/a") {
+		t.Errorf("extractTestFuncs() error = %v; expected an import cycle", err)
+	}
+}
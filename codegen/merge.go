@@ -0,0 +1,151 @@
+package codegen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"strings"
+)
+
+// MergeTestCaseTemplates adds the test case templates of tagged functions and
+// types that an existing test case file does not cover yet, such as a
+// follow-up variant added to the solution. A function is covered if any of
+// its test<Func>Input, test<Func>Output or test<Func>Case types is declared.
+// The templates are appended and the imports they need are added, while the
+// rest of the file, including its cases, comments and formatting, is kept
+// as it is.
+//
+// Parameters:
+//   - srcFile: The path of the source file, used to resolve its imports
+//   - srcContent: The content of the source file
+//   - testCaseFile: The path of the test case file
+//   - testCaseContent: The content of the test case file
+//
+// Returns:
+//   - []byte: The merged test case file, the same content if nothing is missing
+//   - []string: The names of the functions and types whose templates were added
+//   - Diagnostics: Warnings about type errors in the source package that did
+//     not prevent the merge
+//   - error: An error if either file cannot be processed
+func (g *Generator) MergeTestCaseTemplates(srcFile string, srcContent []byte, testCaseFile string, testCaseContent []byte) ([]byte, []string, Diagnostics, error) {
+	tfMetadata, err := g.extractTestFuncs(srcFile, srcContent)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("extracting test function: %w", err)
+	}
+	chunks, err := g.testCaseChunksOf(tfMetadata)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	pkgName, warnings := tfMetadata.pkgName, tfMetadata.warnings
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, testCaseFile, testCaseContent, parser.ParseComments)
+	if err != nil {
+		return nil, nil, nil, DiagnosticsOf(err)
+	}
+	if f.Name.Name != pkgName {
+		return nil, nil, nil, errorAt(fset, f.Name.Pos(), "package name mismatch: %s != %s", f.Name.Name, pkgName)
+	}
+	declared := schemaTypesOf(f)
+
+	var (
+		added   []string
+		imports []string
+		body    strings.Builder
+	)
+	for _, chunk := range chunks {
+		if declared[g.naming.TestCaseInputTypeNameOf(chunk.caseName)] != "" ||
+			declared[g.naming.TestCaseOutputTypeNameOf(chunk.caseName)] != "" ||
+			declared[g.naming.TestCaseTypeNameOf(chunk.caseName)] != "" {
+			continue
+		}
+		added = append(added, chunk.caseName)
+		imports = append(imports, chunk.imports...)
+		body.WriteString("\n" + strings.TrimRight(string(chunk.code), "\n") + "\n")
+	}
+	if len(added) == 0 {
+		return testCaseContent, nil, warnings, nil
+	}
+
+	merged := addImports(fset, f, string(testCaseContent), uniqueSorted(imports))
+	if !strings.HasSuffix(merged, "\n") {
+		merged += "\n"
+	}
+	return []byte(merged + body.String()), added, warnings, nil
+}
+
+// MergeTestCaseTemplates adds the missing test case templates of a source
+// file to its test case file with the default options, see
+// Generator.MergeTestCaseTemplates.
+func MergeTestCaseTemplates(srcFile string, srcContent []byte, testCaseFile string, testCaseContent []byte) ([]byte, []string, Diagnostics, error) {
+	return defaultGenerator.MergeTestCaseTemplates(srcFile, srcContent, testCaseFile, testCaseContent)
+}
+
+// addImports adds the imports that a file does not have yet, by
+// editing its content rather than reprinting it so that its formatting is
+// kept. The paths are added to the last import declaration, or in a new one
+// after the package clause.
+//
+// Parameters:
+//   - fset: The file set the file was parsed into
+//   - f: The parsed file
+//   - content: The content the file was parsed from
+//   - paths: The imports needed, see importSpecOf
+//
+// Returns:
+//   - string: The content with the missing imports
+func addImports(fset *token.FileSet, f *ast.File, content string, paths []string) string {
+	imported := make(map[string]bool)
+	for _, spec := range f.Imports {
+		if path, err := strconv.Unquote(spec.Path.Value); err == nil {
+			if spec.Name != nil && spec.Name.Name != packageNameOf(path) {
+				path = spec.Name.Name + " " + path
+			}
+			imported[path] = true
+		}
+	}
+	var needed []string
+	for _, imp := range paths {
+		if !imported[imp] {
+			needed = append(needed, imp)
+		}
+	}
+	if len(needed) == 0 {
+		return content
+	}
+	sortImports(needed)
+	missing := make([]string, len(needed))
+	for i, imp := range needed {
+		name, path := importSpecOf(imp)
+		missing[i] = strings.TrimSpace(name + " " + strconv.Quote(path))
+	}
+
+	var last *ast.GenDecl
+	for _, decl := range f.Decls {
+		if genDecl, ok := decl.(*ast.GenDecl); ok && genDecl.Tok == token.IMPORT {
+			last = genDecl
+		}
+	}
+
+	var (
+		offset int
+		insert string
+	)
+	switch {
+	case last == nil:
+		offset = fset.Position(f.Name.End()).Offset
+		insert = "\n\nimport (\n\t" + strings.Join(missing, "\n\t") + "\n)"
+	case last.Lparen.IsValid():
+		offset = fset.Position(last.Rparen).Offset
+		insert = "\t" + strings.Join(missing, "\n\t") + "\n"
+		if !strings.HasSuffix(content[:offset], "\n") {
+			insert = "\n" + insert
+		}
+	default:
+		offset = fset.Position(last.End()).Offset
+		insert = "\nimport " + strings.Join(missing, "\nimport ")
+	}
+	return content[:offset] + insert + content[offset:]
+}
@@ -0,0 +1,128 @@
+package codegen
+
+import (
+	"go/parser"
+	"go/token"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMergeTestCaseTemplates(t *testing.T) {
+	const src = `package p
+
+import "sort"
+
+//go:generate leetcode-gen-test
+func twoSum(nums []int, target int) []int { return nil }
+
+//go:generate leetcode-gen-test
+func twoSumSorted(numbers []int, target int) []int { return nil }
+
+//go:generate leetcode-gen-test
+func count(words sort.StringSlice) int { return 0 }
+`
+	const testCase = `//go:generate leetcode-gen-test generate --test-case=$GOFILE
+package p
+
+import "fmt"
+
+var (
+	// A hand-written case
+	basic   = testTwoSumCase{input: testTwoSumInput{nums: []int{2, 7}, target: 9}, output: testTwoSumOutput{field0: []int{0, 1}}}
+	_ = fmt.Sprint
+)
+
+type testTwoSumInput struct{ nums []int; target int }
+type testTwoSumOutput struct{ field0 []int }
+type testTwoSumCase struct {
+	name   string
+	input  testTwoSumInput
+	output testTwoSumOutput
+}`
+
+	merged, added, _, err := MergeTestCaseTemplates("", []byte(src), "p_testcase.go", []byte(testCase))
+	if err != nil {
+		t.Fatalf("MergeTestCaseTemplates() error = %v", err)
+	}
+	if expected := []string{"TwoSumSorted", "Count"}; !reflect.DeepEqual(added, expected) {
+		t.Errorf("MergeTestCaseTemplates() added %v; expected %v", added, expected)
+	}
+
+	result := string(merged)
+	expectedPrefix := strings.Replace(testCase, `import "fmt"`, "import \"fmt\"\nimport \"sort\"", 1)
+	if !strings.HasPrefix(result, expectedPrefix) {
+		t.Errorf("MergeTestCaseTemplates() changed the existing content:\n%s", result)
+	}
+	for _, typeName := range []string{"testTwoSumSortedCase", "testCountInput"} {
+		if !strings.Contains(result, "type "+typeName+" struct") {
+			t.Errorf("MergeTestCaseTemplates() did not add %s:\n%s", typeName, result)
+		}
+	}
+	if _, err := parser.ParseFile(token.NewFileSet(), "", merged, 0); err != nil {
+		t.Errorf("MergeTestCaseTemplates() produced invalid code: %v\n%s", err, result)
+	}
+
+	// Merging again finds nothing to add
+	again, added, _, err := MergeTestCaseTemplates("", []byte(src), "p_testcase.go", merged)
+	if err != nil || len(added) != 0 || string(again) != result {
+		t.Errorf("MergeTestCaseTemplates() on a merged file = %v, %v; expected no changes", added, err)
+	}
+}
+
+func TestAddImports(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{
+			name:     "no imports",
+			content:  "package p\n\nvar x = 1\n",
+			expected: "package p\n\nimport (\n\t\"sort\"\n\t\"strings\"\n)\n\nvar x = 1\n",
+		},
+		{
+			name:     "single import",
+			content:  "package p\n\nimport \"fmt\"\n\nvar x = fmt.Sprint()\n",
+			expected: "package p\n\nimport \"fmt\"\nimport \"sort\"\nimport \"strings\"\n\nvar x = fmt.Sprint()\n",
+		},
+		{
+			name:     "grouped imports",
+			content:  "package p\n\nimport (\n\t\"fmt\"\n\t\"sort\"\n)\n",
+			expected: "package p\n\nimport (\n\t\"fmt\"\n\t\"sort\"\n\t\"strings\"\n)\n",
+		},
+		{
+			name:     "grouped on one line",
+			content:  "package p\n\nimport (\"fmt\")\n",
+			expected: "package p\n\nimport (\"fmt\"\n\t\"sort\"\n\t\"strings\"\n)\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fset := token.NewFileSet()
+			f, err := parser.ParseFile(fset, "", tt.content, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result := addImports(fset, f, tt.content, []string{"sort", "strings"}); result != tt.expected {
+				t.Errorf("addImports() =\n%s\nexpected\n%s", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestAddImportsAliased(t *testing.T) {
+	const content = "package p\n\nimport (\n\t\"crypto/rand\"\n\trand \"math/rand\"\n)\n"
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", content, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Packages are imported again under the aliases the generated code uses
+	expected := "package p\n\nimport (\n\t\"crypto/rand\"\n\trand \"math/rand\"\n\tmathrand \"math/rand\"\n)\n"
+	if result := addImports(fset, f, content, []string{"crypto/rand", "math/rand", "mathrand math/rand"}); result != expected {
+		t.Errorf("addImports() =\n%s\nexpected\n%s", result, expected)
+	}
+}
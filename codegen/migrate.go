@@ -0,0 +1,713 @@
+package codegen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+
+	"github.com/Ezer015/leetcode-gen-test/utils"
+)
+
+// MigrateTestCases updates the schema types of a test case file that no
+// longer match the signatures in the source file, along with the composite
+// literals of the cases using them:
+//   - Fields renamed in the signature, matched by position and kept with
+//     their values under the new name
+//   - Fields added to the signature, set to their zero value with a TODO
+//     comment, except for the optional fields of test<Func>Case
+//   - Fields removed from the signature, dropped with their values
+//   - Fields whose type changed, with their values converted if the
+//     conversion is lossless, e.g. from []int{1, 2} to []int64{1, 2}
+//
+// Values that cannot be migrated are left as they are and reported. The
+// example cases of the migrated functions are generated again, and the rest
+// of the file is kept as it is: only the migrated types and literals are
+// formatted.
+//
+// Parameters:
+//   - srcFile: The path of the source file
+//   - srcContent: The content of the source file
+//   - testCaseFile: The path of the test case file
+//   - testCaseContent: The content of the test case file
+//
+// Returns:
+//   - []byte: The migrated test case file, the same content if nothing is stale
+//   - []string: The names of the migrated schema types
+//   - []string: A description of each value that could not be migrated, or
+//     was dropped, prefixed with its position
+//   - error: An error if either file cannot be processed
+func (g *Generator) MigrateTestCases(srcFile string, srcContent []byte, testCaseFile string, testCaseContent []byte) ([]byte, []string, []string, error) {
+	tfMetadata, err := g.extractTestFuncs(srcFile, srcContent)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("extracting test function: %w", err)
+	}
+	templates, err := g.testCaseFileOf(tfMetadata)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("generating test case templates: %w", err)
+	}
+
+	fset := token.NewFileSet()
+	expectedFile, err := parser.ParseFile(fset, "", templates, parser.ParseComments)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("parsing test case templates: %v", err)
+	}
+	actualFile, err := parser.ParseFile(fset, testCaseFile, testCaseContent, parser.ParseComments)
+	if err != nil {
+		return nil, nil, nil, DiagnosticsOf(err)
+	}
+
+	m := &migrator{
+		fset:    fset,
+		content: testCaseContent,
+		specs:   typeSpecsOf(actualFile),
+		structs: make(map[string]*structMigration),
+		elided:  make(map[*ast.CompositeLit]string),
+	}
+
+	// Compare the schema types with those of a fresh template
+	expected, actual := schemaTypesOf(expectedFile), schemaTypesOf(actualFile)
+	expectedSpecs := typeSpecsOf(expectedFile)
+	fieldTypes := schemaFieldTypesOf(tfMetadata, g.naming)
+	var migrated []string
+	for name, spec := range expectedSpecs {
+		if actual[name] == "" || actual[name] == expected[name] {
+			continue
+		}
+		sm, ok := newStructMigration(m.specs[name], spec, fieldTypes[name])
+		if !ok {
+			m.problem(m.specs[name].Pos(), "%s is not a struct type, it is left as it is", name)
+			continue
+		}
+		sm.isCase = g.naming.IsTestCase(name)
+		m.structs[name] = sm
+		migrated = append(migrated, name)
+
+		// Replace the declaration with the one of the template
+		m.edits = append(m.edits, edit{
+			start: m.offset(m.specs[name].Name.Pos()),
+			end:   m.offset(m.specs[name].End()),
+			text:  string(templates[fset.Position(spec.Name.Pos()).Offset:fset.Position(spec.End()).Offset]),
+		})
+	}
+	if len(migrated) == 0 {
+		return testCaseContent, nil, nil, nil
+	}
+	sort.Strings(migrated)
+
+	// The example cases of the migrated functions spell the new fields
+	examples := exampleCommentsOf(expectedFile)
+	replaced := make(map[string]bool)
+	for _, name := range migrated {
+		caseType := g.naming.TestCaseTypeNameOf(g.naming.FuncNameOf(name))
+		if replaced[caseType] {
+			continue
+		}
+		replaced[caseType] = true
+		if c, ok := exampleCommentsOf(actualFile)[caseType]; ok && examples[caseType] != nil {
+			m.edits = append(m.edits, edit{start: m.offset(c.Pos()), end: m.offset(c.End()), text: examples[caseType].Text})
+		}
+	}
+
+	ast.Inspect(actualFile, func(n ast.Node) bool {
+		if lit, ok := n.(*ast.CompositeLit); ok {
+			m.visit(lit)
+		}
+		return true
+	})
+
+	edits := m.liveEdits()
+	content, err := applyEdits(testCaseContent, edits)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("migrating test cases: %v", err)
+	}
+	formatted, err := formatEdited(content, editedRangesOf(edits))
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("formatting migrated test cases: %v", err)
+	}
+	return formatted, migrated, m.problems, nil
+}
+
+// exampleCommentsOf maps the names of test case types to the block comments
+// holding their example cases in a test case file, see exampleCaseOf.
+func exampleCommentsOf(f *ast.File) map[string]*ast.Comment {
+	comments := make(map[string]*ast.Comment)
+	for _, group := range f.Comments {
+		for _, c := range group.List {
+			body, ok := strings.CutPrefix(c.Text, "/*")
+			if !ok {
+				continue
+			}
+			rest, ok := strings.CutPrefix(strings.TrimSpace(body), "_ = ")
+			if !ok {
+				continue
+			}
+			if end := strings.IndexAny(rest, "[{"); end > 0 {
+				comments[rest[:end]] = c
+			}
+		}
+	}
+	return comments
+}
+
+// editedRangesOf returns the ranges of the content that edits produce, in
+// the coordinates of the edited content.
+func editedRangesOf(edits []edit) []edit {
+	sort.SliceStable(edits, func(i, j int) bool { return edits[i].start < edits[j].start })
+	ranges := make([]edit, len(edits))
+	delta := 0
+	for i, e := range edits {
+		start := e.start + delta
+		ranges[i] = edit{start: start, end: start + len(e.text)}
+		delta += len(e.text) - (e.end - e.start)
+	}
+	return ranges
+}
+
+// formatEdited formats the composite literals and type declarations of a
+// file that hold edited ranges, leaving the rest of the file as it is.
+//
+// Parameters:
+//   - content: The edited content of the file
+//   - edited: The edited ranges of the content
+//
+// Returns:
+//   - []byte: The content with the edited literals and types formatted
+//   - error: An error if the content does not parse
+func formatEdited(content []byte, edited []edit) ([]byte, error) {
+	formatted, err := format.Source(content)
+	if err != nil {
+		return nil, err
+	}
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", content, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	ff, err := parser.ParseFile(fset, "", formatted, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	// Formatting keeps the literals and types in order, so that each is
+	// replaced with its counterpart in the formatted file
+	units, formattedUnits := formatUnitsOf(f), formatUnitsOf(ff)
+	if len(units) != len(formattedUnits) {
+		return formatted, nil
+	}
+	offset := func(pos token.Pos) int { return fset.Position(pos).Offset }
+	var edits []edit
+	for i, unit := range units {
+		start, end := offset(unit.Pos()), offset(unit.End())
+		for _, r := range edited {
+			if start <= r.start && r.end <= end {
+				text := formatted[offset(formattedUnits[i].Pos()):offset(formattedUnits[i].End())]
+				edits = append(edits, edit{start: start, end: end, text: string(text)})
+				break
+			}
+		}
+	}
+	return applyEdits(content, edits)
+}
+
+// formatUnitsOf returns the outermost composite literals and type
+// declarations of a file, which are formatted as a whole once edited.
+func formatUnitsOf(f *ast.File) []ast.Node {
+	var units []ast.Node
+	ast.Inspect(f, func(n ast.Node) bool {
+		switch n.(type) {
+		case *ast.CompositeLit, *ast.TypeSpec:
+			units = append(units, n)
+			return false
+		}
+		return true
+	})
+	return units
+}
+
+// MigrateTestCases migrates the stale schema types of a test case file and
+// their cases with the default options, see Generator.MigrateTestCases.
+func MigrateTestCases(srcFile string, srcContent []byte, testCaseFile string, testCaseContent []byte) ([]byte, []string, []string, error) {
+	return defaultGenerator.MigrateTestCases(srcFile, srcContent, testCaseFile, testCaseContent)
+}
+
+// schemaField is a field of a schema struct type.
+type schemaField struct {
+	name string
+	typ  string
+	// expr is the type expression of the field
+	expr ast.Expr
+}
+
+// structMigration describes how the fields of a schema struct type changed.
+type structMigration struct {
+	// renamed maps old field names to new ones
+	renamed map[string]string
+	// removed holds the old names of removed fields
+	removed map[string]bool
+	// added holds the new fields without an old counterpart
+	added []schemaField
+	// retyped maps the new names of fields whose type changed to their new type
+	retyped map[string]types.Type
+	// types maps new field names to their types, if known
+	types  map[string]types.Type
+	isCase bool
+}
+
+// newStructMigration compares the old and new declarations of a struct type.
+// Fields missing from the new declaration and new fields at the same index
+// are taken to be renamed.
+//
+// Parameters:
+//   - oldSpec: The declaration in the test case file
+//   - newSpec: The declaration in the template
+//   - fieldTypes: The types of the new fields, if known
+//
+// Returns:
+//   - *structMigration: The changes of the fields
+//   - bool: Whether both declarations are struct types
+func newStructMigration(oldSpec, newSpec *ast.TypeSpec, fieldTypes map[string]types.Type) (*structMigration, bool) {
+	oldFields, ok := structFieldsOf(oldSpec)
+	if !ok {
+		return nil, false
+	}
+	newFields, ok := structFieldsOf(newSpec)
+	if !ok {
+		return nil, false
+	}
+
+	sm := &structMigration{
+		renamed: make(map[string]string),
+		removed: make(map[string]bool),
+		retyped: make(map[string]types.Type),
+		types:   fieldTypes,
+	}
+	oldByName := make(map[string]schemaField)
+	for _, f := range oldFields {
+		oldByName[f.name] = f
+	}
+	newByName := make(map[string]bool)
+	for _, f := range newFields {
+		newByName[f.name] = true
+	}
+
+	for i, f := range newFields {
+		old, ok := oldByName[f.name]
+		if !ok && i < len(oldFields) && !newByName[oldFields[i].name] {
+			old, ok = oldFields[i], true
+			sm.renamed[old.name] = f.name
+		}
+		switch {
+		case !ok:
+			sm.added = append(sm.added, f)
+		case old.typ != f.typ:
+			sm.retyped[f.name] = fieldTypes[f.name]
+		}
+	}
+	for _, f := range oldFields {
+		if _, isRenamed := sm.renamed[f.name]; !isRenamed && !newByName[f.name] {
+			sm.removed[f.name] = true
+		}
+	}
+	return sm, true
+}
+
+// structFieldsOf returns the fields of a struct type declaration, one per name.
+func structFieldsOf(spec *ast.TypeSpec) ([]schemaField, bool) {
+	st, ok := spec.Type.(*ast.StructType)
+	if !ok {
+		return nil, false
+	}
+	var fields []schemaField
+	for _, field := range st.Fields.List {
+		for _, name := range field.Names {
+			fields = append(fields, schemaField{name: name.Name, typ: types.ExprString(field.Type), expr: field.Type})
+		}
+	}
+	return fields, true
+}
+
+// typeSpecsOf maps the names of the types declared in a file to their declarations.
+func typeSpecsOf(f *ast.File) map[string]*ast.TypeSpec {
+	specs := make(map[string]*ast.TypeSpec)
+	for _, decl := range f.Decls {
+		if genDecl, ok := decl.(*ast.GenDecl); ok && genDecl.Tok == token.TYPE {
+			for _, spec := range genDecl.Specs {
+				ts := spec.(*ast.TypeSpec)
+				specs[ts.Name.Name] = ts
+			}
+		}
+	}
+	return specs
+}
+
+// schemaFieldTypesOf maps the names of the input and output types of the
+// test functions, following naming, to the types of their fields. Fields
+// spelled in LeetCode notation are strings.
+func schemaFieldTypesOf(tfMetadata *testFuncMetadata, naming utils.Naming) map[string]map[string]types.Type {
+	typesOf := func(fields []fieldInfo) map[string]types.Type {
+		m := make(map[string]types.Type)
+		for _, f := range fields {
+			if f.Notation() {
+				m[f.Name] = types.Typ[types.String]
+			} else {
+				m[f.Name] = f.typ
+			}
+		}
+		return m
+	}
+
+	fieldTypes := make(map[string]map[string]types.Type)
+	for _, tf := range tfMetadata.testFuncs {
+		fieldTypes[naming.TestCaseInputTypeNameOf(tf.CaseName())] = typesOf(tf.Params)
+		fieldTypes[naming.TestCaseOutputTypeNameOf(tf.CaseName())] = typesOf(tf.Outputs())
+	}
+	return fieldTypes
+}
+
+// edit replaces the bytes in [start, end) of a file with text.
+type edit struct {
+	start, end int
+	text       string
+}
+
+// applyEdits applies non-overlapping edits to content.
+func applyEdits(content []byte, edits []edit) ([]byte, error) {
+	sort.SliceStable(edits, func(i, j int) bool { return edits[i].start < edits[j].start })
+	var sb strings.Builder
+	last := 0
+	for _, e := range edits {
+		if e.start < last {
+			return nil, fmt.Errorf("overlapping edits at offset %d", e.start)
+		}
+		sb.Write(content[last:e.start])
+		sb.WriteString(e.text)
+		last = e.end
+	}
+	sb.Write(content[last:])
+	return []byte(sb.String()), nil
+}
+
+// migrator collects the edits migrating the literals of a test case file.
+type migrator struct {
+	fset    *token.FileSet
+	content []byte
+	// specs are the type declarations of the test case file
+	specs map[string]*ast.TypeSpec
+	// structs are the struct types to migrate, by name
+	structs map[string]*structMigration
+	// elided maps literals whose type is elided to the struct type they have
+	elided   map[*ast.CompositeLit]string
+	edits    []edit
+	removed  []edit
+	problems []string
+}
+
+func (m *migrator) offset(pos token.Pos) int {
+	return m.fset.Position(pos).Offset
+}
+
+func (m *migrator) text(node ast.Node) string {
+	return string(m.content[m.offset(node.Pos()):m.offset(node.End())])
+}
+
+func (m *migrator) problem(pos token.Pos, format string, args ...any) {
+	m.problems = append(m.problems, fmt.Sprintf("%s: %s", m.fset.Position(pos), fmt.Sprintf(format, args...)))
+}
+
+// liveEdits returns the edits that are not inside removed values.
+func (m *migrator) liveEdits() []edit {
+	edits := append([]edit(nil), m.removed...)
+	for _, e := range m.edits {
+		inside := false
+		for _, r := range m.removed {
+			if e.start >= r.start && e.end <= r.end {
+				inside = true
+				break
+			}
+		}
+		if !inside {
+			edits = append(edits, e)
+		}
+	}
+	return edits
+}
+
+// structNameOf returns the name of the schema struct type a type expression
+// refers to, e.g. "testMaxInput" for testMaxInput[int], or an empty string.
+func (m *migrator) structNameOf(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		if _, ok := m.specs[e.Name]; ok {
+			return e.Name
+		}
+	case *ast.IndexExpr:
+		return m.structNameOf(e.X)
+	case *ast.IndexListExpr:
+		return m.structNameOf(e.X)
+	}
+	return ""
+}
+
+// visit migrates a composite literal if it has a migrated struct type, and
+// records the types of the literals nested in it whose type is elided, such
+// as the input of a case or the cases of a slice.
+func (m *migrator) visit(lit *ast.CompositeLit) {
+	name := m.elided[lit]
+	if lit.Type != nil {
+		name = m.structNameOf(lit.Type)
+	}
+
+	// Record the struct types of nested literals
+	switch t := lit.Type.(type) {
+	case *ast.ArrayType:
+		if elem := m.structNameOf(t.Elt); elem != "" {
+			for _, elt := range lit.Elts {
+				if kv, ok := elt.(*ast.KeyValueExpr); ok {
+					elt = kv.Value
+				}
+				if nested, ok := elt.(*ast.CompositeLit); ok && nested.Type == nil {
+					m.elided[nested] = elem
+				}
+			}
+		}
+	case *ast.MapType:
+		if elem := m.structNameOf(t.Value); elem != "" {
+			for _, elt := range lit.Elts {
+				if kv, ok := elt.(*ast.KeyValueExpr); ok {
+					if nested, ok := kv.Value.(*ast.CompositeLit); ok && nested.Type == nil {
+						m.elided[nested] = elem
+					}
+				}
+			}
+		}
+	}
+	if name == "" {
+		return
+	}
+	if oldFields, ok := structFieldsOf(m.specs[name]); ok {
+		for _, elt := range lit.Elts {
+			kv, ok := elt.(*ast.KeyValueExpr)
+			if !ok {
+				continue
+			}
+			nested, ok := kv.Value.(*ast.CompositeLit)
+			if !ok || nested.Type != nil {
+				continue
+			}
+			for _, f := range oldFields {
+				if key, ok := kv.Key.(*ast.Ident); ok && key.Name == f.name {
+					m.elided[nested] = m.structNameOf(f.expr)
+				}
+			}
+		}
+	}
+
+	if sm, ok := m.structs[name]; ok {
+		m.migrate(lit, name, sm)
+	}
+}
+
+// migrate collects the edits migrating a literal of a struct type.
+func (m *migrator) migrate(lit *ast.CompositeLit, name string, sm *structMigration) {
+	present := make(map[string]bool)
+	lastKept := -1
+	for i, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			m.problem(lit.Pos(), "%s literal without field names is left as it is", name)
+			return
+		}
+		key, ok := kv.Key.(*ast.Ident)
+		if !ok {
+			m.problem(lit.Pos(), "%s literal without field names is left as it is", name)
+			return
+		}
+
+		if sm.removed[key.Name] {
+			start, end := m.offset(elt.Pos()), m.offset(elt.End())
+			if i+1 < len(lit.Elts) {
+				end = m.offset(lit.Elts[i+1].Pos())
+			} else if rest := string(m.content[end:m.offset(lit.Rbrace)]); strings.HasPrefix(strings.TrimLeft(rest, " \t"), ",") {
+				end += strings.Index(rest, ",") + 1
+			}
+			m.removed = append(m.removed, edit{start: start, end: end})
+			m.problem(elt.Pos(), "dropped %s of %s, which is no longer a field", m.text(elt), name)
+			continue
+		}
+		lastKept = i
+
+		fieldName := key.Name
+		if newName, ok := sm.renamed[key.Name]; ok {
+			fieldName = newName
+			m.edits = append(m.edits, edit{start: m.offset(key.Pos()), end: m.offset(key.End()), text: newName})
+		}
+		present[fieldName] = true
+
+		if to, ok := sm.retyped[fieldName]; ok {
+			edits, err := m.convert(kv.Value, to)
+			if err != nil {
+				m.problem(kv.Value.Pos(), "cannot convert %s of %s: %v", m.text(kv.Value), fieldName, err)
+				continue
+			}
+			m.edits = append(m.edits, edits...)
+		}
+	}
+
+	// Add the new fields with their zero values
+	if sm.isCase {
+		return
+	}
+	var fields []string
+	multiline := m.fset.Position(lit.Lbrace).Line != m.fset.Position(lit.Rbrace).Line
+	for _, f := range sm.added {
+		if present[f.name] {
+			continue
+		}
+		zero, ok := zeroValueOf(f.typ, sm.types[f.name])
+		if !ok {
+			m.problem(lit.Pos(), "cannot set %s of %s to its zero value, left out", f.name, name)
+			continue
+		}
+		if multiline {
+			fields = append(fields, fmt.Sprintf("%s: %s, // TODO: set %s\n", f.name, zero, f.name))
+		} else {
+			fields = append(fields, fmt.Sprintf("%s: %s /* TODO: set %s */", f.name, zero, f.name))
+		}
+	}
+	if len(fields) == 0 {
+		return
+	}
+
+	text := strings.Join(fields, "")
+	if !multiline {
+		text = strings.Join(fields, ", ")
+		if lastKept >= 0 {
+			// A comma follows the last kept field unless it ends the literal
+			rest := string(m.content[m.offset(lit.Elts[lastKept].End()):m.offset(lit.Rbrace)])
+			if lastKept == len(lit.Elts)-1 && !strings.Contains(rest, ",") {
+				text = ", " + text
+			} else if !strings.HasSuffix(rest, " ") {
+				text = " " + text
+			}
+		}
+	}
+	rbrace := m.offset(lit.Rbrace)
+	m.edits = append(m.edits, edit{start: rbrace, end: rbrace, text: text})
+}
+
+// convert collects the edits converting the value of a field to a new type,
+// which is possible for constants representable in the new type and for
+// slice, array and map literals whose elements can be converted.
+func (m *migrator) convert(expr ast.Expr, to types.Type) ([]edit, error) {
+	if to == nil {
+		return nil, fmt.Errorf("the new type is unknown")
+	}
+	if _, ok := to.(*types.TypeParam); ok {
+		return nil, fmt.Errorf("the new type is a type parameter")
+	}
+	typeString, _ := qualifiedTypeString(to)
+
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		return m.convert(e.X, to)
+	case *ast.Ident:
+		if e.Name == "nil" {
+			switch to.Underlying().(type) {
+			case *types.Pointer, *types.Slice, *types.Map, *types.Chan, *types.Signature, *types.Interface:
+				return nil, nil
+			}
+			return nil, fmt.Errorf("nil is not a valid %s", typeString)
+		}
+	case *ast.CompositeLit:
+		var edits []edit
+		if e.Type != nil {
+			if types.ExprString(e.Type) == typeString {
+				return nil, nil
+			}
+			edits = append(edits, edit{start: m.offset(e.Type.Pos()), end: m.offset(e.Type.End()), text: typeString})
+		}
+		var key, elem types.Type
+		switch u := to.Underlying().(type) {
+		case *types.Slice:
+			elem = u.Elem()
+		case *types.Array:
+			elem = u.Elem()
+		case *types.Map:
+			key, elem = u.Key(), u.Elem()
+		default:
+			return nil, fmt.Errorf("the literal is not a valid %s", typeString)
+		}
+		for _, elt := range e.Elts {
+			value := elt
+			if kv, ok := elt.(*ast.KeyValueExpr); ok {
+				if key != nil {
+					keyEdits, err := m.convert(kv.Key, key)
+					if err != nil {
+						return nil, err
+					}
+					edits = append(edits, keyEdits...)
+				}
+				value = kv.Value
+			}
+			valueEdits, err := m.convert(value, elem)
+			if err != nil {
+				return nil, err
+			}
+			edits = append(edits, valueEdits...)
+		}
+		return edits, nil
+	}
+
+	// Constants keep their spelling if they are representable in the new type
+	if basic, ok := to.Underlying().(*types.Basic); ok {
+		if _, err := types.Eval(token.NewFileSet(), nil, token.NoPos, fmt.Sprintf("[]%s{%s}", basic.Name(), m.text(expr))); err == nil {
+			return nil, nil
+		}
+	}
+	return nil, fmt.Errorf("%s is not a constant representable as %s", m.text(expr), typeString)
+}
+
+// zeroValueOf spells the zero value of a field type.
+//
+// Parameters:
+//   - typeString: The type of the field as written in the struct type
+//   - typ: The type of the field, or nil to look up predeclared types by name
+//
+// Returns:
+//   - string: The zero value
+//   - bool: Whether the zero value can be spelled, which is not the case for
+//     type parameters and unknown types
+func zeroValueOf(typeString string, typ types.Type) (string, bool) {
+	if typ == nil {
+		obj, ok := types.Universe.Lookup(typeString).(*types.TypeName)
+		if !ok {
+			return "", false
+		}
+		typ = obj.Type()
+	}
+	if _, ok := typ.(*types.TypeParam); ok {
+		return "", false
+	}
+
+	switch u := typ.Underlying().(type) {
+	case *types.Basic:
+		switch {
+		case u.Info()&types.IsNumeric != 0:
+			return "0", true
+		case u.Info()&types.IsString != 0:
+			return `""`, true
+		case u.Info()&types.IsBoolean != 0:
+			return "false", true
+		}
+	case *types.Pointer, *types.Slice, *types.Map, *types.Chan, *types.Signature, *types.Interface:
+		return "nil", true
+	case *types.Struct, *types.Array:
+		return typeString + "{}", true
+	}
+	return "", false
+}
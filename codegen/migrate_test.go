@@ -0,0 +1,208 @@
+package codegen
+
+import (
+	"go/types"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMigrateTestCases(t *testing.T) {
+	const testCase = `package p
+
+var (
+	basic = testTwoSumCase{input: testTwoSumInput{nums: []int{2, 7}, target: 9}, output: testTwoSumOutput{field0: []int{0, 1}}}
+	multi = testTwoSumCase{
+		input: testTwoSumInput{
+			nums:   []int{-3, 300}, // Keeps its comment
+			target: 1,
+		},
+	}
+	elided = []testTwoSumCase{{input: testTwoSumInput{nums: nil, target: 2}}}
+	sorted = testSortCase{input: testSortInput{words: []string{"b", "a"}, reverse: true}}
+)
+
+type testTwoSumInput struct {
+	nums   []int
+	target int
+}
+type testTwoSumOutput struct{ field0 []int }
+type testTwoSumCase struct {
+	name   string
+	input  testTwoSumInput
+	output testTwoSumOutput
+}
+
+type testSortInput struct {
+	words   []string
+	reverse bool
+}
+type testSortOutput struct{ words []string }
+type testSortCase struct {
+	name   string
+	input  testSortInput
+	output testSortOutput
+}
+`
+
+	t.Run("up to date", func(t *testing.T) {
+		const src = `package p
+
+//go:generate leetcode-gen-test
+func twoSum(nums []int, target int) []int { return nil }
+
+//go:generate leetcode-gen-test
+func sort(words []string, reverse bool) {}
+`
+		result, migrated, problems, err := MigrateTestCases("", []byte(src), "p_testcase.go", []byte(testCase))
+		if err != nil {
+			t.Fatalf("MigrateTestCases() error = %v", err)
+		}
+		if string(result) != testCase || migrated != nil || problems != nil {
+			t.Errorf("MigrateTestCases() = %v, %v; expected no changes", migrated, problems)
+		}
+	})
+
+	t.Run("changed signatures", func(t *testing.T) {
+		const src = `package p
+
+//go:generate leetcode-gen-test
+func twoSum(numbers []int8, target int16, limit int) []int { return nil }
+
+//go:generate leetcode-gen-test
+func sort(words []string) {}
+`
+		result, migrated, problems, err := MigrateTestCases("", []byte(src), "p_testcase.go", []byte(testCase))
+		if err != nil {
+			t.Fatalf("MigrateTestCases() error = %v", err)
+		}
+		if expected := []string{"testSortInput", "testTwoSumInput"}; !reflect.DeepEqual(migrated, expected) {
+			t.Errorf("MigrateTestCases() migrated %v; expected %v", migrated, expected)
+		}
+
+		for _, expected := range []string{
+			"testTwoSumInput{numbers: []int8{2, 7}, target: 9, limit: 0 /* TODO: set limit */}",
+			"numbers: []int{-3, 300}, // Keeps its comment",
+			"limit:   0, // TODO: set limit",
+			"testTwoSumInput{numbers: nil, target: 2, limit: 0 /* TODO: set limit */}",
+			"testSortInput{words: []string{\"b\", \"a\"}}",
+			"type testTwoSumInput struct {\n\tnumbers []int8\n\ttarget  int16\n\tlimit   int\n}",
+			"type testSortInput struct {\n\twords []string\n}",
+		} {
+			if !strings.Contains(string(result), expected) {
+				t.Errorf("MigrateTestCases() result lacks %q:\n%s", expected, result)
+			}
+		}
+
+		expectedProblems := []string{
+			"p_testcase.go:7:12: cannot convert []int{-3, 300} of numbers",
+			"p_testcase.go:12:72: dropped reverse: true of testSortInput",
+		}
+		if len(problems) != len(expectedProblems) {
+			t.Fatalf("MigrateTestCases() problems = %q; expected %d", problems, len(expectedProblems))
+		}
+		for i, expected := range expectedProblems {
+			if !strings.HasPrefix(problems[i], expected) {
+				t.Errorf("problems[%d] = %q; expected it to start with %q", i, problems[i], expected)
+			}
+		}
+	})
+}
+
+func TestMigrateTestCasesKeepsUntouchedCode(t *testing.T) {
+	const src = "package p\n\n//leetcode:test\nfunc twoSum(numbers []int, target int) []int { return nil }\n\n//leetcode:test\nfunc half(n int) int { return n / 2 }\n"
+	const testCase = `package p
+
+// Auto-generated test case template for twoSum
+var (
+/*
+	_ = testTwoSumCase{
+		input: testTwoSumInput{
+			nums:   ...,
+			target: ...,
+		},
+		output: testTwoSumOutput{
+			field0: ...,
+		},
+	}
+*/
+	basic = testTwoSumCase{input: testTwoSumInput{nums: []int{2, 7},target: 9}}
+)
+
+var (
+	// Not gofmt-ed, and left so
+	even  =  testHalfCase{input: testHalfInput{n:4}, output: testHalfOutput{field0:2}}
+)
+
+type testTwoSumInput struct {
+	nums   []int
+	target int
+}
+
+type testTwoSumOutput struct {
+	field0 []int
+}
+
+type testTwoSumCase struct {
+	name   string
+	input  testTwoSumInput
+	output testTwoSumOutput
+}
+
+type (
+	testHalfInput  struct{ n int }
+	testHalfOutput struct{ field0 int }
+	testHalfCase   struct {
+		name   string
+		input  testHalfInput
+		output testHalfOutput
+	}
+)
+`
+	result, migrated, _, err := MigrateTestCases("", []byte(src), "p_testcase.go", []byte(testCase))
+	if err != nil {
+		t.Fatalf("MigrateTestCases() error = %v", err)
+	}
+	if expected := []string{"testTwoSumInput"}; !reflect.DeepEqual(migrated, expected) {
+		t.Errorf("MigrateTestCases() migrated %v; expected %v", migrated, expected)
+	}
+	expected := strings.NewReplacer(
+		"\t\t\tnums:   ...,", "\t\t\tnumbers: ...,",
+		"\t\t\ttarget: ...,", "\t\t\ttarget:  ...,",
+		"testTwoSumInput{nums: []int{2, 7},target: 9}", "testTwoSumInput{numbers: []int{2, 7}, target: 9}",
+		"\tnums   []int\n\ttarget int\n", "\tnumbers []int\n\ttarget  int\n",
+	).Replace(testCase)
+	if string(result) != expected {
+		t.Errorf("MigrateTestCases() =\n%s\nexpected\n%s", result, expected)
+	}
+}
+
+func TestZeroValueOf(t *testing.T) {
+	point := types.NewNamed(types.NewTypeName(0, nil, "Point", nil), types.NewStruct(nil, nil), nil)
+	tests := []struct {
+		typeString string
+		typ        types.Type
+		expected   string
+		ok         bool
+	}{
+		{"int", nil, "0", true},
+		{"float64", types.Typ[types.Float64], "0", true},
+		{"string", nil, `""`, true},
+		{"bool", nil, "false", true},
+		{"[]int", types.NewSlice(types.Typ[types.Int]), "nil", true},
+		{"*ListNode", types.NewPointer(point), "nil", true},
+		{"Point", point, "Point{}", true},
+		{"[2]int", types.NewArray(types.Typ[types.Int], 2), "[2]int{}", true},
+		{"T", types.NewTypeParam(types.NewTypeName(0, nil, "T", nil), types.NewInterfaceType(nil, nil)), "", false},
+		{"Unknown", nil, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.typeString, func(t *testing.T) {
+			result, ok := zeroValueOf(tt.typeString, tt.typ)
+			if result != tt.expected || ok != tt.ok {
+				t.Errorf("zeroValueOf(%q) = %q, %v; expected %q, %v", tt.typeString, result, ok, tt.expected, tt.ok)
+			}
+		})
+	}
+}
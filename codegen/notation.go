@@ -0,0 +1,67 @@
+package codegen
+
+import (
+	"go/types"
+	"strconv"
+)
+
+// notationExampleOf shows how a value of type t, for which hasNodes holds, is
+// spelled in LeetCode notation, e.g. "[[1,2],[3]]" for a slice of lists.
+func notationExampleOf(t types.Type) string {
+	var example func(t types.Type) string
+	example = func(t types.Type) string {
+		switch u := types.Unalias(t).(type) {
+		case *types.Slice:
+			return "[" + example(u.Elem()) + "]"
+		case *types.Array:
+			return "[" + example(u.Elem()) + "]"
+		}
+		return "[1,2,3]"
+	}
+	return strconv.Quote(example(t))
+}
+
+// hasNodes reports whether values of type t are linked lists or binary trees,
+// possibly nested in slices or arrays. Test cases spell such values in
+// LeetCode notation, which generated tests decode with lctest.MustDecode.
+//
+// Linked lists are pointers to structs with Val and Next fields, binary trees
+// pointers to structs with Val, Left and Right fields, as in the ListNode and
+// TreeNode types LeetCode defines.
+func hasNodes(t types.Type) bool {
+	switch u := t.(type) {
+	case nil:
+		return false
+	case *types.Pointer:
+		return isNode(u)
+	case *types.Slice:
+		return hasNodes(u.Elem())
+	case *types.Array:
+		return hasNodes(u.Elem())
+	default:
+		if alias, ok := t.(*types.Alias); ok {
+			return hasNodes(types.Unalias(alias))
+		}
+		return false
+	}
+}
+
+// isNode reports whether ptr points to a linked list or binary tree node.
+func isNode(ptr *types.Pointer) bool {
+	st, ok := ptr.Elem().Underlying().(*types.Struct)
+	if !ok {
+		return false
+	}
+	fields := make(map[string]types.Type)
+	for i := 0; i < st.NumFields(); i++ {
+		fields[st.Field(i).Name()] = st.Field(i).Type()
+	}
+	isLink := func(name string) bool {
+		t, ok := fields[name]
+		return ok && types.Identical(t, ptr)
+	}
+	if _, ok := fields["Val"]; !ok {
+		return false
+	}
+	return isLink("Next") || (isLink("Left") && isLink("Right"))
+}
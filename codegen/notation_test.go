@@ -0,0 +1,145 @@
+package codegen
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"reflect"
+	"testing"
+)
+
+const notationSrc = `package p
+
+type ListNode struct {
+	Val  int
+	Next *ListNode
+}
+type TreeNode struct {
+	Val         int
+	Left, Right *TreeNode
+}
+type Node struct {
+	Val      int
+	Children []*Node
+}
+type halfTree struct {
+	Val  int
+	Left *halfTree
+}
+type Forest = []*TreeNode
+
+func list(head *ListNode) {}
+func tree(root *TreeNode) {}
+func lists(heads []*ListNode) {}
+func forest(trees [2][]*TreeNode) {}
+func alias(trees Forest) {}
+func value(node ListNode) {}
+func nary(root *Node) {}
+func half(root *halfTree) {}
+func ints(nums []int) {}
+`
+
+func notationParamsOf(t *testing.T, funcName string) []fieldInfo {
+	t.Helper()
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "p.go", notationSrc, 0)
+	if err != nil {
+		t.Fatalf("parsing source: %v", err)
+	}
+	info := &types.Info{Types: make(map[ast.Expr]types.TypeAndValue)}
+	if _, err := (&types.Config{}).Check("", fset, []*ast.File{f}, info); err != nil {
+		t.Fatalf("type checking source: %v", err)
+	}
+	for _, d := range f.Decls {
+		if fd, ok := d.(*ast.FuncDecl); ok && fd.Name.Name == funcName {
+			return extractFields(fd.Type.Params, info)
+		}
+	}
+	t.Fatalf("function %s not found", funcName)
+	return nil
+}
+
+func TestHasNodes(t *testing.T) {
+	tests := []struct {
+		funcName        string
+		expected        bool
+		expectedExample string
+	}{
+		{"list", true, `"[1,2,3]"`},
+		{"tree", true, `"[1,2,3]"`},
+		{"lists", true, `"[[1,2,3]]"`},
+		{"forest", true, `"[[[1,2,3]]]"`},
+		{"alias", true, `"[[1,2,3]]"`},
+		{"value", false, ""},
+		{"nary", false, ""},
+		{"half", false, ""},
+		{"ints", false, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.funcName, func(t *testing.T) {
+			param := notationParamsOf(t, test.funcName)[0]
+			if result := param.Notation(); result != test.expected {
+				t.Errorf("hasNodes(%s) = %v; expected %v", param.Type, result, test.expected)
+			}
+			if !test.expected {
+				if result := param.SchemaType(); result != param.Type {
+					t.Errorf("SchemaType() = %q; expected %q", result, param.Type)
+				}
+				return
+			}
+			if result := param.SchemaType(); result != "string" {
+				t.Errorf("SchemaType() = %q; expected %q", result, "string")
+			}
+			if result := param.NotationExample(); result != test.expectedExample {
+				t.Errorf("NotationExample() = %s; expected %s", result, test.expectedExample)
+			}
+		})
+	}
+}
+
+func TestArgsOf(t *testing.T) {
+	tests := []struct {
+		name     string
+		tf       testFuncData
+		expected []argInfo
+	}{
+		{
+			name: "passed directly",
+			tf:   testFuncData{Params: notationParamsOf(t, "ints")},
+			expected: []argInfo{
+				{Name: "nums", Type: "[]int"},
+			},
+		},
+		{
+			name: "cloned",
+			tf:   testFuncData{Params: notationParamsOf(t, "ints"), InPlace: []string{"nums"}},
+			expected: []argInfo{
+				{Name: "nums", Type: "[]int", Var: "gotNums", Clone: true},
+			},
+		},
+		{
+			name: "decoded",
+			tf:   testFuncData{Params: notationParamsOf(t, "tree")},
+			expected: []argInfo{
+				{Name: "root", Type: "*TreeNode", Var: "inRoot", Decode: true},
+			},
+		},
+		{
+			name: "decoded in place",
+			tf:   testFuncData{Params: notationParamsOf(t, "list"), InPlace: []string{"head"}},
+			expected: []argInfo{
+				{Name: "head", Type: "*ListNode", Var: "gotHead", Decode: true},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if result := argsOf(test.tf); !reflect.DeepEqual(result, test.expected) {
+				t.Errorf("argsOf() = %+v; expected %+v", result, test.expected)
+			}
+		})
+	}
+}
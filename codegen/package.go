@@ -0,0 +1,79 @@
+package codegen
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"strings"
+)
+
+// parsePackage parses a file along with the other files of its package in the
+// same directory, so that the types, helpers and constants it shares with
+// them resolve during type checking. Files excluded by build constraints and
+// files of other packages are left out, and so are test files unless include
+// asks for them.
+//
+// Parameters:
+//   - fset: The file set to parse the files into
+//   - fsys: The file system the sibling files are read from
+//   - filename: The path of the file; if empty or in a missing directory, the
+//     file is parsed on its own
+//   - content: The content of the file, which takes precedence over the
+//     version in the file system
+//   - include: Reports whether a sibling file with the given base name,
+//     including test files, is parsed as well
+//
+// Returns:
+//   - *ast.File: The parsed file
+//   - []*ast.File: All parsed files of the package, starting with the file
+//   - error: An error if a file cannot be read, or Diagnostics holding the
+//     syntax errors of all files that cannot be parsed
+func parsePackage(fset *token.FileSet, fsys fileSystem, filename string, content []byte, include func(name string) bool) (*ast.File, []*ast.File, error) {
+	f, err := parser.ParseFile(fset, filename, content, parser.ParseComments)
+	if err != nil {
+		return nil, nil, DiagnosticsOf(err)
+	}
+	files := []*ast.File{f}
+	if filename == "" {
+		return f, files, nil
+	}
+
+	dir := fsys.dir(filename)
+	entries, err := fsys.readDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return f, files, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading package directory: %v", err)
+	}
+	var ds Diagnostics
+	ctxt := fsys.buildContext()
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || name == fsys.base(filename) || !strings.HasSuffix(name, ".go") || !include(name) {
+			continue
+		}
+		if match, err := ctxt.MatchFile(dir, name); err != nil || !match {
+			continue
+		}
+		siblingContent, err := fsys.readFile(fsys.join(dir, name))
+		if err != nil {
+			return nil, nil, fmt.Errorf("reading package file: %v", err)
+		}
+		sibling, err := parser.ParseFile(fset, fsys.join(dir, name), siblingContent, parser.ParseComments)
+		if err != nil {
+			ds.add(err)
+			continue
+		}
+		if sibling.Name.Name == f.Name.Name {
+			files = append(files, sibling)
+		}
+	}
+	if err := ds.err(); err != nil {
+		return nil, nil, err
+	}
+	return f, files, nil
+}
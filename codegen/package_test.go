@@ -0,0 +1,128 @@
+package codegen
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExtractAcrossPackageFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"types.go": `package p
+
+type ListNode struct {
+	Val  int
+	Next *ListNode
+}
+
+const limit = 10
+`,
+		"list.go": `package p
+
+//go:generate leetcode-gen-test
+func reverse(head *ListNode) *ListNode { return head }
+`,
+		// Test case files and tests of the package must not break extraction
+		"list_testcase.go": "package p\n\nvar stale = undefinedType{}\n",
+		"list_test.go":     "package p\n\nvar broken = undefinedType{}\n",
+		"other.go":         "//go:build ignore\n\npackage other\n",
+		"math.go": `package p
+
+//go:generate leetcode-gen-test
+func clamp(x int) int { return min(x, limit) }
+`,
+		"math_testcase.go": `package p
+
+var basicClamp = testClampCase{input: testClampInput{x: limit + 1}, output: testClampOutput{field0: limit}}
+
+type testClampInput struct{ x int }
+type testClampOutput struct{ field0 int }
+type testClampCase struct {
+	name   string
+	input  testClampInput
+	output testClampOutput
+}
+`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The content passed in takes precedence over the file on disk
+	tfMetadata, err := defaultGenerator.extractTestFuncs(filepath.Join(dir, "list.go"), []byte(`package p
+
+//go:generate leetcode-gen-test
+func reverseList(head *ListNode) *ListNode { return head }
+`))
+	if err != nil {
+		t.Fatalf("extractTestFuncs() error = %v", err)
+	}
+	if len(tfMetadata.testFuncs) != 1 || tfMetadata.testFuncs[0].FuncName != "reverseList" {
+		t.Errorf("extractTestFuncs() found %+v; expected only reverseList", tfMetadata.testFuncs)
+	}
+
+	if _, err := defaultGenerator.extractTestCases(filepath.Join(dir, "math_testcase.go"), []byte(files["math_testcase.go"])); err == nil {
+		t.Fatalf("extractTestCases() succeeded with a broken test case file in the package; expected an error")
+	}
+	if err := os.Remove(filepath.Join(dir, "list_testcase.go")); err != nil {
+		t.Fatal(err)
+	}
+	tcMetadata, err := defaultGenerator.extractTestCases(filepath.Join(dir, "math_testcase.go"), []byte(files["math_testcase.go"]))
+	if err != nil {
+		t.Fatalf("extractTestCases() error = %v", err)
+	}
+	if len(tcMetadata.testCases) != 1 || tcMetadata.testCases[0].FuncName != "Clamp" {
+		t.Errorf("extractTestCases() found %+v; expected only the cases of Clamp", tcMetadata.testCases)
+	}
+}
+
+func TestExtractTestOnlyTestCases(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"math.go": `package p
+
+//go:generate leetcode-gen-test
+func clamp(x int) int { return min(x, 10) }
+
+//go:generate leetcode-gen-test
+func double(x int) int { return 2 * x }
+`,
+		// Test-only case files are compiled together, so they may share values
+		"math_testcase_test.go": `package p
+
+var basicClamp = testClampCase{input: testClampInput{x: large}, output: testClampOutput{field0: 10}}
+
+type testClampInput struct{ x int }
+type testClampOutput struct{ field0 int }
+type testClampCase struct {
+	name   string
+	input  testClampInput
+	output testClampOutput
+}
+`,
+		"double_testcase_test.go": "package p\n\nconst large = 11\n",
+		// Other tests of the package are still left out
+		"math_test.go": "package p\n\nvar broken = undefinedType{}\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tcMetadata, err := defaultGenerator.extractTestCases(filepath.Join(dir, "math_testcase_test.go"), []byte(files["math_testcase_test.go"]))
+	if err != nil {
+		t.Fatalf("extractTestCases() error = %v", err)
+	}
+	if len(tcMetadata.testCases) != 1 || tcMetadata.testCases[0].FuncName != "Clamp" {
+		t.Errorf("extractTestCases() found %+v; expected only the cases of Clamp", tcMetadata.testCases)
+	}
+
+	// Source files never see test-only case files
+	if _, err := defaultGenerator.extractTestFuncs(filepath.Join(dir, "math.go"), []byte(files["math.go"])); err != nil {
+		t.Errorf("extractTestFuncs() error = %v", err)
+	}
+}
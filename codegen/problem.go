@@ -0,0 +1,789 @@
+package codegen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"html"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Example is an example of a problem description, e.g.
+//
+//	Example 1:
+//	Input: nums = [2,7,11,15], target = 9
+//	Output: [0,1]
+type Example struct {
+	// Name is the heading of the example, e.g. "Example 1".
+	Name string
+	// Inputs are the named inputs of the example, in order. Inputs given
+	// without a name, as in "Input: [1,2,3]", have an empty name.
+	Inputs []ExampleValue
+	// Outputs are the outputs of the example, usually a single one without
+	// a name.
+	Outputs []ExampleValue
+}
+
+// ExampleValue is a named value of an example, spelled as in the problem
+// description, e.g. "[2,7,11,15]".
+type ExampleValue struct {
+	Name  string
+	Value string
+}
+
+var (
+	// htmlTagPattern matches the tags of HTML problem descriptions.
+	htmlTagPattern = regexp.MustCompile(`<[^>]*>`)
+	// htmlBreakPattern matches the tags of HTML problem descriptions that end
+	// a line.
+	htmlBreakPattern = regexp.MustCompile(`(?i)<br\s*/?>|</(p|div|pre|li|h[1-6])>`)
+	// exampleHeadingPattern matches the headings of examples.
+	exampleHeadingPattern = regexp.MustCompile(`(?m)^[\s#>*]*(Example\s*\d+)\s*:`)
+	// exampleEndPattern matches the sections following the last example.
+	exampleEndPattern = regexp.MustCompile(`(?m)^[\s#>*]*(Constraints|Follow[\s-]*up|Note)\b`)
+	// inputPattern, outputPattern and explanationPattern match the labels of
+	// the parts of an example.
+	inputPattern       = regexp.MustCompile(`Input\s*:`)
+	outputPattern      = regexp.MustCompile(`Output\s*:`)
+	explanationPattern = regexp.MustCompile(`Explanation\s*:`)
+	// namedValuePattern matches a value preceded by its name, e.g.
+	// "target = 9".
+	namedValuePattern = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)\s*=\s*`)
+)
+
+// ParseExamples extracts the examples of a problem description, saved from
+// LeetCode as HTML, Markdown or plain text. Each example starts with an
+// "Example N:" heading and holds an "Input:" and an "Output:" part, followed
+// by an optional "Explanation:". Inputs are split into their named values,
+// e.g. "nums = [2,7,11,15], target = 9" into nums and target.
+//
+// Parameters:
+//   - content: The problem description
+//
+// Returns:
+//   - []Example: The examples, in order
+//   - error: An error if the description has no examples, or an example
+//     lacks its input or output
+func ParseExamples(content []byte) ([]Example, error) {
+	text := plainTextOf(string(content))
+	headings := exampleHeadingPattern.FindAllStringSubmatchIndex(text, -1)
+	if len(headings) == 0 {
+		return nil, fmt.Errorf("no examples found")
+	}
+	// The last example ends with the sections following the examples
+	last := headings[len(headings)-1][1]
+	if loc := exampleEndPattern.FindStringIndex(text[last:]); loc != nil {
+		text = text[:last+loc[0]]
+	}
+	var examples []Example
+	for i, heading := range headings {
+		end := len(text)
+		if i+1 < len(headings) {
+			end = headings[i+1][0]
+		}
+		name := strings.Join(strings.Fields(text[heading[2]:heading[3]]), " ")
+		if !strings.Contains(name, " ") {
+			name = strings.Replace(name, "Example", "Example ", 1)
+		}
+		example, err := parseExample(name, text[heading[1]:end])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+		examples = append(examples, example)
+	}
+	return examples, nil
+}
+
+// plainTextOf strips the markup of a problem description: the tags and
+// entities of HTML, and the emphasis, code spans and code fences of
+// Markdown.
+func plainTextOf(s string) string {
+	if htmlTagPattern.MatchString(s) {
+		s = htmlBreakPattern.ReplaceAllString(s, "$0\n")
+		s = htmlTagPattern.ReplaceAllString(s, "")
+		s = html.UnescapeString(s)
+	}
+	s = strings.ReplaceAll(s, "```", "")
+	s = strings.ReplaceAll(s, "**", "")
+	s = strings.ReplaceAll(s, "`", "")
+	// Non-breaking spaces are common in saved pages
+	return strings.ReplaceAll(s, "\u00a0", " ")
+}
+
+// parseExample parses the text of an example following its heading.
+func parseExample(name, text string) (Example, error) {
+	input := inputPattern.FindStringIndex(text)
+	if input == nil {
+		return Example{}, fmt.Errorf("missing Input")
+	}
+	output := outputPattern.FindStringIndex(text[input[1]:])
+	if output == nil {
+		return Example{}, fmt.Errorf("missing Output")
+	}
+	inputText := text[input[1] : input[1]+output[0]]
+	outputText := text[input[1]+output[1]:]
+	if explanation := explanationPattern.FindStringIndex(outputText); explanation != nil {
+		outputText = outputText[:explanation[0]]
+	}
+
+	example := Example{Name: name}
+	var err error
+	if example.Inputs, err = splitNamedValues(inputText); err != nil {
+		return Example{}, fmt.Errorf("input: %v", err)
+	}
+	if example.Outputs, err = splitNamedValues(outputText); err != nil {
+		return Example{}, fmt.Errorf("output: %v", err)
+	}
+	if len(example.Inputs) == 0 {
+		return Example{}, fmt.Errorf("empty Input")
+	}
+	if len(example.Outputs) == 0 {
+		return Example{}, fmt.Errorf("empty Output")
+	}
+	return example, nil
+}
+
+// splitNamedValues splits a list of values at the commas outside brackets
+// and strings, e.g. "nums = [2,7], target = 9" into nums and target. A value
+// without a name continues the previous one, if any, so that unnamed lists
+// such as "1, 2" are kept whole.
+func splitNamedValues(s string) ([]ExampleValue, error) {
+	s = strings.Join(strings.Fields(s), " ")
+	parts, err := splitTopLevel(s)
+	if err != nil {
+		return nil, err
+	}
+	var values []ExampleValue
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if m := namedValuePattern.FindStringSubmatch(part); m != nil {
+			values = append(values, ExampleValue{Name: m[1], Value: strings.TrimSpace(part[len(m[0]):])})
+			continue
+		}
+		if len(values) > 0 && values[len(values)-1].Name != "" {
+			values[len(values)-1].Value += ", " + part
+			continue
+		}
+		values = append(values, ExampleValue{Value: part})
+	}
+	for _, v := range values {
+		if v.Value == "" {
+			return nil, fmt.Errorf("missing value of %s", v.Name)
+		}
+	}
+	return values, nil
+}
+
+// splitTopLevel splits s at the commas outside brackets and double-quoted
+// strings.
+func splitTopLevel(s string) ([]string, error) {
+	var (
+		parts    []string
+		depth    int
+		inString bool
+		start    int
+	)
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case inString && c == '\\':
+			i++
+		case c == '"':
+			inString = !inString
+		case inString:
+		case c == '[' || c == '{':
+			depth++
+		case c == ']' || c == '}':
+			depth--
+			if depth < 0 {
+				return nil, fmt.Errorf("unbalanced %c in %s", c, s)
+			}
+		case c == ',' && depth == 0:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	if inString || depth != 0 {
+		return nil, fmt.Errorf("unterminated value %s", s)
+	}
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	return append(parts, s[start:]), nil
+}
+
+// jsonValue is a value of an example, which LeetCode spells in JSON.
+type jsonValue struct {
+	kind jsonKind
+	// text is the number or the unquoted string.
+	text  string
+	elems []jsonValue
+}
+
+type jsonKind int
+
+const (
+	jsonNull jsonKind = iota
+	jsonBool
+	jsonNumber
+	jsonString
+	jsonList
+)
+
+// parseJSONValue parses a value of an example. Besides JSON, it accepts
+// single-quoted characters, as in ['a','b'].
+func parseJSONValue(s string) (jsonValue, error) {
+	p := &jsonParser{s: s}
+	v, err := p.value()
+	if err != nil {
+		return jsonValue{}, err
+	}
+	if p.skipSpace(); p.i < len(p.s) {
+		return jsonValue{}, fmt.Errorf("unexpected %q after the value", p.s[p.i:])
+	}
+	return v, nil
+}
+
+// jsonParser parses a value of an example from its text.
+type jsonParser struct {
+	s string
+	i int
+}
+
+func (p *jsonParser) skipSpace() {
+	for p.i < len(p.s) && (p.s[p.i] == ' ' || p.s[p.i] == '\t' || p.s[p.i] == '\n' || p.s[p.i] == '\r') {
+		p.i++
+	}
+}
+
+func (p *jsonParser) value() (jsonValue, error) {
+	p.skipSpace()
+	if p.i == len(p.s) {
+		return jsonValue{}, fmt.Errorf("missing value")
+	}
+	switch c := p.s[p.i]; {
+	case c == '[':
+		p.i++
+		v := jsonValue{kind: jsonList}
+		if p.skipSpace(); p.i < len(p.s) && p.s[p.i] == ']' {
+			p.i++
+			return v, nil
+		}
+		for {
+			elem, err := p.value()
+			if err != nil {
+				return jsonValue{}, err
+			}
+			v.elems = append(v.elems, elem)
+			p.skipSpace()
+			if p.i == len(p.s) {
+				return jsonValue{}, fmt.Errorf("missing ]")
+			}
+			p.i++
+			switch p.s[p.i-1] {
+			case ']':
+				return v, nil
+			case ',':
+			default:
+				return jsonValue{}, fmt.Errorf("expected , or ] at %q", p.s[p.i-1:])
+			}
+		}
+	case c == '"' || c == '\'':
+		end := p.i + 1
+		for end < len(p.s) && p.s[end] != c {
+			if p.s[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		if end >= len(p.s) {
+			return jsonValue{}, fmt.Errorf("unterminated string %s", p.s[p.i:])
+		}
+		raw := p.s[p.i+1 : end]
+		p.i = end + 1
+		text, err := strconv.Unquote(`"` + strings.ReplaceAll(raw, `\'`, `'`) + `"`)
+		if err != nil {
+			return jsonValue{}, fmt.Errorf("invalid string %s", raw)
+		}
+		return jsonValue{kind: jsonString, text: text}, nil
+	}
+
+	start := p.i
+	for p.i < len(p.s) && !strings.ContainsRune(" \t\r\n,]", rune(p.s[p.i])) {
+		p.i++
+	}
+	word := p.s[start:p.i]
+	switch word {
+	case "null":
+		return jsonValue{kind: jsonNull}, nil
+	case "true", "false":
+		return jsonValue{kind: jsonBool, text: word}, nil
+	}
+	if _, err := strconv.ParseFloat(word, 64); err != nil {
+		return jsonValue{}, fmt.Errorf("unexpected %q", word)
+	}
+	return jsonValue{kind: jsonNumber, text: word}, nil
+}
+
+// compact spells the value in JSON without spaces, as LeetCode notation
+// does, e.g. "[1,null,2]".
+func (v jsonValue) compact() string {
+	switch v.kind {
+	case jsonNull:
+		return "null"
+	case jsonString:
+		return strconv.Quote(v.text)
+	case jsonList:
+		elems := make([]string, len(v.elems))
+		for i, elem := range v.elems {
+			elems[i] = elem.compact()
+		}
+		return "[" + strings.Join(elems, ",") + "]"
+	}
+	return v.text
+}
+
+// goLiteralOf converts a value of an example to a Go expression of a type.
+// The type of composite literals is spelled as typeExpr, or elided if it is
+// empty, as it may be in the elements of a slice.
+//
+// Parameters:
+//   - v: The value
+//   - t: The type of the expression
+//   - typeExpr: The type as spelled in the test case file, or empty
+//
+// Returns:
+//   - string: The Go expression
+//   - error: An error if the value does not fit the type
+func goLiteralOf(v jsonValue, t types.Type, typeExpr string) (string, error) {
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		switch {
+		case u.Info()&types.IsBoolean != 0 && v.kind == jsonBool:
+			return v.text, nil
+		case u.Info()&types.IsString != 0 && v.kind == jsonString:
+			return strconv.Quote(v.text), nil
+		case u.Info()&types.IsInteger != 0 && v.kind == jsonString && (u.Kind() == types.Uint8 || u.Kind() == types.Int32):
+			// Characters, as in char[] s = ["h","e"]
+			if r := []rune(v.text); len(r) == 1 {
+				return strconv.QuoteRune(r[0]), nil
+			}
+		case u.Info()&types.IsInteger != 0 && v.kind == jsonNumber:
+			if isInteger(v.text) {
+				return v.text, nil
+			}
+		case u.Info()&types.IsFloat != 0 && v.kind == jsonNumber:
+			return v.text, nil
+		}
+	case *types.Slice, *types.Array:
+		if v.kind == jsonNull {
+			if _, ok := u.(*types.Slice); ok {
+				return "nil", nil
+			}
+			break
+		}
+		if v.kind != jsonList {
+			break
+		}
+		elem := u.(interface{ Elem() types.Type }).Elem()
+		if array, ok := u.(*types.Array); ok && int64(len(v.elems)) > array.Len() {
+			return "", fmt.Errorf("%s has more than %d elements", v.compact(), array.Len())
+		}
+		elems := make([]string, len(v.elems))
+		for i, e := range v.elems {
+			lit, err := goLiteralOf(e, elem, "")
+			if err != nil {
+				return "", err
+			}
+			elems[i] = lit
+		}
+		return typeExpr + "{" + strings.Join(elems, ", ") + "}", nil
+	case *types.Pointer, *types.Map, *types.Interface, *types.Signature, *types.Chan:
+		if v.kind == jsonNull {
+			return "nil", nil
+		}
+	}
+	if _, ok := t.(*types.TypeParam); ok {
+		return "", fmt.Errorf("cannot convert %s to type parameter %s", v.compact(), t)
+	}
+	return "", fmt.Errorf("cannot convert %s to %s", v.compact(), types.TypeString(t, func(p *types.Package) string { return p.Name() }))
+}
+
+// isInteger reports whether a number is spelled as an integer, which Go
+// constants hold exactly whatever its size.
+func isInteger(s string) bool {
+	s = strings.TrimLeft(s, "+-")
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// ImportExamples adds the examples of a problem description to the test case
+// file of a source file as test<Func>Case variables, named after the
+// examples, e.g. example1TwoSum. The inputs of the examples are matched with
+// the parameters of the function by name, or else by position, and their
+// values converted to Go literals of the parameter types; values spelled in
+// LeetCode notation are kept as strings. The test case file gets the missing
+// test case templates first, and examples whose variables it declares
+// already are left out, so that importing again adds nothing.
+//
+// Parameters:
+//   - srcFile: The path of the source file
+//   - srcContent: The content of the source file
+//   - testCaseFile: The path of the test case file
+//   - testCaseContent: The content of the test case file, or nil to create it
+//   - funcName: The tagged function the examples are cases of, as declared
+//     or qualified with its receiver type, e.g. "Solution.twoSum", or empty
+//     for the one whose parameters the inputs of the examples name
+//   - examples: The examples, see ParseExamples
+//
+// Returns:
+//   - []byte: The test case file with the cases of the examples
+//   - []string: The names of the variables added
+//   - []string: A description of each example that could not be imported,
+//     prefixed with its name
+//   - Diagnostics: Warnings about type errors in the source package that did
+//     not prevent the import
+//   - error: An error if either file cannot be processed or no function
+//     matches the examples
+func (g *Generator) ImportExamples(srcFile string, srcContent []byte, testCaseFile string, testCaseContent []byte, funcName string, examples []Example) ([]byte, []string, []string, Diagnostics, error) {
+	tfMetadata, err := g.extractTestFuncs(srcFile, srcContent)
+	if err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("extracting test function: %w", err)
+	}
+	tf, err := exampleFuncOf(tfMetadata.testFuncs, funcName, examples)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	// Start from a test case file covering the function
+	var warnings Diagnostics
+	if testCaseContent == nil {
+		testCaseContent, warnings, err = g.GenerateTestCaseTemplates(srcFile, srcContent)
+	} else {
+		testCaseContent, _, warnings, err = g.MergeTestCaseTemplates(srcFile, srcContent, testCaseFile, testCaseContent)
+	}
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, testCaseFile, testCaseContent, parser.ParseComments)
+	if err != nil {
+		return nil, nil, nil, nil, DiagnosticsOf(err)
+	}
+	declared := make(map[string]bool)
+	for _, decl := range f.Decls {
+		if genDecl, ok := decl.(*ast.GenDecl); ok && genDecl.Tok == token.VAR {
+			for _, spec := range genDecl.Specs {
+				for _, name := range spec.(*ast.ValueSpec).Names {
+					declared[name.Name] = true
+				}
+			}
+		}
+	}
+
+	s := g.schemaOf(tf)
+	var (
+		added    []string
+		problems []string
+		imports  []string
+		cases    strings.Builder
+	)
+	for _, example := range examples {
+		name := exampleVarNameOf(example.Name, s.caseName)
+		if declared[name] {
+			continue
+		}
+		code, fields, err := importedCaseOf(tf, s, example)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", example.Name, err))
+			continue
+		}
+		declared[name] = true
+		added = append(added, name)
+		for _, f := range fields {
+			imports = append(imports, f.imports...)
+		}
+		cases.WriteString("\t" + name + " = " + code + "\n")
+	}
+	if len(added) == 0 {
+		return testCaseContent, nil, problems, warnings, nil
+	}
+
+	content := insertCases(fset, f, string(testCaseContent), s, cases.String())
+	content = addImports(fset, f, content, uniqueSorted(imports))
+	formatted, err := format.Source([]byte(content))
+	if err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("formatting imported test cases: %v", err)
+	}
+	return formatted, added, problems, warnings, nil
+}
+
+// ImportExamples adds the examples of a problem description to a test case
+// file with the default options, see Generator.ImportExamples.
+func ImportExamples(srcFile string, srcContent []byte, testCaseFile string, testCaseContent []byte, funcName string, examples []Example) ([]byte, []string, []string, Diagnostics, error) {
+	return defaultGenerator.ImportExamples(srcFile, srcContent, testCaseFile, testCaseContent, funcName, examples)
+}
+
+// exampleFuncOf picks the tagged function that examples are cases of: the
+// one named funcName, or else the only function, or else the only one whose
+// parameters include the names of all inputs.
+func exampleFuncOf(testFuncs []testFuncData, funcName string, examples []Example) (testFuncData, error) {
+	var names []string
+	for _, tf := range testFuncs {
+		names = append(names, tf.displayName())
+	}
+	sort.Strings(names)
+
+	if funcName != "" {
+		for _, tf := range testFuncs {
+			if funcName == tf.FuncName || funcName == tf.displayName() || funcName == tf.CaseName() {
+				if len(tf.Generics) > 0 {
+					return testFuncData{}, fmt.Errorf("%s is generic, its cases must be written by hand", tf.displayName())
+				}
+				return tf, nil
+			}
+		}
+		return testFuncData{}, fmt.Errorf("no tagged function named %s, expected one of: %s", funcName, strings.Join(names, ", "))
+	}
+
+	var matches []testFuncData
+	for _, tf := range testFuncs {
+		if len(tf.Generics) > 0 {
+			continue
+		}
+		params := make(map[string]bool)
+		for _, p := range tf.Params {
+			params[p.Name] = true
+		}
+		matched := true
+		for _, example := range examples {
+			for _, input := range example.Inputs {
+				matched = matched && params[input.Name]
+			}
+		}
+		if matched || len(testFuncs) == 1 {
+			matches = append(matches, tf)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		if len(testFuncs) == 0 {
+			return testFuncData{}, fmt.Errorf("no tagged functions")
+		}
+		return testFuncData{}, fmt.Errorf("no tagged function has the parameters of the examples, choose one of: %s", strings.Join(names, ", "))
+	case 1:
+		return matches[0], nil
+	}
+	var matched []string
+	for _, tf := range matches {
+		matched = append(matched, tf.displayName())
+	}
+	sort.Strings(matched)
+	return testFuncData{}, fmt.Errorf("several tagged functions have the parameters of the examples, choose one of: %s", strings.Join(matched, ", "))
+}
+
+// exampleVarNameOf names the variable of the case of an example, e.g.
+// "example1TwoSum" for "Example 1" of TwoSum.
+func exampleVarNameOf(exampleName, caseName string) string {
+	var sb strings.Builder
+	for _, r := range exampleName {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			sb.WriteRune(r)
+		}
+	}
+	name := sb.String()
+	if name == "" {
+		name = "example"
+	}
+	return strings.ToLower(name[:1]) + name[1:] + caseName
+}
+
+// importedCaseOf spells the test case of an example as a composite literal.
+//
+// Parameters:
+//   - tf: The function the example is a case of
+//   - s: The schema of its test cases
+//   - example: The example
+//
+// Returns:
+//   - string: The composite literal
+//   - []fieldInfo: The fields set by the literal
+//   - error: An error if the values of the example do not match the
+//     parameters and outputs of the function
+func importedCaseOf(tf testFuncData, s caseSchema, example Example) (string, []fieldInfo, error) {
+	inputs, err := matchExampleValues(tf.Params, example.Inputs, "input")
+	if err != nil {
+		return "", nil, err
+	}
+	outputs, err := matchExampleValues(tf.Outputs(), example.Outputs, "output")
+	if err != nil {
+		return "", nil, err
+	}
+
+	var sb strings.Builder
+	sb.WriteString(s.typeName + "{\n")
+	sb.WriteString("\t\t" + nameAttrName + ": " + strconv.Quote(example.Name) + ",\n")
+	var used []fieldInfo
+	for _, attr := range []struct {
+		name     string
+		typeName string
+		fields   []fieldInfo
+		values   []string
+	}{
+		{inputAttrName, s.inputTypeName, tf.Params, inputs},
+		{outputAttrName, s.outputTypeName, tf.Outputs(), outputs},
+	} {
+		sb.WriteString("\t\t" + attr.name + ": " + attr.typeName + "{\n")
+		for i, field := range attr.fields {
+			v, err := parseJSONValue(attr.values[i])
+			if err != nil {
+				return "", nil, fmt.Errorf("%s %s: %v", attr.name, field.Name, err)
+			}
+			var lit string
+			if field.Notation() {
+				lit = strconv.Quote(v.compact())
+			} else if lit, err = goLiteralOf(v, field.typ, field.Type); err != nil {
+				return "", nil, fmt.Errorf("%s %s: %v", attr.name, field.Name, err)
+			} else {
+				used = append(used, field)
+			}
+			sb.WriteString("\t\t\t" + field.Name + ": " + lit + ",\n")
+		}
+		sb.WriteString("\t\t},\n")
+	}
+	sb.WriteString("\t}")
+	return sb.String(), used, nil
+}
+
+// matchExampleValues matches the values of an example with the fields they
+// set: by name, or else by position for the fields no value names. A single
+// unnamed output sets the only output field.
+func matchExampleValues(fields []fieldInfo, values []ExampleValue, what string) ([]string, error) {
+	if len(values) != len(fields) {
+		return nil, fmt.Errorf("expected %d %ss, the example has %d", len(fields), what, len(values))
+	}
+
+	matched := make([]string, len(fields))
+	used := make([]bool, len(values))
+	for i, field := range fields {
+		for j, v := range values {
+			if !used[j] && v.Name == field.Name {
+				matched[i], used[j] = v.Value, true
+				break
+			}
+		}
+	}
+	for i := range fields {
+		if matched[i] != "" {
+			continue
+		}
+		for j, v := range values {
+			if !used[j] {
+				matched[i], used[j] = v.Value, true
+				break
+			}
+		}
+	}
+	return matched, nil
+}
+
+// insertCases inserts the declarations of cases into a test case file: after
+// the last case of the function, or else at the top of the variable block of
+// its test case template, or else in a new variable block following the
+// template.
+//
+// Parameters:
+//   - fset: The file set the file was parsed into
+//   - f: The parsed file
+//   - content: The content the file was parsed from
+//   - s: The schema of the test cases of the function
+//   - cases: The declarations, one per line, indented to be held in a
+//     variable block
+//
+// Returns:
+//   - string: The content with the cases
+func insertCases(fset *token.FileSet, f *ast.File, content string, s caseSchema, cases string) string {
+	offset := func(pos token.Pos) int { return fset.Position(pos).Offset }
+	insertAt := func(at int, text string) string { return content[:at] + text + content[at:] }
+
+	var (
+		lastCase     *ast.ValueSpec
+		lastCaseDecl *ast.GenDecl
+		templateDecl *ast.GenDecl
+		templateEnd  token.Pos
+	)
+	for i, decl := range f.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok {
+			continue
+		}
+		switch genDecl.Tok {
+		case token.VAR:
+			for _, spec := range genDecl.Specs {
+				vs := spec.(*ast.ValueSpec)
+				for _, value := range vs.Values {
+					if lit, ok := value.(*ast.CompositeLit); ok && caseTypeNameOf(lit.Type) == s.typeName {
+						lastCase, lastCaseDecl = vs, genDecl
+					}
+				}
+			}
+		case token.TYPE:
+			for _, spec := range genDecl.Specs {
+				name := spec.(*ast.TypeSpec).Name.Name
+				if name != s.typeName && name != s.inputTypeName && name != s.outputTypeName {
+					continue
+				}
+				templateEnd = max(templateEnd, genDecl.End())
+				// The variable block of a template precedes its types
+				if templateDecl == nil && i > 0 {
+					if prev, ok := f.Decls[i-1].(*ast.GenDecl); ok && prev.Tok == token.VAR && prev.Lparen.IsValid() {
+						templateDecl = prev
+					}
+				}
+			}
+		}
+	}
+
+	switch {
+	case lastCase != nil && lastCaseDecl.Lparen.IsValid():
+		return insertAt(offset(lastCase.End()), "\n"+strings.TrimSuffix(cases, "\n"))
+	case lastCase != nil:
+		return insertAt(offset(lastCaseDecl.End()), "\n\nvar (\n"+cases+")")
+	case templateDecl != nil:
+		return insertAt(offset(templateDecl.Lparen)+1, "\n"+cases)
+	case templateEnd.IsValid():
+		return insertAt(offset(templateEnd), "\n\nvar (\n"+cases+")")
+	}
+	if !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	return content + "\nvar (\n" + cases + ")\n"
+}
+
+// caseTypeNameOf returns the name of the type of a composite literal, e.g.
+// "testMaxCase" for testMaxCase[int]{...}, or empty if it is not named.
+func caseTypeNameOf(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return e.Name
+	case *ast.IndexExpr:
+		return caseTypeNameOf(e.X)
+	case *ast.IndexListExpr:
+		return caseTypeNameOf(e.X)
+	}
+	return ""
+}
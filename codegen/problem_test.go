@@ -0,0 +1,176 @@
+package codegen
+
+import (
+	"go/types"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestParseExamples(t *testing.T) {
+	twoSum := []Example{
+		{Name: "Example 1", Inputs: []ExampleValue{{"nums", "[2,7,11,15]"}, {"target", "9"}}, Outputs: []ExampleValue{{"", "[0,1]"}}},
+		{Name: "Example 2", Inputs: []ExampleValue{{"nums", "[3,2,4]"}, {"target", "6"}}, Outputs: []ExampleValue{{"", "[1,2]"}}},
+	}
+	tests := []struct {
+		name     string
+		content  string
+		expected []Example
+	}{
+		{"html", `<p>Return <em>indices</em>.</p>
+<p><strong class="example">Example 1:</strong></p>
+<pre>
+<strong>Input:</strong> nums = [2,7,11,15], target = 9
+<strong>Output:</strong> [0,1]
+<strong>Explanation:</strong> Because nums[0] + nums[1] == 9, we return [0, 1].
+</pre>
+<p><strong class="example">Example 2:</strong></p>
+<div class="example-block"><p><strong>Input:</strong> <span class="example-io">nums = [3,2,4], target&nbsp;= 6</span></p>
+<p><strong>Output:</strong> <span class="example-io">[1,2]</span></p></div>
+<p><strong>Constraints:</strong></p>
+<ul><li><code>2 &lt;= nums.length</code></li></ul>`, twoSum},
+		{"markdown", "**Example 1:**\n\n```\nInput: nums = [2,7,11,15], target = 9\nOutput: [0,1]\n```\n\n" +
+			"**Example 2:**\n\n```\nInput: nums = [3,2,4], target = 6\nOutput: [1,2]\n```\n\n**Constraints:**\n\n- `2 <= nums.length`\n", twoSum},
+		{"text", "Example 1:\nInput: s = \"a, b = c\", k = 2\nOutput: \"ab\"\nExample2 :\nInput: [1, 2]\nOutput: 1, nums = [1,_]\n", []Example{
+			{Name: "Example 1", Inputs: []ExampleValue{{"s", `"a, b = c"`}, {"k", "2"}}, Outputs: []ExampleValue{{"", `"ab"`}}},
+			{Name: "Example 2", Inputs: []ExampleValue{{"", "[1, 2]"}}, Outputs: []ExampleValue{{"", "1"}, {"nums", "[1,_]"}}},
+		}},
+	}
+
+	for _, test := range tests {
+		result, err := ParseExamples([]byte(test.content))
+		if err != nil {
+			t.Errorf("ParseExamples(%s) error = %v", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(result, test.expected) {
+			t.Errorf("ParseExamples(%s) = %+v; expected %+v", test.name, result, test.expected)
+		}
+	}
+
+	for _, content := range []string{"no examples", "Example 1:\nOutput: 1\n", "Example 1:\nInput: a = [1\nOutput: 1\n"} {
+		if _, err := ParseExamples([]byte(content)); err == nil {
+			t.Errorf("ParseExamples(%q) succeeded; expected an error", content)
+		}
+	}
+}
+
+func TestGoLiteralOf(t *testing.T) {
+	ints := types.NewSlice(types.Typ[types.Int])
+	tests := []struct {
+		value    string
+		typ      types.Type
+		typeExpr string
+		expected string
+	}{
+		{"-5", types.Typ[types.Int], "", "-5"},
+		{"2.50000", types.Typ[types.Float64], "", "2.50000"},
+		{"true", types.Typ[types.Bool], "", "true"},
+		{`"a\"b"`, types.Typ[types.String], "", `"a\"b"`},
+		{`"x"`, types.Typ[types.Byte], "", "'x'"},
+		{"[1, 2,3]", ints, "[]int", "[]int{1, 2, 3}"},
+		{"[[1],[]]", types.NewSlice(ints), "[][]int", "[][]int{{1}, {}}"},
+		{`[['a','b']]`, types.NewSlice(types.NewSlice(types.Typ[types.Rune])), "[][]rune", "[][]rune{{'a', 'b'}}"},
+		{"null", ints, "[]int", "nil"},
+		{"1.5", types.Typ[types.Int], "", ""},
+		{`"ab"`, types.Typ[types.Byte], "", ""},
+		{"[1,2]", types.NewArray(types.Typ[types.Int], 1), "[1]int", ""},
+		{"1", types.NewMap(types.Typ[types.Int], types.Typ[types.Int]), "map[int]int", ""},
+	}
+
+	for _, test := range tests {
+		v, err := parseJSONValue(test.value)
+		if err != nil {
+			t.Errorf("parseJSONValue(%s) error = %v", test.value, err)
+			continue
+		}
+		result, err := goLiteralOf(v, test.typ, test.typeExpr)
+		if test.expected == "" {
+			if err == nil {
+				t.Errorf("goLiteralOf(%s, %s) = %s; expected an error", test.value, test.typ, result)
+			}
+			continue
+		}
+		if err != nil || result != test.expected {
+			t.Errorf("goLiteralOf(%s, %s) = %s, %v; expected %s", test.value, test.typ, result, err, test.expected)
+		}
+	}
+}
+
+func TestImportExamples(t *testing.T) {
+	src := []byte(`package p
+
+type ListNode struct {
+	Val  int
+	Next *ListNode
+}
+
+//leetcode:test
+func addTwo(a []int, b int) []int { return a }
+
+//leetcode:test
+func reverseList(head *ListNode) *ListNode { return head }
+`)
+	g, err := NewGenerator(Options{FS: fstest.MapFS{"p/sol.go": {Data: src}}})
+	if err != nil {
+		t.Fatalf("NewGenerator() error = %v", err)
+	}
+	examples := []Example{
+		{Name: "Example 1", Inputs: []ExampleValue{{"a", "[1,2]"}, {"b", "3"}}, Outputs: []ExampleValue{{"", "[4,5]"}}},
+		{Name: "Example 2", Inputs: []ExampleValue{{"a", "[]"}, {"b", "x"}}, Outputs: []ExampleValue{{"", "[]"}}},
+	}
+
+	content, added, problems, _, err := g.ImportExamples("p/sol.go", src, "p/sol_testcase.go", nil, "", examples)
+	if err != nil {
+		t.Fatalf("ImportExamples() error = %v", err)
+	}
+	if strings.Join(added, " ") != "example1AddTwo" {
+		t.Errorf("ImportExamples() added %v; expected example1AddTwo", added)
+	}
+	if len(problems) != 1 || !strings.HasPrefix(problems[0], `Example 2: input b: unexpected "x"`) {
+		t.Errorf("ImportExamples() problems = %q", problems)
+	}
+	expected := `var (
+	example1AddTwo = testAddTwoCase{
+		name: "Example 1",
+		input: testAddTwoInput{
+			a: []int{1, 2},
+			b: 3,
+		},
+		output: testAddTwoOutput{
+			field0: []int{4, 5},
+		},
+	}
+
+/*`
+	if !strings.Contains(string(content), expected) {
+		t.Errorf("ImportExamples() = %s; expected it to contain %s", content, expected)
+	}
+
+	// Importing again adds nothing, while the cases of another function
+	// follow their own template
+	examples[1].Inputs[1].Value = "0"
+	content, added, problems, _, err = g.ImportExamples("p/sol.go", src, "p/sol_testcase.go", content, "addTwo", examples)
+	if err != nil || len(problems) > 0 || strings.Join(added, " ") != "example2AddTwo" {
+		t.Fatalf("ImportExamples() again = %v, %q, %v; expected example2AddTwo", added, problems, err)
+	}
+	if !strings.Contains(string(content), "\t}\n\texample2AddTwo = testAddTwoCase{") {
+		t.Errorf("ImportExamples() did not add the case after the others:\n%s", content)
+	}
+	lists := []Example{{Name: "Example 1", Inputs: []ExampleValue{{"head", "[1, 2]"}}, Outputs: []ExampleValue{{"", "[2,1]"}}}}
+	content, _, _, _, err = g.ImportExamples("p/sol.go", src, "p/sol_testcase.go", content, "reverseList", lists)
+	if err != nil {
+		t.Fatalf("ImportExamples(reverseList) error = %v", err)
+	}
+	if !strings.Contains(string(content), `head: "[1,2]",`) || !strings.Contains(string(content), `field0: "[2,1]",`) {
+		t.Errorf("ImportExamples(reverseList) did not spell the lists in LeetCode notation:\n%s", content)
+	}
+
+	for _, funcName := range []string{"", "nope"} {
+		unnamed := []Example{{Name: "Example 1", Inputs: []ExampleValue{{"x", "1"}}, Outputs: []ExampleValue{{"", "1"}}}}
+		if _, _, _, _, err := g.ImportExamples("p/sol.go", src, "p/sol_testcase.go", nil, funcName, unnamed); err == nil {
+			t.Errorf("ImportExamples(%q) succeeded; expected an error", funcName)
+		}
+	}
+}
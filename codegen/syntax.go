@@ -0,0 +1,344 @@
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// Dimensions of the synthetic file that layout hands out positions in.
+const (
+	// layoutLineWidth is the number of offsets per line; it exceeds the
+	// length of any generated line, so that go/printer never mistakes the
+	// end of a line for the start of the next one.
+	layoutLineWidth = 1 << 12
+	// layoutSize bounds the size of the synthetic file, and with it the
+	// number of lines of a generated file.
+	layoutSize = 1 << 30
+)
+
+// layout hands out the positions of generated syntax trees. The positions
+// belong to a synthetic file in which every call to next starts a new line,
+// so that go/printer breaks lines, leaves blank lines and places comments
+// where the generator asks for them, as it would for code written by hand.
+//
+// Nodes must be laid out in the order they are printed in; tokens without a
+// position continue the line of the token before them.
+type layout struct {
+	fset     *token.FileSet
+	file     *token.File
+	line     int
+	comments []*ast.CommentGroup
+}
+
+// newLayout returns a layout starting on an empty file.
+func newLayout() *layout {
+	fset := token.NewFileSet()
+	return &layout{fset: fset, file: fset.AddFile("", -1, layoutSize)}
+}
+
+// next starts a new line and returns the position at its start.
+func (l *layout) next() token.Pos {
+	l.line++
+	offset := l.line * layoutLineWidth
+	l.file.AddLine(offset)
+	return l.file.Pos(offset)
+}
+
+// blank leaves an empty line.
+func (l *layout) blank() {
+	l.next()
+}
+
+// comment adds a group of line comments, one per line of text, starting on
+// a new line. The group is returned to serve as a doc comment.
+func (l *layout) comment(lines ...string) *ast.CommentGroup {
+	group := &ast.CommentGroup{}
+	for _, line := range lines {
+		text := "//"
+		if line != "" {
+			text += " " + strings.ReplaceAll(line, "\n", " ")
+		}
+		group.List = append(group.List, &ast.Comment{Slash: l.next(), Text: text})
+	}
+	l.comments = append(l.comments, group)
+	return group
+}
+
+// verbatim adds a comment written out in full on a new line, such as a
+// directive, e.g. "//go:generate ...".
+func (l *layout) verbatim(text string) *ast.CommentGroup {
+	group := &ast.CommentGroup{List: []*ast.Comment{{Slash: l.next(), Text: text}}}
+	l.comments = append(l.comments, group)
+	return group
+}
+
+// trailingComment adds a line comment at the end of the current line.
+func (l *layout) trailingComment(text string) *ast.CommentGroup {
+	pos := l.file.Pos(l.line*layoutLineWidth + layoutLineWidth/2)
+	group := &ast.CommentGroup{List: []*ast.Comment{{Slash: pos, Text: "// " + strings.ReplaceAll(text, "\n", " ")}}}
+	l.comments = append(l.comments, group)
+	return group
+}
+
+// blockComment adds a block comment holding the lines of code, starting on
+// a new line and taking as many lines as the code does.
+func (l *layout) blockComment(code string) {
+	pos := l.next()
+	for range strings.Count(code, "\n") + 2 {
+		l.next()
+	}
+	text := "/*\n" + strings.ReplaceAll(code, "*/", "* /") + "\n*/"
+	l.comments = append(l.comments, &ast.CommentGroup{List: []*ast.Comment{{Slash: pos, Text: text}}})
+}
+
+// block returns a block holding statements, opening on the line of pos and
+// closing on a new line after the statements.
+func (l *layout) block(pos token.Pos, stmts ...ast.Stmt) *ast.BlockStmt {
+	return &ast.BlockStmt{Lbrace: pos, List: stmts, Rbrace: l.next()}
+}
+
+// print prints a syntax tree laid out by l in the canonical gofmt style,
+// along with the comments that fall within it, including its doc comment.
+//
+// Parameters:
+//   - node: A file, declaration, statement or expression
+//
+// Returns:
+//   - []byte: The printed code
+//   - error: An error if the tree cannot be printed
+func (l *layout) print(node ast.Node) ([]byte, error) {
+	var buf bytes.Buffer
+	config := printer.Config{Mode: printer.UseSpaces | printer.TabIndent, Tabwidth: 8}
+	if f, ok := node.(*ast.File); ok {
+		f.Comments = l.comments
+		if err := config.Fprint(&buf, l.fset, f); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+	if err := config.Fprint(&buf, l.fset, &printer.CommentedNode{Node: node, Comments: l.comments}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// printDecls prints declarations laid out by l one after another, separated
+// by blank lines.
+func (l *layout) printDecls(decls []ast.Decl) ([]byte, error) {
+	var buf bytes.Buffer
+	for i, decl := range decls {
+		code, err := l.print(decl)
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			buf.WriteString("\n\n")
+		}
+		buf.Write(code)
+	}
+	buf.WriteString("\n")
+	return buf.Bytes(), nil
+}
+
+// render prints a syntax tree without positions on a single line, e.g. for
+// spelling code in comments.
+func render(node ast.Node) string {
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, token.NewFileSet(), node); err != nil {
+		return ""
+	}
+	return buf.String()
+}
+
+// exprAt parses a Go expression, such as a type spelled by the extractor,
+// and places all its tokens at pos, as if it were written on a single line.
+//
+// Parameters:
+//   - pos: The position of the line the expression is printed on, or
+//     token.NoPos to continue the line of the tokens before it
+//   - s: The expression
+//
+// Returns:
+//   - ast.Expr: The parsed expression
+//   - error: An error if s is not a valid expression
+func exprAt(pos token.Pos, s string) (ast.Expr, error) {
+	expr, err := parser.ParseExpr(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression %q", s)
+	}
+	ast.Inspect(expr, func(n ast.Node) bool {
+		if n != nil {
+			setPositions(reflect.ValueOf(n).Elem(), pos)
+		}
+		return true
+	})
+	return expr, nil
+}
+
+// tokenPosType is the type of the position fields of syntax tree nodes.
+var tokenPosType = reflect.TypeOf(token.NoPos)
+
+// setPositions sets all the position fields of a node to pos.
+func setPositions(v reflect.Value, pos token.Pos) {
+	if v.Kind() != reflect.Struct {
+		return
+	}
+	for i := range v.NumField() {
+		if field := v.Field(i); field.Type() == tokenPosType && field.CanSet() {
+			field.Set(reflect.ValueOf(pos))
+		}
+	}
+}
+
+// at places the leftmost token of a node at pos, so that the node starts
+// the line pos belongs to. Nodes that start with a token other than those
+// generated by this package are left alone.
+func at[N ast.Node](pos token.Pos, node N) N {
+	var n ast.Node = node
+	for {
+		switch x := n.(type) {
+		case *ast.Ident:
+			x.NamePos = pos
+		case *ast.BasicLit:
+			x.ValuePos = pos
+		case *ast.SelectorExpr:
+			n = x.X
+			continue
+		case *ast.CallExpr:
+			n = x.Fun
+			continue
+		case *ast.IndexExpr:
+			n = x.X
+			continue
+		case *ast.IndexListExpr:
+			n = x.X
+			continue
+		case *ast.CompositeLit:
+			if x.Type != nil {
+				n = x.Type
+				continue
+			}
+			x.Lbrace = pos
+		case *ast.KeyValueExpr:
+			n = x.Key
+			continue
+		case *ast.UnaryExpr:
+			x.OpPos = pos
+		case *ast.BinaryExpr:
+			n = x.X
+			continue
+		case *ast.StarExpr:
+			x.Star = pos
+		case *ast.ExprStmt:
+			n = x.X
+			continue
+		case *ast.AssignStmt:
+			n = x.Lhs[0]
+			continue
+		case *ast.IfStmt:
+			x.If = pos
+		case *ast.RangeStmt:
+			x.For = pos
+		case *ast.SwitchStmt:
+			x.Switch = pos
+		case *ast.CaseClause:
+			x.Case = pos
+		case *ast.DeferStmt:
+			x.Defer = pos
+		case *ast.DeclStmt:
+			n = x.Decl
+			continue
+		case *ast.GenDecl:
+			x.TokPos = pos
+		case *ast.FuncDecl:
+			x.Type.Func = pos
+		case *ast.Field:
+			if len(x.Names) > 0 {
+				n = x.Names[0]
+			} else {
+				n = x.Type
+			}
+			continue
+		}
+		return node
+	}
+}
+
+// ident returns an identifier without a position.
+func ident(name string) *ast.Ident {
+	return ast.NewIdent(name)
+}
+
+// sel returns the selector expression x.name1.name2...
+func sel(x ast.Expr, names ...string) ast.Expr {
+	for _, name := range names {
+		x = &ast.SelectorExpr{X: x, Sel: ident(name)}
+	}
+	return x
+}
+
+// call returns the call expression fun(args...).
+func call(fun ast.Expr, args ...ast.Expr) *ast.CallExpr {
+	return &ast.CallExpr{Fun: fun, Args: args}
+}
+
+// lctestCall returns a call of a runtime helper, lctest.name(args...).
+func lctestCall(name string, args ...ast.Expr) *ast.CallExpr {
+	return call(sel(ident("lctest"), name), args...)
+}
+
+// instantiate returns the generic type or function x instantiated with the
+// type arguments, or x itself if there are none.
+func instantiate(x ast.Expr, typeArgs ...ast.Expr) ast.Expr {
+	switch len(typeArgs) {
+	case 0:
+		return x
+	case 1:
+		return &ast.IndexExpr{X: x, Index: typeArgs[0]}
+	default:
+		return &ast.IndexListExpr{X: x, Indices: typeArgs}
+	}
+}
+
+// str returns a string literal holding s.
+func str(s string) *ast.BasicLit {
+	return &ast.BasicLit{Kind: token.STRING, Value: fmt.Sprintf("%q", s)}
+}
+
+// define returns the statement "names := value".
+func define(value ast.Expr, names ...string) *ast.AssignStmt {
+	lhs := make([]ast.Expr, len(names))
+	for i, name := range names {
+		lhs[i] = ident(name)
+	}
+	return &ast.AssignStmt{Lhs: lhs, Tok: token.DEFINE, Rhs: []ast.Expr{value}}
+}
+
+// sourceRefOf refers to the declaration at pos by the base name of its file
+// and its line, e.g. "two_sum.go:12", or returns an empty string if the
+// position is unknown.
+func sourceRefOf(pos token.Position) string {
+	if pos.Filename == "" {
+		return ""
+	}
+	if pos.Line == 0 {
+		return filepath.Base(pos.Filename)
+	}
+	return fmt.Sprintf("%s:%d", filepath.Base(pos.Filename), pos.Line)
+}
+
+// generatedFrom describes the declaration a section of generated code comes
+// from, e.g. "Solution.twoSum (two_sum.go:12)".
+func generatedFrom(name string, pos token.Position) string {
+	if ref := sourceRefOf(pos); ref != "" {
+		return fmt.Sprintf("%s (%s)", name, ref)
+	}
+	return name
+}
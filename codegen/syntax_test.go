@@ -0,0 +1,98 @@
+package codegen
+
+import (
+	"go/ast"
+	"go/token"
+	"testing"
+)
+
+func TestLayout(t *testing.T) {
+	l := newLayout()
+	doc := l.comment("Auto-generated test case template for twoSum (two_sum.go:4)")
+	pos := l.next()
+	l.blockComment("\t_ = testTwoSumCase{}")
+	l.blank()
+	decls := []ast.Decl{&ast.GenDecl{Doc: doc, TokPos: pos, Tok: token.VAR, Lparen: pos, Rparen: l.next()}}
+
+	l.blank()
+	decl, err := structDeclOf(l, "testTwoSumInput", []fieldInfo{{Name: "T", Type: "int | float64"}}, []templateField{
+		{name: "nums", typ: "[]T"},
+		{name: "head", typ: "string", comment: "*ListNode in LeetCode notation"},
+		{name: "pair", typ: "struct{x int}"},
+	})
+	if err != nil {
+		t.Fatalf("structDeclOf() error = %v", err)
+	}
+	decls = append(decls, decl)
+
+	result, err := l.printDecls(decls)
+	if err != nil {
+		t.Fatalf("printDecls() error = %v", err)
+	}
+	expected := `// Auto-generated test case template for twoSum (two_sum.go:4)
+var (
+/*
+	_ = testTwoSumCase{}
+*/
+
+)
+
+type testTwoSumInput[T int | float64] struct {
+	nums []T
+	head string // *ListNode in LeetCode notation
+	pair struct{ x int }
+}
+`
+	if string(result) != expected {
+		t.Errorf("printDecls() = %s; expected %s", result, expected)
+	}
+}
+
+func TestExprAt(t *testing.T) {
+	tests := []struct {
+		expr     string
+		expected string
+		err      bool
+	}{
+		{"map[string][]*ListNode", "map[string][]*ListNode", false},
+		{"struct{x int; y int}", "struct {\n\tx int\n\ty int\n}", false},
+		{"struct{}", "struct{}", false},
+		{"1500 * time.Millisecond", "1500 * time.Millisecond", false},
+		{"&{12 Cell}", "", true},
+		{"func(", "", true},
+	}
+
+	for _, test := range tests {
+		l := newLayout()
+		expr, err := exprAt(l.next(), test.expr)
+		if test.err {
+			if err == nil {
+				t.Errorf("exprAt(%q) succeeded; expected an error", test.expr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("exprAt(%q) error = %v", test.expr, err)
+		}
+		if result, err := l.print(expr); err != nil || string(result) != test.expected {
+			t.Errorf("exprAt(%q) printed %q, %v; expected %q", test.expr, result, err, test.expected)
+		}
+	}
+}
+
+func TestGeneratedFrom(t *testing.T) {
+	tests := []struct {
+		pos      token.Position
+		expected string
+	}{
+		{token.Position{Filename: "/src/p/two_sum.go", Line: 12, Column: 6}, "twoSum (two_sum.go:12)"},
+		{token.Position{Filename: "two_sum.go"}, "twoSum (two_sum.go)"},
+		{token.Position{}, "twoSum"},
+	}
+
+	for _, test := range tests {
+		if result := generatedFrom("twoSum", test.pos); result != test.expected {
+			t.Errorf("generatedFrom(%v) = %q; expected %q", test.pos, result, test.expected)
+		}
+	}
+}
@@ -0,0 +1,480 @@
+package codegen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/Ezer015/leetcode-gen-test/utils"
+)
+
+// Files of a template directory, see LoadTemplates.
+const (
+	// TestCaseTemplateFile holds the template of the test case template of
+	// each function.
+	TestCaseTemplateFile = "testcase.tmpl"
+	// TestTemplateFile holds the template of the test of each function.
+	TestTemplateFile = "test.tmpl"
+)
+
+// Templates are text/template templates written by users in place of the
+// code generated for each function tagged for testing, e.g. to run the cases
+// in parallel or to report failures differently. Design types are always
+// generated by the built-in code.
+//
+// A template writes Go declarations, which are formatted and placed in the
+// generated file after the package clause. The imports are added as needed:
+// the packages of the types of the function, lctest, reflect, testing and
+// time when the code refers to them, and any other package the template
+// names with the Import function.
+//
+// Besides the functions of text/template, templates may call:
+//   - UpperFirst: capitalizes the first letter of a name
+//   - FilterGenerics: returns the type parameters used by a list of fields
+//   - FieldListOf, NameListOf, TypeListOf: spell fields as type parameter
+//     lists, e.g. "[T int | float64]", "[T]" and "[int | float64]"
+//   - TestCaseTypeNameOf, TestCaseInputTypeNameOf, TestCaseOutputTypeNameOf,
+//     CheckerFuncNameOf: name test case types and checkers after a case name
+//     following the naming scheme of the generator
+//   - GeneratedFrom: describes a declaration by its name and position, e.g.
+//     "twoSum (two_sum.go:4)"
+//   - Import: adds an import path to the generated file, writing nothing
+type Templates struct {
+	// TestCase writes the test case template of a function: the input,
+	// output and case types its test cases are built from. It is executed
+	// with the Function, or nil for the built-in template.
+	TestCase *template.Template
+	// Test writes the test of a function. It is executed with the
+	// TestTemplateData of the function, or nil for the built-in test.
+	Test *template.Template
+}
+
+// TestTemplateData is the data test templates are executed with: the
+// function under test, its test cases and how the built-in test passes the
+// inputs and checks the outputs of each case. Test cases hold the receiver,
+// inputs and expected outputs in fields named receiver, input and output.
+type TestTemplateData struct {
+	Function
+	// Checker is the name of the function checking the outputs of the
+	// cases, or empty if they are compared with the expected outputs.
+	Checker string
+	Cases   []Case
+	// Args tells how each parameter is passed, in order.
+	Args []Arg
+	// Checks tells how each output is checked, in the order of Outputs.
+	Checks []Check
+	// CloneReceiver is set if the receiver holds references, so that it is
+	// copied with lctest.Clone before calling the method.
+	CloneReceiver bool
+	// TimeoutExpr spells Timeout as a Go expression, e.g. "2 * time.Second",
+	// or is empty if there is no timeout.
+	TimeoutExpr string
+}
+
+// Arg tells how a generated test passes a parameter to the function.
+type Arg struct {
+	Name string
+	Type string
+	// Var is the name of the local variable passed instead of the input
+	// field of the case, or empty if the field is passed directly.
+	Var string
+	// Decode is set if Var is decoded from LeetCode notation with
+	// lctest.MustDecode.
+	Decode bool
+	// Clone is set if Var is a copy of the input field made with
+	// lctest.Clone, which the function modifies in place.
+	Clone bool
+}
+
+// Check tells how a generated test checks an output against the expected
+// value.
+type Check struct {
+	// Name is the name of the output field holding the expected value.
+	Name string
+	Type string
+	// Var is the name of the local variable holding the actual value.
+	Var string
+	// Notation is set if the expected value is spelled in LeetCode notation,
+	// in which case it is decoded into the local variable named by Want.
+	Notation bool
+	Want     string
+	// Comparison is the operator or function comparing the values: "==",
+	// "lctest.Equal" or "lctest.Diff".
+	Comparison string
+	// Options holds the lctest options passed along with the values, e.g.
+	// "lctest.Unordered(0)".
+	Options []string
+}
+
+// LoadTemplates loads the templates of a directory: TestCaseTemplateFile and
+// TestTemplateFile, either of which may be missing to keep the built-in code.
+//
+// Parameters:
+//   - dir: The template directory
+//
+// Returns:
+//   - Templates: The parsed templates
+//   - error: An error if a template cannot be read, or Diagnostics locating
+//     the problems in the template files
+func LoadTemplates(dir string) (Templates, error) {
+	var (
+		ts Templates
+		ds Diagnostics
+	)
+	for _, t := range []struct {
+		file  string
+		tmpl  **template.Template
+		parse func(filename, text string) (*template.Template, error)
+	}{
+		{TestCaseTemplateFile, &ts.TestCase, ParseTestCaseTemplate},
+		{TestTemplateFile, &ts.Test, ParseTestTemplate},
+	} {
+		filename := filepath.Join(dir, t.file)
+		content, err := os.ReadFile(filename)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return Templates{}, fmt.Errorf("reading template: %v", err)
+		}
+		tmpl, err := t.parse(filename, string(content))
+		if err != nil {
+			ds.add(err)
+			continue
+		}
+		*t.tmpl = tmpl
+	}
+	if err := ds.err(); err != nil {
+		return Templates{}, err
+	}
+	return ts, nil
+}
+
+// ParseTestCaseTemplate parses a test case template and validates it by
+// executing it for sample functions, see Templates.TestCase.
+//
+// Parameters:
+//   - filename: The name of the template file, which problems are located in
+//   - text: The template
+//
+// Returns:
+//   - *template.Template: The template
+//   - error: A Diagnostic located in the template file if the template does
+//     not parse, fails for a sample or writes code that does not parse
+func ParseTestCaseTemplate(filename, text string) (*template.Template, error) {
+	var samples []templateSample
+	for _, data := range sampleTestData() {
+		samples = append(samples, templateSample{name: data.Name, data: data.Function})
+	}
+	return parseTemplate(filename, text, samples)
+}
+
+// ParseTestTemplate parses a test template and validates it by executing it
+// for sample functions, see Templates.Test.
+//
+// Parameters:
+//   - filename: The name of the template file, which problems are located in
+//   - text: The template
+//
+// Returns:
+//   - *template.Template: The template
+//   - error: A Diagnostic located in the template file if the template does
+//     not parse, fails for a sample or writes code that does not parse
+func ParseTestTemplate(filename, text string) (*template.Template, error) {
+	var samples []templateSample
+	for _, data := range sampleTestData() {
+		samples = append(samples, templateSample{name: data.Name, data: data})
+	}
+	return parseTemplate(filename, text, samples)
+}
+
+// templateSample is the data a template is executed with on load.
+type templateSample struct {
+	name string
+	data any
+}
+
+// parseTemplate parses a template named after its file and executes it with
+// each sample, so that mistakes such as misspelled fields are reported on
+// load rather than for the first function reaching them.
+func parseTemplate(filename, text string, samples []templateSample) (*template.Template, error) {
+	tmpl, err := template.New(filename).Funcs(templateFuncs(utils.DefaultNaming)).Parse(text)
+	if err != nil {
+		return nil, templateDiagnosticOf(filename, err)
+	}
+	for _, s := range samples {
+		if _, _, err := defaultGenerator.render(tmpl, "sample function "+s.name, s.data, nil); err != nil {
+			return nil, err
+		}
+	}
+	return tmpl, nil
+}
+
+// sampleTestData returns the functions templates are validated with: a plain
+// function, and a generic method taking a list in LeetCode notation that it
+// sorts in place, with a timeout and a checker.
+func sampleTestData() []TestTemplateData {
+	pos := token.Position{Filename: "two_sum.go", Line: 4, Column: 6}
+	plain := Function{
+		Name:      "twoSum",
+		CaseName:  "TwoSum",
+		Params:    []Field{{Name: "nums", Type: "[]int"}, {Name: "target", Type: "int"}},
+		Results:   []Field{{Name: "field0", Type: "[]int"}},
+		Outputs:   []Field{{Name: "field0", Type: "[]int"}},
+		Tolerance: defaultTolerance,
+		Pos:       pos,
+	}
+	list := Field{Name: "head", Type: "*ListNode", Notation: true, NotationExample: `"[1,2,3]"`}
+	method := Function{
+		Name:       "sortList",
+		Receiver:   "Solution",
+		CaseName:   "SolutionSortList",
+		Params:     []Field{list, {Name: "nums", Type: "[]T"}},
+		Results:    []Field{{Name: "field0", Type: "*ListNode", Notation: true, NotationExample: `"[1,2,3]"`}},
+		TypeParams: []Field{{Name: "T", Type: "int | float64"}},
+		InPlace:    []string{"nums"},
+		Tolerance:  defaultTolerance,
+		Unordered:  []int{0},
+		Timeout:    2 * time.Second,
+		Pos:        pos,
+	}
+	method.Outputs = []Field{method.Results[0], method.Params[1]}
+
+	return []TestTemplateData{
+		{
+			Function: plain,
+			Cases:    []Case{{Name: "example", Desc: "example", OutputType: "testTwoSumOutput"}},
+			Args:     []Arg{{Name: "nums", Type: "[]int"}, {Name: "target", Type: "int"}},
+			Checks:   []Check{{Name: "field0", Type: "[]int", Var: "field0", Comparison: "lctest.Equal"}},
+		},
+		{
+			Function: method,
+			Checker:  "checkSolutionSortList",
+			Cases: []Case{
+				{Name: "example", Desc: "example", OutputType: "testSolutionSortListOutput[T]"},
+				{Name: "emptyList", Desc: "empty list", OutputType: "testSolutionSortListOutput[T]"},
+			},
+			Args: []Arg{
+				{Name: "head", Type: "*ListNode", Var: "inHead", Decode: true},
+				{Name: "nums", Type: "[]T", Var: "gotNums", Clone: true},
+			},
+			Checks: []Check{
+				{Name: "field0", Type: "*ListNode", Var: "field0", Notation: true, Want: "wantField0", Comparison: "lctest.Equal"},
+				{Name: "nums", Type: "[]T", Var: "gotNums", Comparison: "lctest.Diff", Options: []string{"lctest.Unordered(0)"}},
+			},
+			CloneReceiver: true,
+			TimeoutExpr:   timeoutExprOf(2 * time.Second),
+		},
+	}
+}
+
+// templateFuncs returns the functions available to templates, naming test
+// case types and checkers following naming. Import is bound to the generated
+// file when the template is executed, see render.
+func templateFuncs(naming utils.Naming) template.FuncMap {
+	return template.FuncMap{
+		"UpperFirst": upperFirst,
+		"FilterGenerics": func(generics, fields []Field) []Field {
+			used := make(map[string]bool)
+			for _, g := range filterGenerics(fieldInfosOf(generics), fieldInfosOf(fields)) {
+				used[g.Name] = true
+			}
+			var result []Field
+			for _, g := range generics {
+				if used[g.Name] {
+					result = append(result, g)
+				}
+			}
+			return result
+		},
+		"FieldListOf":              func(fields []Field) string { return fieldListOf(fieldInfosOf(fields)) },
+		"NameListOf":               func(fields []Field) string { return nameListOf(fieldInfosOf(fields)) },
+		"TypeListOf":               func(fields []Field) string { return typeListOf(fieldInfosOf(fields)) },
+		"TestCaseTypeNameOf":       naming.TestCaseTypeNameOf,
+		"TestCaseInputTypeNameOf":  naming.TestCaseInputTypeNameOf,
+		"TestCaseOutputTypeNameOf": naming.TestCaseOutputTypeNameOf,
+		"CheckerFuncNameOf":        naming.CheckerFuncNameOf,
+		"GeneratedFrom":            generatedFrom,
+		"Import":                   func(path string) string { return "" },
+	}
+}
+
+// fieldInfosOf turns fields of the exported model back into the fields the
+// helpers of templates take.
+func fieldInfosOf(fields []Field) []fieldInfo {
+	infos := make([]fieldInfo, len(fields))
+	for i, f := range fields {
+		infos[i] = fieldInfo{Name: f.Name, Type: f.Type}
+	}
+	return infos
+}
+
+// render executes a template for a function and formats the declarations it
+// writes.
+//
+// Parameters:
+//   - tmpl: The template
+//   - name: The name of the function, which problems are reported for
+//   - data: The data the template is executed with
+//   - candidates: The imports the code may refer to, which it needs if it
+//     uses their package names or aliases, see importSpecOf
+//
+// Returns:
+//   - []byte: The formatted declarations
+//   - []string: The import paths the declarations need: the candidates they
+//     refer to and the paths the template imports
+//   - error: A Diagnostic located in the template file if the template fails
+//     or writes code that does not parse
+func (g *Generator) render(tmpl *template.Template, name string, data any, candidates []string) ([]byte, []string, error) {
+	t, err := tmpl.Clone()
+	if err != nil {
+		return nil, nil, templateDiagnosticOf(tmpl.Name(), err)
+	}
+	var imports []string
+	t.Funcs(templateFuncs(g.naming)).Funcs(template.FuncMap{
+		"Import": func(path string) (string, error) {
+			if path == "" || strings.ContainsAny(path, "\"\\ \t\n") {
+				return "", fmt.Errorf("invalid import path %q", path)
+			}
+			imports = append(imports, path)
+			return "", nil
+		},
+	})
+
+	var out bytes.Buffer
+	if err := t.Execute(&out, data); err != nil {
+		d := templateDiagnosticOf(tmpl.Name(), err)
+		d.Message = name + ": " + d.Message
+		return nil, nil, d
+	}
+	code, refs, err := formatDecls(out.Bytes())
+	if err != nil {
+		return nil, nil, errorIn(tmpl.Name(), "%s: generated code does not parse: %v", name, err)
+	}
+	for _, imp := range candidates {
+		name, path := importSpecOf(imp)
+		if name == "" {
+			name = packageNameOf(path)
+		}
+		if refs[name] {
+			imports = append(imports, imp)
+		}
+	}
+	return code, uniqueSorted(imports), nil
+}
+
+// formatDecls formats Go declarations written without a package clause.
+//
+// Parameters:
+//   - code: The declarations
+//
+// Returns:
+//   - []byte: The formatted declarations
+//   - map[string]bool: The names the declarations select from without
+//     declaring them, which are package names
+//   - error: The first syntax error, located in code, if it does not parse
+func formatDecls(code []byte) ([]byte, map[string]bool, error) {
+	const clause = "package p\n"
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", clause+string(code), parser.ParseComments)
+	if err != nil {
+		var errs scanner.ErrorList
+		if errors.As(err, &errs) && len(errs) > 0 {
+			return nil, nil, fmt.Errorf("%d:%d: %s", errs[0].Pos.Line-1, errs[0].Pos.Column, errs[0].Msg)
+		}
+		return nil, nil, err
+	}
+
+	refs := make(map[string]bool)
+	ast.Inspect(f, func(n ast.Node) bool {
+		if s, ok := n.(*ast.SelectorExpr); ok {
+			if x, ok := s.X.(*ast.Ident); ok && x.Obj == nil {
+				refs[x.Name] = true
+			}
+		}
+		return true
+	})
+
+	var out bytes.Buffer
+	if err := format.Node(&out, fset, f); err != nil {
+		return nil, nil, err
+	}
+	return bytes.TrimLeft(bytes.TrimPrefix(out.Bytes(), []byte(clause)), "\n"), refs, nil
+}
+
+// packageNameOf returns the name packages are usually referred to by: the
+// last element of their import path, skipping major version suffixes such
+// as "v2".
+func packageNameOf(importPath string) string {
+	dir, name := path.Split(importPath)
+	if len(name) > 1 && name[0] == 'v' && strings.Trim(name[1:], "0123456789") == "" && dir != "" {
+		return path.Base(dir)
+	}
+	return name
+}
+
+// templateDiagnosticOf locates an error of text/template in the template file
+// named filename. Errors carry "template: name:line:" or, once the template
+// is executed, "template: name:line:col: executing "name" at <...>:".
+func templateDiagnosticOf(filename string, err error) Diagnostic {
+	d := Diagnostic{Pos: token.Position{Filename: filename}, Message: err.Error()}
+	rest, ok := strings.CutPrefix(err.Error(), "template: "+filename+":")
+	if !ok {
+		return d
+	}
+	lineStr, rest, _ := strings.Cut(rest, ":")
+	line, err := strconv.Atoi(lineStr)
+	if err != nil {
+		return d
+	}
+	d.Pos.Line = line
+	if colStr, after, ok := strings.Cut(rest, ":"); ok {
+		if col, err := strconv.Atoi(colStr); err == nil {
+			d.Pos.Column = col
+			rest = after
+		}
+	}
+	rest = strings.TrimSpace(rest)
+	d.Message = strings.TrimPrefix(rest, fmt.Sprintf("executing %q ", filename))
+	return d
+}
+
+// testTemplateDataOf gathers the data a test template is executed with for
+// a function.
+func testTemplateDataOf(tf testFuncData, tc testCaseData, args []argInfo, outputs []resultCheck, cloneReceiver bool) TestTemplateData {
+	data := TestTemplateData{
+		Function:      functionOf(tf),
+		Checker:       tc.Checker,
+		Cases:         casesOf(tc.Cases),
+		CloneReceiver: cloneReceiver,
+		TimeoutExpr:   timeoutExprOf(tf.Timeout),
+	}
+	for _, arg := range args {
+		data.Args = append(data.Args, Arg{Name: arg.Name, Type: arg.Type, Var: arg.Var, Decode: arg.Decode, Clone: arg.Clone})
+	}
+	for _, o := range outputs {
+		data.Checks = append(data.Checks, Check{
+			Name:       o.Name,
+			Type:       o.Type,
+			Var:        o.Var,
+			Notation:   o.Notation,
+			Want:       o.Want,
+			Comparison: o.Comparison.String(),
+			Options:    o.Options,
+		})
+	}
+	return data
+}
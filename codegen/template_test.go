@@ -0,0 +1,129 @@
+package codegen
+
+import (
+	"bytes"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// parallelTestTemplate runs the cases of plain functions in parallel,
+// logging the inputs of failed cases.
+const parallelTestTemplate = `{{- $f := . -}}
+// Test{{.CaseName}} runs the cases of {{GeneratedFrom .Name .Pos}} in parallel.
+func Test{{.CaseName}}(t *testing.T) {
+{{- range $c := .Cases}}
+	t.Run({{printf "%q" $c.Desc}}, func(t *testing.T) {
+		t.Parallel()
+		{{range $i, $k := $f.Checks}}{{if $i}}, {{end}}{{$k.Var}}{{end}} := {{$f.Name}}({{range $i, $a := $f.Args}}{{if $i}}, {{end}}{{$c.Name}}.input.{{$a.Name}}{{end}})
+		{{- range $f.Checks}}
+		if !reflect.DeepEqual({{.Var}}, {{$c.Name}}.output.{{.Name}}) {
+			t.Error(fmt.Sprintf("inputs %+v: got %v", {{$c.Name}}.input, {{.Var}}))
+		}
+		{{- end}}
+	})
+{{- end}}
+}
+{{- Import "fmt"}}
+`
+
+func TestParseTestTemplate(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected string
+	}{
+		{parallelTestTemplate, ""},
+		{"// {{.Name}}\nfunc Test{{.Bogus}}(t *testing.T) {}", `test.tmpl:2:11: sample function twoSum: at <.Bogus>: can't evaluate field Bogus in type codegen.TestTemplateData`},
+		{"// {{.Name}}\n{{Nope .Name}}", `test.tmpl:2: function "Nope" not defined`},
+		{"func Test{{.CaseName}}(t *testing.T) {", "test.tmpl: sample function twoSum: generated code does not parse: 1:32: expected '}', found 'EOF'"},
+		{`{{Import "a b"}}`, `test.tmpl:1:2: sample function twoSum: at <Import "a b">: error calling Import: invalid import path "a b"`},
+	}
+
+	for _, test := range tests {
+		_, err := ParseTestTemplate("test.tmpl", test.text)
+		result := ""
+		if err != nil {
+			result = err.Error()
+		}
+		if result != test.expected {
+			t.Errorf("ParseTestTemplate(%q) error = %q; expected %q", test.text, result, test.expected)
+		}
+	}
+}
+
+func TestGeneratorTemplates(t *testing.T) {
+	fsys := fstest.MapFS{
+		"go.mod": {Data: []byte("module example.com/lc\n\ngo 1.23\n")},
+		"p/sol.go": {Data: []byte(`package p
+
+//leetcode:test
+func addTwo(a, b int) int { return a + b }
+`)},
+	}
+	testCase, err := ParseTestCaseTemplate("testcase.tmpl", `// {{TestCaseTypeNameOf .CaseName}} is a case of {{.Name}}.
+type {{TestCaseTypeNameOf .CaseName}} struct {
+	name   string
+	input  struct { {{range .Params}}{{.Name}} {{.SchemaType}}; {{end}} }
+	output struct { {{range .Outputs}}{{.Name}} {{.SchemaType}}; {{end}} }
+}
+`)
+	if err != nil {
+		t.Fatalf("ParseTestCaseTemplate() error = %v", err)
+	}
+	test, err := ParseTestTemplate("test.tmpl", parallelTestTemplate)
+	if err != nil {
+		t.Fatalf("ParseTestTemplate() error = %v", err)
+	}
+	g, err := NewGenerator(Options{FS: fsys, Templates: Templates{TestCase: testCase, Test: test}})
+	if err != nil {
+		t.Fatalf("NewGenerator() error = %v", err)
+	}
+
+	result, err := g.GenerateTestCases(Input{Path: "p/sol.go"})
+	if err != nil {
+		t.Fatalf("GenerateTestCases() error = %v", err)
+	}
+	expected := `//go:generate leetcode-gen-test generate --test-case=$GOFILE
+package p
+
+// testAddTwoCase is a case of addTwo.
+type testAddTwoCase struct {
+	name  string
+	input struct {
+		a int
+		b int
+	}
+	output struct{ field0 int }
+}
+`
+	if content := string(result.Files[0].Content); content != expected {
+		t.Errorf("GenerateTestCases() = %s; expected %s", content, expected)
+	}
+
+	testCases := string(result.Files[0].Content) + `
+var sum = testAddTwoCase{name: "small sum"}
+`
+	result, err = g.GenerateTests(Input{Path: "p/sol.go"}, Input{Path: "p/sol_testcase.go", Content: strings.NewReader(testCases)})
+	if err != nil {
+		t.Fatalf("GenerateTests() error = %v", err)
+	}
+	content := result.Files[0].Content
+	f, err := parser.ParseFile(token.NewFileSet(), "sol_test.go", content, parser.ImportsOnly)
+	if err != nil {
+		t.Fatalf("GenerateTests() generated invalid code: %v\n%s", err, content)
+	}
+	var imports []string
+	for _, spec := range f.Imports {
+		imports = append(imports, spec.Path.Value)
+	}
+	if strings.Join(imports, " ") != `"fmt" "reflect" "testing"` {
+		t.Errorf("GenerateTests() imports = %v; expected fmt, reflect and testing", imports)
+	}
+	for _, expected := range []string{"// TestAddTwo runs the cases of addTwo (sol.go:4) in parallel.", "\t\tt.Parallel()\n", "field0 := addTwo(sum.input.a, sum.input.b)"} {
+		if !bytes.Contains(content, []byte(expected)) {
+			t.Errorf("GenerateTests() generated\n%s\nexpected it to contain %q", content, expected)
+		}
+	}
+}
@@ -2,6 +2,9 @@ package codegen
 
 import (
 	"fmt"
+	"go/ast"
+	"go/token"
+	"sort"
 	"strings"
 	"unicode"
 )
@@ -62,7 +65,7 @@ func filterGenerics(generics, fields []fieldInfo) []fieldInfo {
 	intersection := make([]fieldInfo, 0)
 	for _, generic := range generics {
 		for _, fieldType := range fieldTypes {
-			if containsGeneric(generic.Name, fieldType) {
+			if fieldType == generic.Name || containsGeneric(fieldType, generic.Name) {
 				intersection = append(intersection, generic)
 				break
 			}
@@ -172,3 +175,71 @@ func typeListOf(generics []fieldInfo) string {
 	}
 	return fmt.Sprintf("[%s]", strings.Join(types, ", "))
 }
+
+// importDeclOf lays out an import declaration for the given package paths.
+// A single path yields a one-line declaration and several paths a grouped one,
+// with standard library packages listed before all others. Aliased packages,
+// see importSpecOf, are imported with their aliases.
+//
+// Example:
+//
+//	input: []string{"reflect", "testing"}
+//	output: "import (\n\t\"reflect\"\n\t\"testing\"\n)"
+func importDeclOf(l *layout, paths []string) *ast.GenDecl {
+	pos := l.next()
+	decl := &ast.GenDecl{TokPos: pos, Tok: token.IMPORT}
+	if len(paths) == 1 {
+		decl.Specs = []ast.Spec{importSpecAt(token.NoPos, paths[0])}
+		return decl
+	}
+
+	var std, others []string
+	for _, imp := range paths {
+		if _, path := importSpecOf(imp); isStdImportPath(path) {
+			std = append(std, imp)
+		} else {
+			others = append(others, imp)
+		}
+	}
+	sortImports(std)
+	sortImports(others)
+
+	decl.Lparen = pos
+	for _, imp := range std {
+		decl.Specs = append(decl.Specs, importSpecAt(l.next(), imp))
+	}
+	if len(std) > 0 && len(others) > 0 {
+		l.blank()
+	}
+	for _, imp := range others {
+		decl.Specs = append(decl.Specs, importSpecAt(l.next(), imp))
+	}
+	decl.Rparen = l.next()
+	return decl
+}
+
+// importSpecAt creates the import spec of a package at pos, see importSpecOf.
+func importSpecAt(pos token.Pos, imp string) *ast.ImportSpec {
+	name, path := importSpecOf(imp)
+	spec := &ast.ImportSpec{Path: at(pos, str(path))}
+	if name != "" {
+		spec.Name = at(pos, ident(name))
+	}
+	return spec
+}
+
+// sortImports sorts imports by path, as gofmt does, see importSpecOf.
+func sortImports(imports []string) {
+	sort.Slice(imports, func(i, j int) bool {
+		_, a := importSpecOf(imports[i])
+		_, b := importSpecOf(imports[j])
+		return a < b
+	})
+}
+
+// isStdImportPath reports whether path looks like a standard library import
+// path, i.e. its first element contains no dot.
+func isStdImportPath(path string) bool {
+	first, _, _ := strings.Cut(path, "/")
+	return !strings.Contains(first, ".")
+}
@@ -118,7 +118,7 @@ func equalFieldInfoSlices(a, b []fieldInfo) bool {
 		return false
 	}
 	for i := range a {
-		if a[i] != b[i] {
+		if a[i].Name != b[i].Name || a[i].Type != b[i].Type || a[i].typ != b[i].typ {
 			return false
 		}
 	}
@@ -326,3 +326,26 @@ func TestTypeListOf(t *testing.T) {
 		}
 	}
 }
+func TestImportDeclOf(t *testing.T) {
+	tests := []struct {
+		paths    []string
+		expected string
+	}{
+		{[]string{"testing"}, "import \"testing\""},
+		{[]string{"reflect", "testing"}, "import (\n\t\"reflect\"\n\t\"testing\"\n)"},
+		{[]string{"github.com/a/b", "testing"}, "import (\n\t\"testing\"\n\n\t\"github.com/a/b\"\n)"},
+		{[]string{"mathrand math/rand"}, "import mathrand \"math/rand\""},
+		{[]string{"crypto/rand", "mathrand math/rand", "testing"}, "import (\n\t\"crypto/rand\"\n\tmathrand \"math/rand\"\n\t\"testing\"\n)"},
+	}
+
+	for _, test := range tests {
+		l := newLayout()
+		result, err := l.print(importDeclOf(l, test.paths))
+		if err != nil {
+			t.Fatalf("printing importDeclOf(%v): %v", test.paths, err)
+		}
+		if string(result) != test.expected {
+			t.Errorf("importDeclOf(%v) = %q; expected %q", test.paths, result, test.expected)
+		}
+	}
+}
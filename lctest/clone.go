@@ -0,0 +1,91 @@
+package lctest
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+// Clone returns a deep copy of v. Slices, maps, pointers and interfaces are
+// copied recursively, including those held in unexported struct fields, so
+// that a function modifying the copy in place leaves v untouched.
+// Shared and cyclic pointers are preserved in the copy.
+//
+// Parameters:
+//   - v: The value to copy
+//
+// Returns:
+//   - T: A deep copy of v
+func Clone[T any](v T) T {
+	src := reflect.ValueOf(&v).Elem()
+	dst := reflect.New(src.Type()).Elem()
+	cloneInto(dst, src, make(map[uintptr]reflect.Value))
+	return dst.Interface().(T)
+}
+
+// cloneInto deep-copies src into the settable value dst.
+func cloneInto(dst, src reflect.Value, seen map[uintptr]reflect.Value) {
+	src = accessible(src)
+	switch src.Kind() {
+	case reflect.Pointer:
+		if src.IsNil() {
+			return
+		}
+		if p, ok := seen[src.Pointer()]; ok {
+			dst.Set(p)
+			return
+		}
+		p := reflect.New(src.Type().Elem())
+		seen[src.Pointer()] = p
+		cloneInto(p.Elem(), src.Elem(), seen)
+		dst.Set(p)
+	case reflect.Slice:
+		if src.IsNil() {
+			return
+		}
+		s := reflect.MakeSlice(src.Type(), src.Len(), src.Len())
+		for i := 0; i < src.Len(); i++ {
+			cloneInto(s.Index(i), src.Index(i), seen)
+		}
+		dst.Set(s)
+	case reflect.Array:
+		for i := 0; i < src.Len(); i++ {
+			cloneInto(dst.Index(i), src.Index(i), seen)
+		}
+	case reflect.Map:
+		if src.IsNil() {
+			return
+		}
+		m := reflect.MakeMapWithSize(src.Type(), src.Len())
+		iter := src.MapRange()
+		for iter.Next() {
+			k := reflect.New(src.Type().Key()).Elem()
+			cloneInto(k, iter.Key(), seen)
+			v := reflect.New(src.Type().Elem()).Elem()
+			cloneInto(v, iter.Value(), seen)
+			m.SetMapIndex(k, v)
+		}
+		dst.Set(m)
+	case reflect.Struct:
+		for i := 0; i < src.NumField(); i++ {
+			cloneInto(accessible(dst.Field(i)), src.Field(i), seen)
+		}
+	case reflect.Interface:
+		if src.IsNil() {
+			return
+		}
+		v := reflect.New(src.Elem().Type()).Elem()
+		cloneInto(v, src.Elem(), seen)
+		dst.Set(v)
+	default:
+		dst.Set(src)
+	}
+}
+
+// accessible lifts the read-only restriction from addressable values obtained
+// through unexported struct fields, so that they can be read and set.
+func accessible(v reflect.Value) reflect.Value {
+	if v.CanAddr() && !v.CanInterface() {
+		return reflect.NewAt(v.Type(), unsafe.Pointer(v.UnsafeAddr())).Elem()
+	}
+	return v
+}
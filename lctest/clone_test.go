@@ -0,0 +1,46 @@
+package lctest
+
+import (
+	"reflect"
+	"testing"
+)
+
+type board struct {
+	cells [][]byte
+	seen  map[string][]int
+	next  *board
+	extra any
+}
+
+func TestClone(t *testing.T) {
+	nums := []int{1, 2, 3}
+	numsCopy := Clone(nums)
+	numsCopy[0] = 9
+	if nums[0] != 1 {
+		t.Errorf("Clone(%v) shares its backing array", nums)
+	}
+	if Clone([]int(nil)) != nil {
+		t.Errorf("Clone(nil) = non-nil slice; want nil")
+	}
+
+	original := &board{
+		cells: [][]byte{{'5', '.'}, {'.', '3'}},
+		seen:  map[string][]int{"row": {1}},
+		extra: []string{"x"},
+	}
+	original.next = original
+	copied := Clone(original)
+	if !reflect.DeepEqual(original, copied) {
+		t.Fatalf("Clone(%+v) = %+v; want an equal value", original, copied)
+	}
+	if copied.next != copied {
+		t.Errorf("Clone() did not preserve the cycle")
+	}
+
+	copied.cells[0][0] = 'x'
+	copied.seen["row"][0] = 9
+	copied.extra.([]string)[0] = "y"
+	if original.cells[0][0] != '5' || original.seen["row"][0] != 1 || original.extra.([]string)[0] != "x" {
+		t.Errorf("Clone() shares nested values with the original: %+v", original)
+	}
+}
@@ -0,0 +1,212 @@
+package lctest
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Diff describes how got differs from want, or returns an empty string if
+// Equal would report them as equal with the same options.
+// For unordered slices the description lists the missing and the extra
+// elements; otherwise it points at the first differing element.
+//
+// Parameters:
+//   - got: The actual value
+//   - want: The expected value
+//   - opts: Options adjusting the comparison
+//
+// Returns:
+//   - string: A human-readable description of the difference, or "" if none
+func Diff(got, want any, opts ...Option) string {
+	c := newConfig(opts)
+	return c.diff(reflect.ValueOf(got), reflect.ValueOf(want), 0, "")
+}
+
+func (c *config) diff(a, b reflect.Value, depth int, path string) string {
+	if c.equal(a, b, depth, make(map[visit]bool)) {
+		return ""
+	}
+	at := ""
+	if path != "" {
+		at = path + ": "
+	}
+	if !a.IsValid() || !b.IsValid() || a.Type() != b.Type() {
+		return fmt.Sprintf("%sgot %+v, want %+v", at, valueOf(a), valueOf(b))
+	}
+
+	switch a.Kind() {
+	case reflect.Array, reflect.Slice:
+		if c.unordered[depth] {
+			missing, extra := c.multisetDiff(a, b, depth)
+			return fmt.Sprintf("%smissing %+v, extra %+v", at, missing, extra)
+		}
+		if a.Len() != b.Len() {
+			return fmt.Sprintf("%slength %d, want %d", at, a.Len(), b.Len())
+		}
+		for i := 0; i < a.Len(); i++ {
+			if d := c.diff(a.Index(i), b.Index(i), depth+1, fmt.Sprintf("%s[%d]", path, i)); d != "" {
+				return d
+			}
+		}
+	case reflect.Pointer, reflect.Interface:
+		if !a.IsNil() && !b.IsNil() {
+			return c.diff(a.Elem(), b.Elem(), depth, path)
+		}
+	}
+	return fmt.Sprintf("%sgot %+v, want %+v", at, valueOf(a), valueOf(b))
+}
+
+// multisetDiff matches the elements of the slices or arrays a and b,
+// ignoring their order, and returns the elements of b without a match in a
+// and the elements of a without a match in b.
+func (c *config) multisetDiff(a, b reflect.Value, depth int) (missing, extra []any) {
+	if !c.tolerant {
+		// Exact matches can be counted by a canonical key in linear time
+		counts := make(map[string]int)
+		for i := 0; i < a.Len(); i++ {
+			counts[c.keyOf(a.Index(i), depth+1)]++
+		}
+		for i := 0; i < b.Len(); i++ {
+			key := c.keyOf(b.Index(i), depth+1)
+			if counts[key] == 0 {
+				missing = append(missing, valueOf(b.Index(i)))
+				continue
+			}
+			counts[key]--
+		}
+		for i := 0; i < a.Len(); i++ {
+			key := c.keyOf(a.Index(i), depth+1)
+			if counts[key] > 0 {
+				counts[key]--
+				extra = append(extra, valueOf(a.Index(i)))
+			}
+		}
+		return missing, extra
+	}
+
+	matched := make([]bool, a.Len())
+	for j := 0; j < b.Len(); j++ {
+		found := false
+		for i := 0; i < a.Len(); i++ {
+			if !matched[i] && c.equal(a.Index(i), b.Index(j), depth+1, make(map[visit]bool)) {
+				matched[i] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, valueOf(b.Index(j)))
+		}
+	}
+	for i, ok := range matched {
+		if !ok {
+			extra = append(extra, valueOf(a.Index(i)))
+		}
+	}
+	return missing, extra
+}
+
+// keyOf renders a canonical key for v, which is nested in depth slices or
+// arrays. Values that are equal under the configuration share the same key;
+// in particular, the elements of unordered slices are sorted by their keys.
+func (c *config) keyOf(v reflect.Value, depth int) string {
+	var sb strings.Builder
+	c.writeKey(&sb, v, depth, make(map[uintptr]bool))
+	return sb.String()
+}
+
+func (c *config) writeKey(sb *strings.Builder, v reflect.Value, depth int, visited map[uintptr]bool) {
+	if !v.IsValid() {
+		sb.WriteString("<nil>")
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Array, reflect.Slice:
+		// Nil slices have the key of empty ones, as Equal takes them to be equal
+		keys := make([]string, v.Len())
+		for i := range keys {
+			keys[i] = c.keyOf(v.Index(i), depth+1)
+		}
+		if c.unordered[depth] {
+			sort.Strings(keys)
+		}
+		sb.WriteString("[")
+		sb.WriteString(strings.Join(keys, ","))
+		sb.WriteString("]")
+	case reflect.Map:
+		entries := make([]string, 0, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			entries = append(entries, c.keyOf(iter.Key(), depth)+":"+c.keyOf(iter.Value(), depth))
+		}
+		sort.Strings(entries)
+		sb.WriteString("map[")
+		sb.WriteString(strings.Join(entries, ","))
+		sb.WriteString("]")
+	case reflect.Pointer:
+		if v.IsNil() {
+			sb.WriteString("nil")
+			return
+		}
+		if visited[v.Pointer()] {
+			sb.WriteString("<cycle>")
+			return
+		}
+		visited[v.Pointer()] = true
+		sb.WriteString("&")
+		c.writeKey(sb, v.Elem(), depth, visited)
+		delete(visited, v.Pointer())
+	case reflect.Interface:
+		if v.IsNil() {
+			sb.WriteString("nil")
+			return
+		}
+		sb.WriteString(v.Elem().Type().String())
+		sb.WriteString("(")
+		c.writeKey(sb, v.Elem(), depth, visited)
+		sb.WriteString(")")
+	case reflect.Struct:
+		sb.WriteString("{")
+		for i := 0; i < v.NumField(); i++ {
+			if i > 0 {
+				sb.WriteString(",")
+			}
+			c.writeKey(sb, v.Field(i), depth, visited)
+		}
+		sb.WriteString("}")
+	case reflect.String:
+		sb.WriteString(strconv.Quote(v.String()))
+	case reflect.Bool:
+		sb.WriteString(strconv.FormatBool(v.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		sb.WriteString(strconv.FormatInt(v.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		sb.WriteString(strconv.FormatUint(v.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		sb.WriteString(strconv.FormatFloat(v.Float(), 'g', -1, 64))
+	case reflect.Complex64, reflect.Complex128:
+		sb.WriteString(strconv.FormatComplex(v.Complex(), 'g', -1, 128))
+	default:
+		sb.WriteString(fmt.Sprintf("%s@%x", v.Kind(), v.Pointer()))
+	}
+}
+
+// valueOf returns the value held by v for printing, including values read
+// from unexported struct fields. Linked lists and binary trees are printed in
+// LeetCode notation.
+func valueOf(v reflect.Value) any {
+	if !v.IsValid() {
+		return nil
+	}
+	if hasNodes(v.Type()) {
+		return Encode(accessible(v).Interface())
+	}
+	if v.CanInterface() {
+		return v.Interface()
+	}
+	return fmt.Sprintf("%v", v)
+}
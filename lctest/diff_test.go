@@ -0,0 +1,60 @@
+package lctest
+
+import "testing"
+
+func TestUnorderedEqual(t *testing.T) {
+	tests := []struct {
+		name     string
+		got      any
+		want     any
+		opts     []Option
+		expected bool
+	}{
+		{"ordered by default", []int{1, 2}, []int{2, 1}, nil, false},
+		{"outer unordered", []int{1, 2, 2}, []int{2, 1, 2}, []Option{Unordered()}, true},
+		{"different multiplicity", []int{1, 1, 2}, []int{1, 2, 2}, []Option{Unordered()}, false},
+		{"inner still ordered", [][]int{{1, 2}, {3}}, [][]int{{3}, {2, 1}}, []Option{Unordered(0)}, false},
+		{"outer and inner", [][]int{{1, 2}, {3}}, [][]int{{3}, {2, 1}}, []Option{Unordered(0, 1)}, true},
+		{"inner only", [][]string{{"eat", "tea"}, {"bat"}}, [][]string{{"tea", "eat"}, {"bat"}}, []Option{Unordered(1)}, true},
+		{"inner only keeps outer order", [][]string{{"bat"}, {"eat", "tea"}}, [][]string{{"tea", "eat"}, {"bat"}}, []Option{Unordered(1)}, false},
+		{"nil and empty elements", [][]int{nil, {1}}, [][]int{{1}, {}}, []Option{Unordered()}, true},
+		{"tolerant elements", []float64{1.0000001, 2}, []float64{2, 1}, []Option{Unordered(), Tolerance(1e-5, 1e-5)}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := Equal(tt.got, tt.want, tt.opts...); result != tt.expected {
+				t.Errorf("Equal(%v, %v) = %v; want %v", tt.got, tt.want, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		got      any
+		want     any
+		opts     []Option
+		expected string
+	}{
+		{"equal", []int{1, 2}, []int{1, 2}, nil, ""},
+		{"scalar", 1, 2, nil, "got 1, want 2"},
+		{"length", []int{1}, []int{1, 2}, nil, "length 1, want 2"},
+		{"first difference", [][]int{{1}, {2, 3}}, [][]int{{1}, {2, 4}}, nil, "[1][1]: got 3, want 4"},
+		{"nil and empty", []int(nil), []int{}, nil, ""},
+		{"nil and non-empty", []int(nil), []int{1}, nil, "length 0, want 1"},
+		{"missing and extra", []int{1, 2, 2}, []int{1, 2, 3}, []Option{Unordered()}, "missing [3], extra [2]"},
+		{"nested multiset", [][]int{{1, 2}, {3}}, [][]int{{3}, {2, 1}, {4}}, []Option{Unordered(0, 1)}, "missing [[4]], extra []"},
+		{"inner multiset", [][]int{{1, 2}}, [][]int{{2, 3}}, []Option{Unordered(1)}, "[0]: missing [3], extra [1]"},
+		{"tolerant multiset", []float64{1, 2}, []float64{2.0000001, 3}, []Option{Unordered(), Tolerance(1e-5, 1e-5)}, "missing [3], extra [1]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := Diff(tt.got, tt.want, tt.opts...); result != tt.expected {
+				t.Errorf("Diff(%v, %v) = %q; want %q", tt.got, tt.want, result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+// Package lctest provides the runtime helpers used by tests generated with
+// leetcode-gen-test.
+package lctest
+
+import (
+	"math"
+	"reflect"
+)
+
+// Default tolerances match the precision LeetCode accepts for floating-point
+// answers.
+const (
+	DefaultAbsTolerance = 1e-5
+	DefaultRelTolerance = 1e-5
+)
+
+// Option configures a comparison performed by Equal.
+type Option func(*config)
+
+type config struct {
+	absTol, relTol float64
+	tolerant       bool
+	unordered      map[int]bool
+}
+
+// Tolerance makes Equal treat two floating-point numbers as equal if they
+// differ by at most abs, or by at most rel times the larger magnitude.
+// The option applies to floats at any depth, including those nested in
+// slices, maps, pointers and structs.
+func Tolerance(abs, rel float64) Option {
+	return func(c *config) {
+		c.absTol = abs
+		c.relTol = rel
+		c.tolerant = true
+	}
+}
+
+// Unordered makes Equal and Diff compare the slices and arrays found at the
+// given nesting levels as multisets, ignoring the order of their elements.
+// Level 0 is the value itself, level 1 are the elements of a level 0 slice,
+// and so on; only slices and arrays count as a nesting level.
+// Without levels, only level 0 is unordered.
+func Unordered(levels ...int) Option {
+	if len(levels) == 0 {
+		levels = []int{0}
+	}
+	return func(c *config) {
+		if c.unordered == nil {
+			c.unordered = make(map[int]bool)
+		}
+		for _, level := range levels {
+			c.unordered[level] = true
+		}
+	}
+}
+
+// Equal reports whether got and want are deeply equal.
+// It follows the rules of reflect.DeepEqual unless options relax them, except
+// that nil and empty slices and maps are equal, as LeetCode spells both as [].
+//
+// Parameters:
+//   - got: The actual value
+//   - want: The expected value
+//   - opts: Options adjusting the comparison
+//
+// Returns:
+//   - bool: True if the values are considered equal, false otherwise.
+func Equal(got, want any, opts ...Option) bool {
+	c := newConfig(opts)
+	return c.equal(reflect.ValueOf(got), reflect.ValueOf(want), 0, make(map[visit]bool))
+}
+
+func newConfig(opts []Option) *config {
+	var c config
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return &c
+}
+
+// visit records a pair of references already being compared, so that
+// cyclic values terminate.
+type visit struct {
+	a, b uintptr
+	typ  reflect.Type
+}
+
+// equal compares a and b, which are nested in depth slices or arrays.
+func (c *config) equal(a, b reflect.Value, depth int, visited map[visit]bool) bool {
+	if !a.IsValid() || !b.IsValid() {
+		return a.IsValid() == b.IsValid()
+	}
+	if a.Type() != b.Type() {
+		return false
+	}
+
+	switch a.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Map:
+		// LeetCode spells nil and empty slices and maps alike, as []
+		if a.Kind() != reflect.Pointer && a.Len() != b.Len() {
+			return false
+		}
+		if a.IsNil() || b.IsNil() {
+			return a.Kind() != reflect.Pointer || a.IsNil() == b.IsNil()
+		}
+		if a.Pointer() == b.Pointer() {
+			return true
+		}
+		v := visit{a.Pointer(), b.Pointer(), a.Type()}
+		if visited[v] {
+			return true
+		}
+		visited[v] = true
+	}
+
+	switch a.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return c.floatEqual(a.Float(), b.Float())
+	case reflect.Complex64, reflect.Complex128:
+		return c.floatEqual(real(a.Complex()), real(b.Complex())) &&
+			c.floatEqual(imag(a.Complex()), imag(b.Complex()))
+	case reflect.Array, reflect.Slice:
+		if c.unordered[depth] {
+			missing, extra := c.multisetDiff(a, b, depth)
+			return len(missing) == 0 && len(extra) == 0
+		}
+		for i := 0; i < a.Len(); i++ {
+			if !c.equal(a.Index(i), b.Index(i), depth+1, visited) {
+				return false
+			}
+		}
+		return true
+	case reflect.Map:
+		iter := a.MapRange()
+		for iter.Next() {
+			bv := b.MapIndex(iter.Key())
+			if !bv.IsValid() || !c.equal(iter.Value(), bv, depth, visited) {
+				return false
+			}
+		}
+		return true
+	case reflect.Pointer, reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			return a.IsNil() == b.IsNil()
+		}
+		return c.equal(a.Elem(), b.Elem(), depth, visited)
+	case reflect.Struct:
+		for i := 0; i < a.NumField(); i++ {
+			if !c.equal(a.Field(i), b.Field(i), depth, visited) {
+				return false
+			}
+		}
+		return true
+	case reflect.Bool:
+		return a.Bool() == b.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return a.Int() == b.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return a.Uint() == b.Uint()
+	case reflect.String:
+		return a.String() == b.String()
+	case reflect.Func:
+		return a.IsNil() && b.IsNil()
+	default:
+		// Channels and unsafe pointers are equal only if they are identical
+		return a.Pointer() == b.Pointer()
+	}
+}
+
+// floatEqual compares two floating-point numbers, honouring the configured
+// tolerance. With a tolerance, NaNs are considered equal to each other.
+func (c *config) floatEqual(a, b float64) bool {
+	if a == b {
+		return true
+	}
+	if !c.tolerant {
+		return false
+	}
+	if math.IsNaN(a) || math.IsNaN(b) {
+		return math.IsNaN(a) && math.IsNaN(b)
+	}
+	if math.IsInf(a, 0) || math.IsInf(b, 0) {
+		return false
+	}
+	diff := math.Abs(a - b)
+	return diff <= c.absTol || diff <= c.relTol*math.Max(math.Abs(a), math.Abs(b))
+}
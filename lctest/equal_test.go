@@ -0,0 +1,61 @@
+package lctest
+
+import (
+	"math"
+	"testing"
+)
+
+type listNode struct {
+	val  float64
+	next *listNode
+}
+
+func TestEqual(t *testing.T) {
+	cyclic := &listNode{val: 1}
+	cyclic.next = cyclic
+	otherCyclic := &listNode{val: 1}
+	otherCyclic.next = otherCyclic
+
+	tolerance := Tolerance(DefaultAbsTolerance, DefaultRelTolerance)
+	tests := []struct {
+		name     string
+		got      any
+		want     any
+		opts     []Option
+		expected bool
+	}{
+		{"equal ints", 1, 1, nil, true},
+		{"different ints", 1, 2, nil, false},
+		{"different types", 1, int64(1), nil, false},
+		{"nil values", nil, nil, nil, true},
+		{"nil and value", nil, 1, nil, false},
+		{"equal slices", []int{1, 2}, []int{1, 2}, nil, true},
+		{"different lengths", []int{1, 2}, []int{1}, nil, false},
+		{"nil and empty slice", []int(nil), []int{}, nil, true},
+		{"nil and non-empty slice", []int(nil), []int{1}, nil, false},
+		{"nested nil and empty slices", [][]int{nil}, [][]int{{}}, nil, true},
+		{"nil and empty map", map[string]int(nil), map[string]int{}, nil, true},
+		{"nil and empty pointer", (*listNode)(nil), &listNode{}, nil, false},
+		{"nested slices", [][]string{{"a"}, {"b", "c"}}, [][]string{{"a"}, {"b", "c"}}, nil, true},
+		{"maps", map[string]int{"a": 1}, map[string]int{"a": 1}, nil, true},
+		{"different maps", map[string]int{"a": 1}, map[string]int{"b": 1}, nil, false},
+		{"exact floats", 0.1, 0.1 + 1e-9, nil, false},
+		{"tolerant floats", 0.1, 0.1 + 1e-9, []Option{tolerance}, true},
+		{"outside tolerance", 0.1, 0.2, []Option{tolerance}, false},
+		{"relative tolerance", 1e10, 1e10 + 1e4, []Option{Tolerance(0, 1e-5)}, true},
+		{"nested floats", []listNode{{val: 0.5}}, []listNode{{val: 0.5 + 1e-7}}, []Option{tolerance}, true},
+		{"float pointers", &listNode{val: 1, next: &listNode{val: 2}}, &listNode{val: 1, next: &listNode{val: 2 + 1e-7}}, []Option{tolerance}, true},
+		{"nan without tolerance", math.NaN(), math.NaN(), nil, false},
+		{"nan with tolerance", math.NaN(), math.NaN(), []Option{tolerance}, true},
+		{"infinities", math.Inf(1), math.Inf(-1), []Option{tolerance}, false},
+		{"cyclic values", cyclic, otherCyclic, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := Equal(tt.got, tt.want, tt.opts...); result != tt.expected {
+				t.Errorf("Equal(%v, %v) = %v; want %v", tt.got, tt.want, result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,388 @@
+package lctest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// Decode builds a value of type T from its LeetCode notation, such as
+// "[1,2,3]" for a linked list or "[3,9,20,null,null,15,7]" for a binary tree.
+//
+// Linked lists are pointers to structs with Val and Next fields, and binary
+// trees pointers to structs with Val, Left and Right fields; they are read
+// from flat arrays, trees in level order with null for missing children.
+// Slices, arrays, pointers, numbers, strings and booleans are read as in
+// JSON, and bytes or runes may also be written as one-character strings.
+//
+// Parameters:
+//   - notation: The value in LeetCode notation
+//
+// Returns:
+//   - T: The decoded value
+//   - error: An error if the notation is malformed or does not fit type T
+func Decode[T any](notation string) (T, error) {
+	var v T
+	dec := json.NewDecoder(strings.NewReader(notation))
+	dec.UseNumber()
+	var raw any
+	if err := dec.Decode(&raw); err != nil {
+		return v, fmt.Errorf("parsing %q: %v", notation, err)
+	}
+	if dec.More() {
+		return v, fmt.Errorf("parsing %q: unexpected data after the value", notation)
+	}
+
+	rv := reflect.ValueOf(&v).Elem()
+	if err := decodeInto(rv, raw); err != nil {
+		return v, fmt.Errorf("decoding %q into %s: %v", notation, rv.Type(), err)
+	}
+	return v, nil
+}
+
+// MustDecode is like Decode but fails the test immediately if the notation
+// cannot be decoded.
+func MustDecode[T any](tb testing.TB, notation string) T {
+	tb.Helper()
+	v, err := Decode[T](notation)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return v
+}
+
+// Encode renders v in LeetCode notation, the inverse of Decode.
+// Trailing nulls are left out of binary trees, and cycles in linked lists are
+// cut short with "...".
+func Encode(v any) string {
+	var buf bytes.Buffer
+	encode(&buf, reflect.ValueOf(v))
+	return buf.String()
+}
+
+// nodeKind tells the linked structures LeetCode writes as flat arrays apart.
+type nodeKind int
+
+const (
+	notANode nodeKind = iota
+	listNodeKind
+	treeNodeKind
+)
+
+// nodeKindOf classifies a type as a linked list or binary tree node pointer.
+func nodeKindOf(t reflect.Type) nodeKind {
+	if t.Kind() != reflect.Pointer || t.Elem().Kind() != reflect.Struct {
+		return notANode
+	}
+	s := t.Elem()
+	if _, ok := s.FieldByName("Val"); !ok {
+		return notANode
+	}
+	isLink := func(name string) bool {
+		f, ok := s.FieldByName(name)
+		return ok && f.Type == t
+	}
+	switch {
+	case isLink("Next"):
+		return listNodeKind
+	case isLink("Left") && isLink("Right"):
+		return treeNodeKind
+	}
+	return notANode
+}
+
+// hasNodes reports whether t is a linked list or binary tree node pointer, or
+// a slice, array or pointer leading to one.
+func hasNodes(t reflect.Type) bool {
+	if nodeKindOf(t) != notANode {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Pointer:
+		return hasNodes(t.Elem())
+	}
+	return false
+}
+
+func decodeInto(dst reflect.Value, raw any) error {
+	switch nodeKindOf(dst.Type()) {
+	case listNodeKind:
+		return decodeList(dst, raw)
+	case treeNodeKind:
+		return decodeTree(dst, raw)
+	}
+
+	if raw == nil {
+		switch dst.Kind() {
+		case reflect.Pointer, reflect.Slice, reflect.Map, reflect.Interface:
+			dst.SetZero()
+			return nil
+		}
+		return fmt.Errorf("null is not a valid %s", dst.Type())
+	}
+
+	switch dst.Kind() {
+	case reflect.Pointer:
+		p := reflect.New(dst.Type().Elem())
+		if err := decodeInto(p.Elem(), raw); err != nil {
+			return err
+		}
+		dst.Set(p)
+	case reflect.Slice:
+		items, ok := raw.([]any)
+		if !ok {
+			return fmt.Errorf("expected an array for %s, got %v", dst.Type(), raw)
+		}
+		s := reflect.MakeSlice(dst.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := decodeInto(s.Index(i), item); err != nil {
+				return fmt.Errorf("[%d]: %v", i, err)
+			}
+		}
+		dst.Set(s)
+	case reflect.Array:
+		items, ok := raw.([]any)
+		if !ok || len(items) != dst.Len() {
+			return fmt.Errorf("expected an array of %d elements for %s, got %v", dst.Len(), dst.Type(), raw)
+		}
+		for i, item := range items {
+			if err := decodeInto(dst.Index(i), item); err != nil {
+				return fmt.Errorf("[%d]: %v", i, err)
+			}
+		}
+	case reflect.Interface:
+		dst.Set(reflect.ValueOf(plain(raw)))
+	case reflect.String:
+		s, ok := raw.(string)
+		if !ok {
+			return fmt.Errorf("expected a string, got %v", raw)
+		}
+		dst.SetString(s)
+	case reflect.Bool:
+		b, ok := raw.(bool)
+		if !ok {
+			return fmt.Errorf("expected a boolean, got %v", raw)
+		}
+		dst.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if s, ok := raw.(string); ok && dst.Kind() == reflect.Int32 && utf8.RuneCountInString(s) == 1 {
+			r, _ := utf8.DecodeRuneInString(s)
+			dst.SetInt(int64(r))
+			return nil
+		}
+		n, ok := raw.(json.Number)
+		if !ok {
+			return fmt.Errorf("expected an integer, got %v", raw)
+		}
+		i, err := strconv.ParseInt(n.String(), 10, dst.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid %s %s", dst.Type(), n)
+		}
+		dst.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if s, ok := raw.(string); ok && dst.Kind() == reflect.Uint8 && len(s) == 1 {
+			dst.SetUint(uint64(s[0]))
+			return nil
+		}
+		n, ok := raw.(json.Number)
+		if !ok {
+			return fmt.Errorf("expected an integer, got %v", raw)
+		}
+		u, err := strconv.ParseUint(n.String(), 10, dst.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid %s %s", dst.Type(), n)
+		}
+		dst.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		n, ok := raw.(json.Number)
+		if !ok {
+			return fmt.Errorf("expected a number, got %v", raw)
+		}
+		f, err := strconv.ParseFloat(n.String(), dst.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid %s %s", dst.Type(), n)
+		}
+		dst.SetFloat(f)
+	default:
+		return fmt.Errorf("%s cannot be written in LeetCode notation", dst.Type())
+	}
+	return nil
+}
+
+// decodeList builds a linked list from a flat array of values.
+func decodeList(dst reflect.Value, raw any) error {
+	if raw == nil {
+		dst.SetZero()
+		return nil
+	}
+	items, ok := raw.([]any)
+	if !ok {
+		return fmt.Errorf("expected an array for %s, got %v", dst.Type(), raw)
+	}
+
+	link := dst
+	for i, item := range items {
+		node := reflect.New(dst.Type().Elem())
+		if err := decodeInto(node.Elem().FieldByName("Val"), item); err != nil {
+			return fmt.Errorf("[%d]: %v", i, err)
+		}
+		link.Set(node)
+		link = node.Elem().FieldByName("Next")
+	}
+	return nil
+}
+
+// decodeTree builds a binary tree from its level order, where null marks a
+// missing child.
+func decodeTree(dst reflect.Value, raw any) error {
+	if raw == nil {
+		dst.SetZero()
+		return nil
+	}
+	items, ok := raw.([]any)
+	if !ok {
+		return fmt.Errorf("expected an array for %s, got %v", dst.Type(), raw)
+	}
+	if len(items) == 0 || items[0] == nil {
+		dst.SetZero()
+		return nil
+	}
+
+	newNode := func(i int) (reflect.Value, error) {
+		node := reflect.New(dst.Type().Elem())
+		if err := decodeInto(node.Elem().FieldByName("Val"), items[i]); err != nil {
+			return reflect.Value{}, fmt.Errorf("[%d]: %v", i, err)
+		}
+		return node, nil
+	}
+	root, err := newNode(0)
+	if err != nil {
+		return err
+	}
+	queue := []reflect.Value{root}
+	for i := 1; i < len(items); {
+		if len(queue) == 0 {
+			return fmt.Errorf("[%d]: value without a parent", i)
+		}
+		parent := queue[0]
+		queue = queue[1:]
+		for _, side := range []string{"Left", "Right"} {
+			if i >= len(items) {
+				break
+			}
+			if items[i] != nil {
+				child, err := newNode(i)
+				if err != nil {
+					return err
+				}
+				parent.Elem().FieldByName(side).Set(child)
+				queue = append(queue, child)
+			}
+			i++
+		}
+	}
+	dst.Set(root)
+	return nil
+}
+
+// plain converts the numbers of a decoded JSON value to int or float64.
+func plain(raw any) any {
+	switch v := raw.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return int(i)
+		}
+		f, _ := v.Float64()
+		return f
+	case []any:
+		for i := range v {
+			v[i] = plain(v[i])
+		}
+	}
+	return raw
+}
+
+func encode(buf *bytes.Buffer, v reflect.Value) {
+	if !v.IsValid() {
+		buf.WriteString("null")
+		return
+	}
+
+	switch nodeKindOf(v.Type()) {
+	case listNodeKind:
+		encodeList(buf, v)
+		return
+	case treeNodeKind:
+		encodeTree(buf, v)
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			buf.WriteString("null")
+			return
+		}
+		encode(buf, v.Elem())
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			buf.WriteString("[]")
+			return
+		}
+		buf.WriteByte('[')
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			encode(buf, v.Index(i))
+		}
+		buf.WriteByte(']')
+	case reflect.String:
+		buf.WriteString(strconv.Quote(v.String()))
+	case reflect.Uint8:
+		buf.WriteString(strconv.Quote(string(rune(v.Uint()))))
+	default:
+		fmt.Fprintf(buf, "%v", v)
+	}
+}
+
+func encodeList(buf *bytes.Buffer, v reflect.Value) {
+	seen := make(map[uintptr]bool)
+	buf.WriteByte('[')
+	for node := v; !node.IsNil(); node = node.Elem().FieldByName("Next") {
+		if seen[node.Pointer()] {
+			buf.WriteString(",...")
+			break
+		}
+		seen[node.Pointer()] = true
+		if len(seen) > 1 {
+			buf.WriteByte(',')
+		}
+		encode(buf, node.Elem().FieldByName("Val"))
+	}
+	buf.WriteByte(']')
+}
+
+func encodeTree(buf *bytes.Buffer, v reflect.Value) {
+	var items []string
+	for queue := []reflect.Value{v}; len(queue) > 0; queue = queue[1:] {
+		node := queue[0]
+		if node.IsNil() {
+			items = append(items, "null")
+			continue
+		}
+		var item bytes.Buffer
+		encode(&item, node.Elem().FieldByName("Val"))
+		items = append(items, item.String())
+		queue = append(queue, node.Elem().FieldByName("Left"), node.Elem().FieldByName("Right"))
+	}
+	for len(items) > 0 && items[len(items)-1] == "null" {
+		items = items[:len(items)-1]
+	}
+	buf.WriteString("[" + strings.Join(items, ",") + "]")
+}
@@ -0,0 +1,110 @@
+package lctest
+
+import (
+	"reflect"
+	"testing"
+)
+
+type ListNode struct {
+	Val  int
+	Next *ListNode
+}
+
+type TreeNode struct {
+	Val   int
+	Left  *TreeNode
+	Right *TreeNode
+}
+
+func TestDecodeList(t *testing.T) {
+	head, err := Decode[*ListNode]("[1, 2, 3]")
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	expected := &ListNode{Val: 1, Next: &ListNode{Val: 2, Next: &ListNode{Val: 3}}}
+	if !reflect.DeepEqual(head, expected) {
+		t.Errorf("Decode() = %s; want %s", Encode(head), Encode(expected))
+	}
+
+	if empty, err := Decode[*ListNode]("[]"); err != nil || empty != nil {
+		t.Errorf("Decode([]) = %v, %v; want nil list", empty, err)
+	}
+	lists, err := Decode[[]*ListNode]("[[1,4,5],[1,3,4],[]]")
+	if err != nil || len(lists) != 3 || lists[2] != nil || Encode(lists[1]) != "[1,3,4]" {
+		t.Errorf("Decode([]*ListNode) = %s, %v", Encode(lists), err)
+	}
+}
+
+func TestDecodeTree(t *testing.T) {
+	root, err := Decode[*TreeNode]("[3,9,20,null,null,15,7]")
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	expected := &TreeNode{
+		Val:   3,
+		Left:  &TreeNode{Val: 9},
+		Right: &TreeNode{Val: 20, Left: &TreeNode{Val: 15}, Right: &TreeNode{Val: 7}},
+	}
+	if !reflect.DeepEqual(root, expected) {
+		t.Errorf("Decode() = %s; want %s", Encode(root), Encode(expected))
+	}
+
+	skewed, err := Decode[*TreeNode]("[1,null,2,3]")
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if skewed.Left != nil || skewed.Right.Left.Val != 3 {
+		t.Errorf("Decode([1,null,2,3]) = %s", Encode(skewed))
+	}
+}
+
+func TestDecodeValues(t *testing.T) {
+	board, err := Decode[[][]byte](`[["5","3"],[".","7"]]`)
+	if err != nil || !reflect.DeepEqual(board, [][]byte{{'5', '3'}, {'.', '7'}}) {
+		t.Errorf("Decode([][]byte) = %v, %v", board, err)
+	}
+	floats, err := Decode[[]float64]("[1, 2.5]")
+	if err != nil || !reflect.DeepEqual(floats, []float64{1, 2.5}) {
+		t.Errorf("Decode([]float64) = %v, %v", floats, err)
+	}
+
+	for _, notation := range []string{"[1,", "[1] 2", `["a"]`, "[1.5]", "null"} {
+		if v, err := Decode[[]int](notation); err == nil && notation != "null" {
+			t.Errorf("Decode(%q) = %v; want an error", notation, v)
+		}
+	}
+	if _, err := Decode[int]("null"); err == nil {
+		t.Errorf("Decode[int](null) succeeded; want an error")
+	}
+	if _, err := Decode[*TreeNode]("[1,2,null,null,null,3]"); err == nil {
+		t.Errorf("Decode() of a tree with an orphan value succeeded; want an error")
+	}
+}
+
+func TestEncode(t *testing.T) {
+	cyclic := &ListNode{Val: 1, Next: &ListNode{Val: 2}}
+	cyclic.Next.Next = cyclic
+
+	tests := []struct {
+		name     string
+		value    any
+		expected string
+	}{
+		{"list", &ListNode{Val: 1, Next: &ListNode{Val: 2}}, "[1,2]"},
+		{"nil list", (*ListNode)(nil), "[]"},
+		{"cyclic list", cyclic, "[1,2,...]"},
+		{"tree", &TreeNode{Val: 1, Right: &TreeNode{Val: 2, Left: &TreeNode{Val: 3}}}, "[1,null,2,3]"},
+		{"nil tree", (*TreeNode)(nil), "[]"},
+		{"strings", []string{"a", "b"}, `["a","b"]`},
+		{"bytes", []byte{'5', '.'}, `["5","."]`},
+		{"numbers", [][]int{{1}, {}}, "[[1],[]]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := Encode(tt.value); result != tt.expected {
+				t.Errorf("Encode() = %s; want %s", result, tt.expected)
+			}
+		})
+	}
+}
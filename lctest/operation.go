@@ -0,0 +1,101 @@
+package lctest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// Operation is a single call in the test of a LeetCode design problem, such
+// as LRU Cache, which constructs an object and then calls its methods.
+type Operation struct {
+	// Index is the position of the operation in the sequence.
+	Index int
+	// Name is the constructor or method name as LeetCode spells it, e.g.
+	// "LRUCache" or "put".
+	Name string
+	// Args holds the arguments in LeetCode notation, ready for Decode.
+	Args []string
+	// Expected is the expected return value in LeetCode notation, which is
+	// "null" for constructors and methods without a result.
+	Expected string
+}
+
+// Operations splits the three arrays LeetCode uses to describe the test of a
+// design problem into single operations.
+//
+// Example:
+//
+//	operations: `["LRUCache","put","get"]`
+//	arguments: `[[2],[1,1],[1]]`
+//	expected: `[null,null,1]`
+//
+// Parameters:
+//   - operations: The array of operation names
+//   - arguments: The array of argument arrays, one per operation
+//   - expected: The array of expected return values, one per operation
+//
+// Returns:
+//   - []Operation: The operations in order
+//   - error: An error if an array is malformed or the lengths do not match
+func Operations(operations, arguments, expected string) ([]Operation, error) {
+	var names []string
+	if err := json.Unmarshal([]byte(operations), &names); err != nil {
+		return nil, fmt.Errorf("parsing operations %q: %v", operations, err)
+	}
+	var args [][]json.RawMessage
+	if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+		return nil, fmt.Errorf("parsing arguments %q: %v", arguments, err)
+	}
+	var results []json.RawMessage
+	if err := json.Unmarshal([]byte(expected), &results); err != nil {
+		return nil, fmt.Errorf("parsing expected results %q: %v", expected, err)
+	}
+	if len(args) != len(names) || len(results) != len(names) {
+		return nil, fmt.Errorf("%d operations with %d argument lists and %d expected results", len(names), len(args), len(results))
+	}
+
+	ops := make([]Operation, len(names))
+	for i, name := range names {
+		ops[i] = Operation{Index: i, Name: name, Args: make([]string, len(args[i])), Expected: compact(results[i])}
+		for j, arg := range args[i] {
+			ops[i].Args[j] = compact(arg)
+		}
+	}
+	return ops, nil
+}
+
+// MustOperations is like Operations but fails the test immediately if the
+// arrays cannot be parsed.
+func MustOperations(tb testing.TB, operations, arguments, expected string) []Operation {
+	tb.Helper()
+	ops, err := Operations(operations, arguments, expected)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return ops
+}
+
+// CheckArgs fails the test immediately unless the operation has n arguments.
+func (op Operation) CheckArgs(tb testing.TB, n int) {
+	tb.Helper()
+	if len(op.Args) != n {
+		tb.Fatalf("operation %d: %s takes %d arguments, got %d", op.Index, op.Name, n, len(op.Args))
+	}
+}
+
+// String renders the operation as a call, e.g. "put(1,1)".
+func (op Operation) String() string {
+	return fmt.Sprintf("%s(%s)", op.Name, strings.Join(op.Args, ","))
+}
+
+// compact removes the insignificant spaces from a JSON value.
+func compact(raw json.RawMessage) string {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, raw); err != nil {
+		return string(raw)
+	}
+	return buf.String()
+}
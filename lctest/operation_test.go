@@ -0,0 +1,41 @@
+package lctest
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestOperations(t *testing.T) {
+	ops, err := Operations(`["LRUCache", "put", "get"]`, `[[2], [1, 1], [1]]`, `[null, null, 1]`)
+	if err != nil {
+		t.Fatalf("Operations() error = %v", err)
+	}
+	expected := []Operation{
+		{Index: 0, Name: "LRUCache", Args: []string{"2"}, Expected: "null"},
+		{Index: 1, Name: "put", Args: []string{"1", "1"}, Expected: "null"},
+		{Index: 2, Name: "get", Args: []string{"1"}, Expected: "1"},
+	}
+	if !reflect.DeepEqual(ops, expected) {
+		t.Errorf("Operations() = %+v; want %+v", ops, expected)
+	}
+	if result := ops[1].String(); result != "put(1,1)" {
+		t.Errorf("String() = %q; want %q", result, "put(1,1)")
+	}
+
+	tests := []struct {
+		name                            string
+		operations, arguments, expected string
+	}{
+		{"malformed operations", `["Trie",`, `[[]]`, `[null]`},
+		{"arguments not arrays", `["Trie"]`, `[1]`, `[null]`},
+		{"missing arguments", `["Trie","insert"]`, `[[]]`, `[null,null]`},
+		{"missing results", `["Trie","insert"]`, `[[],["a"]]`, `[null]`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := Operations(test.operations, test.arguments, test.expected); err == nil {
+				t.Errorf("Operations(%s, %s, %s) = nil error; want error", test.operations, test.arguments, test.expected)
+			}
+		})
+	}
+}
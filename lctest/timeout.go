@@ -0,0 +1,30 @@
+package lctest
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// Timeout makes the test binary fail if the test does not finish within d,
+// which catches solutions that loop forever or run far too long. Like the
+// -timeout flag of go test, it panics, since a running function cannot be
+// stopped; the panic names the test that timed out.
+//
+// Example:
+//
+//	defer lctest.Timeout(t, 2*time.Second)()
+//
+// Parameters:
+//   - tb: The running test
+//   - d: The time the test may take
+//
+// Returns:
+//   - func(): A function to call when the test finishes, stopping the timer
+func Timeout(tb testing.TB, d time.Duration) func() {
+	name := tb.Name()
+	timer := time.AfterFunc(d, func() {
+		panic(fmt.Sprintf("%s timed out after %v", name, d))
+	})
+	return func() { timer.Stop() }
+}
@@ -0,0 +1,13 @@
+package lctest
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimeout(t *testing.T) {
+	// A test finishing in time stops the timer before it fires
+	stop := Timeout(t, 20*time.Millisecond)
+	stop()
+	time.Sleep(40 * time.Millisecond)
+}
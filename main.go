@@ -1,8 +1,15 @@
 package main
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/urfave/cli/v2"
 
@@ -16,150 +23,326 @@ func main() {
 		Usage: "Generate test files for LeetCode solution Go source files",
 		Commands: []*cli.Command{
 			{
-				Name:  "init",
-				Usage: "Initialize a test case file for a Go source file",
+				Name:      "init",
+				Usage:     "Initialize test case files for Go source files",
+				ArgsUsage: "<source_file|dir|dir/...>...",
 				Flags: []cli.Flag{
 					&cli.BoolFlag{
 						Name:    "force",
 						Aliases: []string{"f"},
-						Usage:   "Force overwrite existing test case file",
+						Usage:   "Force overwrite existing test case files instead of adding missing functions",
 					},
+					&cli.BoolFlag{
+						Name:  "test-only",
+						Usage: "Create new test case files as <name>_testcase_test.go, keeping the cases out of the production build",
+					},
+					templatesFlag,
+					jobsFlag,
 				},
 				Action: func(c *cli.Context) error {
 					if c.NArg() < 1 {
-						return cli.Exit("Usage: leetcode-gen-test init <source_file> [--force]", 1)
+						return cli.Exit("Usage: leetcode-gen-test init <source_file|dir|dir/...>... [--force] [--test-only]", 1)
 					}
 
-					sourceFile := c.Args().Get(0)
-					force := c.Bool("force")
-
-					// Generate test case file name
-					testCaseFile := utils.TestCaseFileNameOf(sourceFile)
-					if testCaseFile == "" {
-						return cli.Exit("invalid source file name", 1)
+					gs, err := generatorsOf(c)
+					if err != nil {
+						return err
+					}
+					// Find source files with tagged functions in directories
+					sourceFiles, err := expandTargets(c.Args().Slice(), gs.isTaggedSourceFile)
+					if err != nil {
+						return cli.Exit(err, 1)
+					}
+					force, testOnly := c.Bool("force"), c.Bool("test-only")
+					return exitWith(runBatch(sourceFiles, c.Int("jobs"), func(sourceFile string) (string, outcome, error) {
+						return initFile(gs, sourceFile, force, testOnly)
+					}))
+				},
+			},
+			{
+				Name:      "generate",
+				Usage:     "Generate test files for Go source files",
+				ArgsUsage: "<source_file|dir|dir/...>...",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "test-case",
+						Aliases: []string{"t"},
+						Usage:   "Specify the test case file",
+					},
+					&cli.BoolFlag{
+						Name:    "force",
+						Aliases: []string{"f"},
+						Usage:   "Force overwrite existing test files that were not generated",
+					},
+					templatesFlag,
+					jobsFlag,
+				},
+				Action: func(c *cli.Context) error {
+					gs, err := generatorsOf(c)
+					if err != nil {
+						return err
 					}
-					if !force {
-						// Check if test case file already exists
-						if _, err := os.Stat(testCaseFile); err == nil {
-							fmt.Printf("test case file %s already exists\n", testCaseFile)
-							return nil
+					var sourceFiles []string
+					if c.String("test-case") != "" {
+						sourceFile, _, _, err := filesOf(c, "generate")
+						if err != nil {
+							return err
+						}
+						sourceFiles = []string{sourceFile}
+					} else {
+						if c.NArg() < 1 {
+							return cli.Exit("Usage: leetcode-gen-test generate <source_file|dir|dir/...>...", 1)
+						}
+						// Find test case files in directories, and process their source files
+						targets, err := expandTargets(c.Args().Slice(), isTestCaseFile)
+						if err != nil {
+							return cli.Exit(err, 1)
+						}
+						seen := make(map[string]bool)
+						for _, target := range targets {
+							if sourceFile := utils.SrcFileNameOf(target); sourceFile != "" {
+								target = sourceFile
+							}
+							// A source file may have test case files in both layouts
+							if !seen[target] {
+								seen[target] = true
+								sourceFiles = append(sourceFiles, target)
+							}
 						}
 					}
-
-					// Open source file
-					file, err := os.Open(sourceFile)
+					force := c.Bool("force")
+					return exitWith(runBatch(sourceFiles, c.Int("jobs"), func(sourceFile string) (string, outcome, error) {
+						return generateFile(gs, sourceFile, force)
+					}))
+				},
+			},
+			{
+				Name:  "check",
+				Usage: "Check that the test file and test case schema of a Go source file are up to date",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "test-case",
+						Aliases: []string{"t"},
+						Usage:   "Specify the test case file",
+					},
+					templatesFlag,
+				},
+				Action: func(c *cli.Context) error {
+					sourceFile, testCaseFile, testFile, err := filesOf(c, "check")
+					if err != nil {
+						return err
+					}
+					g, err := generatorOf(c, sourceFile)
 					if err != nil {
-						return cli.Exit(fmt.Errorf("failed to open file: %v", err), 1)
+						return err
 					}
-					if file == nil {
-						return cli.Exit(fmt.Errorf("nil file provided"), 1)
+
+					// Read source and test case files
+					srcContent, err := os.ReadFile(sourceFile)
+					if err != nil {
+						return cli.Exit(fmt.Errorf("failed to read source file: %v", err), 1)
 					}
-					defer file.Close()
-					// Read file content
-					content, err := os.ReadFile(file.Name())
+					testCaseContent, err := os.ReadFile(testCaseFile)
 					if err != nil {
-						return cli.Exit(fmt.Errorf("failed to read file content: %v", err), 1)
+						return cli.Exit(fmt.Errorf("failed to read test case file: %v", err), 1)
+					}
+					// A missing test file is stale as a whole
+					testContent, err := os.ReadFile(testFile)
+					if err != nil && !os.IsNotExist(err) {
+						return cli.Exit(fmt.Errorf("failed to read test file: %v", err), 1)
 					}
 
-					// Parse the source file
-					testCaseTemplates, err := codegen.GenerateTestCaseTemplates(content)
+					// Check the schema types against the current signatures
+					stale, err := g.CheckTestCaseSchema(sourceFile, srcContent, testCaseFile, testCaseContent)
 					if err != nil {
-						return cli.Exit(fmt.Errorf("failed to generate test case templates: %v", err), 1)
+						return cli.Exit(failure("check test case schema", err), 1)
+					}
+					for _, s := range stale {
+						fmt.Printf("%s: %s\n", testCaseFile, s)
 					}
-					// Create test case file
-					f, err := os.Create(testCaseFile)
+
+					// Compare the test file with a fresh one
+					testTemplates, warnings, err := g.GenerateTestTemplates(sourceFile, srcContent, testCaseFile, testCaseContent)
 					if err != nil {
-						return cli.Exit(fmt.Errorf("failed to create test case file: %v", err), 1)
+						return cli.Exit(failure("generate test templates", err), 1)
 					}
-					defer f.Close()
-					// Write test case templates to file
-					if _, err := f.Write(testCaseTemplates); err != nil {
-						return cli.Exit(fmt.Errorf("failed to write test case template: %v", err), 1)
+					warn(warnings)
+					diff := utils.UnifiedDiff(testFile, testFile+" (generated)", testContent, testTemplates)
+					fmt.Print(diff)
+
+					// Stale schema types are fixed by migrate, a stale test file by generate
+					switch {
+					case len(stale) > 0 && diff != "":
+						return cli.Exit(fmt.Sprintf("%s and %s are out of date, run leetcode-gen-test migrate, then leetcode-gen-test generate", testCaseFile, testFile), 1)
+					case len(stale) > 0:
+						return cli.Exit(fmt.Sprintf("%s is out of date, run leetcode-gen-test migrate", testCaseFile), 1)
+					case diff != "":
+						return cli.Exit(fmt.Sprintf("%s is out of date, run leetcode-gen-test generate", testFile), 1)
 					}
 					return nil
 				},
 			},
 			{
-				Name:  "generate",
-				Usage: "Generate a test file for a Go source file",
+				Name:  "migrate",
+				Usage: "Migrate the test cases of a Go source file to its current signatures",
 				Flags: []cli.Flag{
 					&cli.StringFlag{
 						Name:    "test-case",
 						Aliases: []string{"t"},
 						Usage:   "Specify the test case file",
 					},
+					templatesFlag,
 				},
 				Action: func(c *cli.Context) error {
-					var (
-						sourceFile   string
-						testCaseFile string
-					)
-					testCaseVal := c.String("test-case")
-					if testCaseVal == "" {
-						if c.NArg() < 1 {
-							return cli.Exit("Usage: leetcode-gen-test generate <source_file>", 1)
-						}
-						sourceFile = c.Args().Get(0)
-						testCaseFile = utils.TestCaseFileNameOf(sourceFile)
-						if testCaseFile == "" {
-							return cli.Exit("invalid source file name", 1)
-						}
-					} else {
-						testCaseFile = testCaseVal
-						sourceFile = utils.SrcFileNameOf(testCaseFile)
-						if sourceFile == "" {
-							return cli.Exit("invalid test case file name", 1)
-						}
+					sourceFile, testCaseFile, _, err := filesOf(c, "migrate")
+					if err != nil {
+						return err
+					}
+					g, err := generatorOf(c, sourceFile)
+					if err != nil {
+						return err
 					}
-					testFile := utils.TestFileNameOf(sourceFile)
-					if testFile == "" {
-						return cli.Exit("invalid source file name", 1)
+
+					// Read source and test case files
+					srcContent, err := os.ReadFile(sourceFile)
+					if err != nil {
+						return cli.Exit(fmt.Errorf("failed to read source file: %v", err), 1)
+					}
+					testCaseContent, err := os.ReadFile(testCaseFile)
+					if err != nil {
+						return cli.Exit(fmt.Errorf("failed to read test case file: %v", err), 1)
 					}
 
-					// Open source srcFile
-					srcFile, err := os.Open(sourceFile)
+					// Rewrite the stale schema types and their cases
+					migrated, types, problems, err := g.MigrateTestCases(sourceFile, srcContent, testCaseFile, testCaseContent)
 					if err != nil {
-						return cli.Exit(fmt.Errorf("failed to open source file: %v", err), 1)
+						return cli.Exit(failure("migrate test cases", err), 1)
 					}
-					if srcFile == nil {
-						return cli.Exit(fmt.Errorf("nil file provided"), 1)
+					if len(types) == 0 {
+						fmt.Printf("%s is up to date\n", testCaseFile)
+						return nil
+					}
+					if err := utils.WriteFileAtomic(testCaseFile, migrated, 0o644); err != nil {
+						return cli.Exit(fmt.Errorf("failed to write test case file: %v", err), 1)
+					}
+					fmt.Printf("migrated %s in %s\n", strings.Join(types, ", "), testCaseFile)
+					for _, problem := range problems {
+						fmt.Println(problem)
+					}
+					if len(problems) > 0 {
+						return cli.Exit(fmt.Sprintf("%d values need to be migrated by hand", len(problems)), 1)
+					}
+					return nil
+				},
+			},
+			{
+				Name:      "import",
+				Usage:     "Import the examples of a saved LeetCode problem description as test cases",
+				ArgsUsage: "<source_file> [problem_file]",
+				Description: "Reads the problem description, as HTML, Markdown or plain text, from problem_file, or from\n" +
+					"standard input if it is omitted or \"-\", and adds its examples to the test case file.",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "func",
+						Aliases: []string{"f"},
+						Usage:   "Import the examples as cases of the tagged function `NAME`, e.g. twoSum or Solution.twoSum",
+					},
+					&cli.BoolFlag{
+						Name:  "test-only",
+						Usage: "Create a new test case file as <name>_testcase_test.go, keeping the cases out of the production build",
+					},
+					templatesFlag,
+				},
+				Action: func(c *cli.Context) error {
+					if c.NArg() < 1 || c.NArg() > 2 {
+						return cli.Exit("Usage: leetcode-gen-test import <source_file> [problem_file] [--func NAME] [--test-only]", 1)
 					}
-					defer srcFile.Close()
-					// Open test case file
-					tcFile, err := os.Open(testCaseFile)
+					sourceFile, testCaseFile, _, err := filesOf(c, "import")
 					if err != nil {
-						return cli.Exit(fmt.Errorf("failed to open test case file: %v", err), 1)
+						return err
 					}
-					if tcFile == nil {
-						return cli.Exit(fmt.Errorf("nil file provided"), 1)
+					g, err := generatorOf(c, sourceFile)
+					if err != nil {
+						return err
 					}
-					defer tcFile.Close()
 
-					// Read file srcContent
-					srcContent, err := os.ReadFile(srcFile.Name())
+					// Read the examples of the problem
+					problem, err := readProblem(c.Args().Get(1))
+					if err != nil {
+						return cli.Exit(fmt.Errorf("failed to read problem description: %v", err), 1)
+					}
+					examples, err := codegen.ParseExamples(problem)
 					if err != nil {
-						return cli.Exit(fmt.Errorf("failed to read file content: %v", err), 1)
+						return cli.Exit(fmt.Errorf("failed to parse problem description: %v", err), 1)
 					}
-					// Read file testCaseContent
-					testCaseContent, err := os.ReadFile(tcFile.Name())
+
+					// Read source and test case files, the latter created if missing
+					srcContent, err := os.ReadFile(sourceFile)
 					if err != nil {
-						return cli.Exit(fmt.Errorf("failed to read file content: %v", err), 1)
+						return cli.Exit(fmt.Errorf("failed to read source file: %v", err), 1)
+					}
+					testCaseContent, err := os.ReadFile(testCaseFile)
+					if os.IsNotExist(err) {
+						if c.Bool("test-only") {
+							testCaseFile = utils.TestOnlyTestCaseFileNameOf(sourceFile)
+						}
+					} else if err != nil {
+						return cli.Exit(fmt.Errorf("failed to read test case file: %v", err), 1)
 					}
 
-					// Parse the file
-					testTemplates, err := codegen.GenerateTestTemplates(srcContent, testCaseContent)
+					imported, added, problems, warnings, err := g.ImportExamples(sourceFile, srcContent, testCaseFile, testCaseContent, c.String("func"), examples)
 					if err != nil {
-						return cli.Exit(fmt.Errorf("failed to generate test templates: %v", err), 1)
+						return cli.Exit(failure("import examples", err), 1)
+					}
+					warn(warnings)
+					if len(added) == 0 {
+						fmt.Printf("%s has all the examples\n", testCaseFile)
+					} else {
+						if err := utils.WriteFileAtomic(testCaseFile, imported, 0o644); err != nil {
+							return cli.Exit(fmt.Errorf("failed to write test case file: %v", err), 1)
+						}
+						fmt.Printf("imported %s into %s\n", strings.Join(added, ", "), testCaseFile)
+					}
+					for _, problem := range problems {
+						fmt.Println(problem)
+					}
+					if len(problems) > 0 {
+						return cli.Exit(fmt.Sprintf("%d examples need to be added by hand", len(problems)), 1)
+					}
+					return nil
+				},
+			},
+			{
+				Name:      "watch",
+				Usage:     "Regenerate and run the tests of Go source files as they change",
+				ArgsUsage: "[dir]",
+				Flags: []cli.Flag{
+					&cli.DurationFlag{
+						Name:    "interval",
+						Aliases: []string{"i"},
+						Usage:   "Time between two polls for changes",
+						Value:   500 * time.Millisecond,
+					},
+					templatesFlag,
+				},
+				Action: func(c *cli.Context) error {
+					root := "."
+					if c.NArg() > 0 {
+						root = c.Args().Get(0)
 					}
-					// Create test file
-					f, err := os.Create(testFile)
+					if c.Duration("interval") <= 0 {
+						return cli.Exit("the interval must be positive", 1)
+					}
+					gs, err := generatorsOf(c)
 					if err != nil {
-						return cli.Exit(fmt.Errorf("failed to create test file: %v", err), 1)
+						return err
 					}
-					defer f.Close()
-					// Write test templates to file
-					if _, err := f.Write(testTemplates); err != nil {
-						return cli.Exit(fmt.Errorf("failed to write test template: %v", err), 1)
+
+					// Watch until interrupted
+					ctx, stop := signal.NotifyContext(c.Context, os.Interrupt)
+					defer stop()
+					if err := watch(ctx, gs, root, c.Duration("interval"), os.Stdout); err != nil {
+						return cli.Exit(fmt.Errorf("failed to watch %s: %v", root, err), 1)
 					}
 					return nil
 				},
@@ -172,3 +355,227 @@ func main() {
 		fmt.Println(err)
 	}
 }
+
+// jobsFlag bounds the number of files processed concurrently.
+var jobsFlag = &cli.IntFlag{
+	Name:    "jobs",
+	Aliases: []string{"j"},
+	Usage:   "Number of files to process concurrently",
+	Value:   runtime.NumCPU(),
+}
+
+// isTestCaseFile reports whether a file found in a directory is a test case file.
+func isTestCaseFile(path string) bool {
+	return utils.SrcFileNameOf(path) != ""
+}
+
+// exitWith prints the summary of a batch, exiting with an error if a file failed.
+func exitWith(s summary) error {
+	if s.failed > 0 {
+		return cli.Exit(s.String(), 1)
+	}
+	fmt.Println(s)
+	return nil
+}
+
+// failure describes an error of a codegen step. Problems located in files are
+// kept as codegen.Diagnostics, which print one per line in
+// "file:line:col: message" form so that editors can jump to them, while
+// other errors are prefixed with the step that failed.
+func failure(step string, err error) error {
+	ds := codegen.DiagnosticsOf(err)
+	for _, d := range ds {
+		if d.Pos.Filename == "" {
+			return fmt.Errorf("failed to %s: %v", step, err)
+		}
+	}
+	return ds
+}
+
+// warnMu keeps the warnings about a file together when files are processed
+// concurrently.
+var warnMu sync.Mutex
+
+// warn prints warnings, such as type errors that did not prevent generating
+// templates, in "file:line:col: warning: message" form.
+func warn(warnings codegen.Diagnostics) {
+	warnMu.Lock()
+	defer warnMu.Unlock()
+	for _, w := range warnings {
+		fmt.Fprintf(os.Stderr, "%v: warning: %s\n", w.Pos, w.Message)
+	}
+}
+
+// initFile creates the test case file of a source file. An existing test case
+// file, in either layout, gets the templates of the functions it does not
+// cover yet, unless it is overwritten.
+//
+// Parameters:
+//   - gs: The generators of the source files
+//   - sourceFile: The path of the source file
+//   - force: Whether an existing test case file is overwritten
+//   - testOnly: Whether a new test case file is created as a test-only
+//     <name>_testcase_test.go file, which is left out of the production build
+//
+// Returns:
+//   - string: The path of the test case file
+//   - outcome: Whether the test case file was created, updated or left as is
+//   - error: An error if the test case file cannot be generated or written
+func initFile(gs *generators, sourceFile string, force, testOnly bool) (string, outcome, error) {
+	// The configuration of the project names the test case file
+	g, err := gs.of(sourceFile)
+	if err != nil {
+		return "", skipped, err
+	}
+	testCaseFile := utils.FindTestCaseFile(sourceFile)
+	if testCaseFile == "" {
+		return "", skipped, fmt.Errorf("invalid source file name")
+	}
+	if _, err := os.Stat(testCaseFile); err != nil && testOnly {
+		testCaseFile = utils.TestOnlyTestCaseFileNameOf(sourceFile)
+	}
+	content, err := os.ReadFile(sourceFile)
+	if err != nil {
+		return testCaseFile, skipped, fmt.Errorf("failed to read file content: %v", err)
+	}
+
+	o := created
+	if testCaseContent, err := os.ReadFile(testCaseFile); err == nil {
+		if !force {
+			// Add the functions the test case file lacks, keeping its cases
+			merged, added, warnings, err := g.MergeTestCaseTemplates(sourceFile, content, testCaseFile, testCaseContent)
+			if err != nil {
+				return testCaseFile, skipped, failure("merge test case templates", err)
+			}
+			warn(warnings)
+			if len(added) == 0 {
+				return testCaseFile, skipped, nil
+			}
+			if err := utils.WriteFileAtomic(testCaseFile, merged, 0o644); err != nil {
+				return testCaseFile, updated, fmt.Errorf("failed to write test case template: %v", err)
+			}
+			return testCaseFile, updated, nil
+		}
+		o = updated
+	}
+
+	testCaseTemplates, warnings, err := g.GenerateTestCaseTemplates(sourceFile, content)
+	if err != nil {
+		return testCaseFile, o, failure("generate test case templates", err)
+	}
+	warn(warnings)
+	if err := utils.WriteFileAtomic(testCaseFile, testCaseTemplates, 0o644); err != nil {
+		return testCaseFile, o, fmt.Errorf("failed to write test case template: %v", err)
+	}
+	return testCaseFile, o, nil
+}
+
+// generateFile generates the test file of a source file from its test case
+// file. A test file that is already up to date is left untouched, and so is
+// a hand-written one unless forced.
+//
+// Parameters:
+//   - gs: The generators of the source files
+//   - sourceFile: The path of the source file
+//   - force: Whether an existing test file that was not generated is overwritten
+//
+// Returns:
+//   - string: The path of the test file
+//   - outcome: Whether the test file was created, updated or already up to date
+//   - error: An error if the test file cannot be generated or written
+func generateFile(gs *generators, sourceFile string, force bool) (string, outcome, error) {
+	// The configuration of the project names the test case file
+	g, err := gs.of(sourceFile)
+	if err != nil {
+		return "", skipped, err
+	}
+	testCaseFile := utils.FindTestCaseFile(sourceFile)
+	testFile := utils.TestFileNameOf(sourceFile)
+	if testCaseFile == "" || testFile == "" {
+		return "", skipped, fmt.Errorf("invalid source file name")
+	}
+
+	srcContent, err := os.ReadFile(sourceFile)
+	if err != nil {
+		return testFile, skipped, fmt.Errorf("failed to read source file: %v", err)
+	}
+	testCaseContent, err := os.ReadFile(testCaseFile)
+	if err != nil {
+		return testFile, skipped, fmt.Errorf("failed to read test case file: %v", err)
+	}
+	testTemplates, warnings, err := g.GenerateTestTemplates(sourceFile, srcContent, testCaseFile, testCaseContent)
+	if err != nil {
+		return testFile, skipped, failure("generate test templates", err)
+	}
+	warn(warnings)
+	hintLctestRequirement(testFile, testTemplates)
+
+	o := created
+	if testContent, err := os.ReadFile(testFile); err == nil {
+		if bytes.Equal(testContent, testTemplates) {
+			return testFile, skipped, nil
+		}
+		if !force && !codegen.IsGeneratedTestFile(testContent) {
+			return testFile, skipped, fmt.Errorf("%s was not generated by leetcode-gen-test, use --force to overwrite it", testFile)
+		}
+		o = updated
+	}
+	if err := utils.WriteFileAtomic(testFile, testTemplates, 0o644); err != nil {
+		return testFile, o, fmt.Errorf("failed to write test template: %v", err)
+	}
+	return testFile, o, nil
+}
+
+// readProblem reads a problem description from a file, or from standard
+// input if the path is empty or "-".
+func readProblem(path string) ([]byte, error) {
+	if path == "" || path == "-" {
+		return io.ReadAll(os.Stdin)
+	}
+	return os.ReadFile(path)
+}
+
+// filesOf resolves the source, test case and test files a command operates on,
+// either from the source file argument or from the --test-case flag.
+//
+// Parameters:
+//   - c: The context of the command
+//   - command: The name of the command, used in the usage message
+//
+// Returns:
+//   - sourceFile: The path of the source file
+//   - testCaseFile: The path of the test case file
+//   - testFile: The path of the test file
+//   - err: An error to exit with if the files cannot be resolved
+func filesOf(c *cli.Context, command string) (sourceFile, testCaseFile, testFile string, err error) {
+	testCaseVal := c.String("test-case")
+	// The configuration of the project names the files
+	target := testCaseVal
+	if target == "" {
+		target = c.Args().Get(0)
+	}
+	if _, err := utils.ConfigOf(target); err != nil {
+		return "", "", "", cli.Exit(fmt.Sprintf("failed to load config: %v", err), 1)
+	}
+	if testCaseVal == "" {
+		if c.NArg() < 1 {
+			return "", "", "", cli.Exit(fmt.Sprintf("Usage: leetcode-gen-test %s <source_file>", command), 1)
+		}
+		sourceFile = c.Args().Get(0)
+		testCaseFile = utils.FindTestCaseFile(sourceFile)
+		if testCaseFile == "" {
+			return "", "", "", cli.Exit("invalid source file name", 1)
+		}
+	} else {
+		testCaseFile = testCaseVal
+		sourceFile = utils.SrcFileNameOf(testCaseFile)
+		if sourceFile == "" {
+			return "", "", "", cli.Exit("invalid test case file name", 1)
+		}
+	}
+	testFile = utils.TestFileNameOf(sourceFile)
+	if testFile == "" {
+		return "", "", "", cli.Exit("invalid source file name", 1)
+	}
+	return sourceFile, testCaseFile, testFile, nil
+}
@@ -0,0 +1,303 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/Ezer015/leetcode-gen-test/codegen"
+	"github.com/Ezer015/leetcode-gen-test/utils"
+)
+
+// templatesDirName is the directory of a project holding the templates that
+// replace the generated code, see codegen.LoadTemplates. It is looked up from
+// the directory of each source file up to the root of its module.
+const templatesDirName = ".leetcode-gen-test"
+
+// templatesFlag sets the template directory of all source files.
+var templatesFlag = &cli.StringFlag{
+	Name:  "templates",
+	Usage: "Load the templates of tests and test cases from `DIR` instead of the directory the project configures or its " + templatesDirName + " directory",
+}
+
+// generators provides the generator of each source file, following the
+// configuration of its project, see utils.Config. The generator of each
+// configuration and template directory is created once, and again once the
+// configuration file changes. It is safe for concurrent use.
+type generators struct {
+	// dir is the template directory set with --templates, or empty to use
+	// the directory of the project of each source file.
+	dir string
+
+	mu    sync.Mutex
+	cache map[generatorKey]generatorResult
+}
+
+// generatorKey identifies the generators of a batch by the configuration
+// and the template directory they follow. Configurations are compared by
+// identity, since utils.ConfigOf loads a changed configuration file anew.
+type generatorKey struct {
+	config    *utils.Config
+	templates string
+}
+
+// generatorResult is the generator of a configuration and a template
+// directory, or the error creating it.
+type generatorResult struct {
+	g   *codegen.Generator
+	err error
+}
+
+// generatorsOf creates the generators of a command from its --templates flag.
+//
+// Parameters:
+//   - c: The context of the command
+//
+// Returns:
+//   - *generators: The generators
+//   - error: An error to exit with if the template directory does not exist
+func generatorsOf(c *cli.Context) (*generators, error) {
+	dir := c.String(templatesFlag.Name)
+	if dir != "" {
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			return nil, cli.Exit(fmt.Sprintf("template directory %s does not exist", dir), 1)
+		}
+	}
+	return &generators{dir: dir, cache: make(map[generatorKey]generatorResult)}, nil
+}
+
+// generatorOf returns the generator of the source file a command operates on.
+//
+// Parameters:
+//   - c: The context of the command
+//   - sourceFile: The path of the source file
+//
+// Returns:
+//   - *codegen.Generator: The generator
+//   - error: An error to exit with if the templates cannot be loaded
+func generatorOf(c *cli.Context, sourceFile string) (*codegen.Generator, error) {
+	gs, err := generatorsOf(c)
+	if err != nil {
+		return nil, err
+	}
+	g, err := gs.of(sourceFile)
+	if err != nil {
+		return nil, cli.Exit(err, 1)
+	}
+	return g, nil
+}
+
+// of returns the generator of a source file, which follows the configuration
+// of its project and uses the templates of its template directory: the one
+// set with --templates, the one the project configures, or else the
+// templatesDirName directory of the project.
+//
+// Parameters:
+//   - sourceFile: The path of the source file
+//
+// Returns:
+//   - *codegen.Generator: The generator
+//   - error: An error if the configuration is invalid or the templates
+//     cannot be loaded, with codegen.Diagnostics locating the problems in
+//     the template files
+func (gs *generators) of(sourceFile string) (*codegen.Generator, error) {
+	config, err := utils.ConfigOf(sourceFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %v", err)
+	}
+	key := generatorKey{config: config, templates: gs.dir}
+	if key.templates == "" {
+		key.templates = config.Templates
+	}
+	if key.templates == "" {
+		key.templates = findTemplatesDir(sourceFile)
+	}
+
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	if r, ok := gs.cache[key]; ok {
+		return r.g, r.err
+	}
+	var r generatorResult
+	r.g, r.err = newGenerator(config, key.templates)
+	gs.cache[key] = r
+	return r.g, r.err
+}
+
+// newGenerator creates a generator following a configuration.
+//
+// Parameters:
+//   - config: The configuration of the project
+//   - templates: The template directory, or empty for the built-in code
+//
+// Returns:
+//   - *codegen.Generator: The generator
+//   - error: An error if the configuration is invalid or the templates
+//     cannot be loaded
+func newGenerator(config *utils.Config, templates string) (*codegen.Generator, error) {
+	opts := codegen.Options{Naming: config.Naming, Directive: config.Directive, Compare: config.Compare.Mode}
+	if config.Compare.Tolerance != "" {
+		tol, err := codegen.ParseTolerance(config.Compare.Tolerance)
+		if err != nil {
+			return nil, fmt.Errorf("invalid config %s: invalid tolerance: %v", config.Path, err)
+		}
+		opts.Tolerance = &tol
+	}
+	if templates != "" {
+		var err error
+		if opts.Templates, err = codegen.LoadTemplates(templates); err != nil {
+			return nil, failure("load templates", err)
+		}
+	}
+	g, err := codegen.NewGenerator(opts)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config %s: %v", config.Path, err)
+	}
+	return g, nil
+}
+
+// isTaggedSourceFile reports whether a file found in a directory is a source
+// file with functions tagged for testing with the directive of its project.
+// Files that cannot be read or parsed are included, so that their errors are
+// reported, and so are files of projects with an invalid configuration.
+func (gs *generators) isTaggedSourceFile(path string) bool {
+	if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") || utils.SrcFileNameOf(path) != "" {
+		return false
+	}
+	g, err := gs.of(path)
+	if err != nil {
+		return true
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return true
+	}
+	tagged, err := g.HasTestTags(content)
+	return tagged || err != nil
+}
+
+// findTemplatesDir looks up the template directory of the project of a
+// source file, from the directory of the file up to the root of its module.
+//
+// Parameters:
+//   - sourceFile: The path of the source file
+//
+// Returns:
+//   - string: The template directory, or empty if there is none
+func findTemplatesDir(sourceFile string) string {
+	dir, err := filepath.Abs(filepath.Dir(sourceFile))
+	if err != nil {
+		return ""
+	}
+	for {
+		candidate := filepath.Join(dir, templatesDirName)
+		if info, err := os.Stat(candidate); err == nil && info.IsDir() {
+			return candidate
+		}
+		// Projects end at the root of their module
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return ""
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// lctestModulePath is the module of the lctest package, which generated
+// tests import for their helpers.
+const lctestModulePath = "github.com/Ezer015/leetcode-gen-test"
+
+// hintedModules holds the go.mod files whose missing requirement of
+// lctestModulePath was already reported.
+var hintedModules sync.Map
+
+// hintLctestRequirement tells how to add the module of the lctest package to
+// the module of a test file that imports it without requiring it, once per
+// module.
+//
+// Parameters:
+//   - testFile: The path of the test file
+//   - content: The content of the test file
+func hintLctestRequirement(testFile string, content []byte) {
+	if !bytes.Contains(content, []byte(strconv.Quote(lctestModulePath+"/lctest"))) {
+		return
+	}
+	goMod := findGoMod(testFile)
+	if goMod == "" {
+		return
+	}
+	data, err := os.ReadFile(goMod)
+	if err != nil || requiresModule(data, lctestModulePath) {
+		return
+	}
+	if _, hinted := hintedModules.LoadOrStore(goMod, true); hinted {
+		return
+	}
+	warnMu.Lock()
+	defer warnMu.Unlock()
+	fmt.Fprintf(os.Stderr, "hint: %s imports %s/lctest, which %s does not require yet, run: go get %s\n",
+		testFile, lctestModulePath, goMod, lctestModulePath)
+}
+
+// findGoMod returns the path of the go.mod file of the module holding a file,
+// or an empty string if there is none.
+func findGoMod(file string) string {
+	dir, err := filepath.Abs(filepath.Dir(file))
+	if err != nil {
+		return ""
+	}
+	for {
+		candidate := filepath.Join(dir, "go.mod")
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// requiresModule reports whether the content of a go.mod file requires a
+// module, or declares it.
+//
+// Example:
+//
+//	input: "module example.com/p\n\nrequire (\n\tgithub.com/a/b v1.0.0\n)\n", "github.com/a/b"
+//	output: true
+func requiresModule(goMod []byte, modPath string) bool {
+	inRequire := false
+	for _, line := range strings.Split(string(goMod), "\n") {
+		line, _, _ = strings.Cut(line, "//")
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 0:
+			continue
+		case inRequire && fields[0] == ")":
+			inRequire = false
+			continue
+		case !inRequire && (fields[0] == "module" || fields[0] == "require"):
+			if len(fields) == 2 && fields[0] == "require" && fields[1] == "(" {
+				inRequire = true
+				continue
+			}
+			fields = fields[1:]
+		case !inRequire:
+			continue
+		}
+		if len(fields) > 0 && strings.Trim(fields[0], `"`) == modPath {
+			return true
+		}
+	}
+	return false
+}
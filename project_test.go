@@ -0,0 +1,72 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindTemplatesDir(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{
+		templatesDirName + "/test.tmpl",
+		"mod/go.mod",
+		"mod/p/sol.go",
+		"mod/q/" + templatesDirName + "/test.tmpl",
+		"mod/q/r/sol.go",
+		"plain/p/sol.go",
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name       string
+		sourceFile string
+		expected   string
+	}{
+		{"module root ends the lookup", "mod/p/sol.go", ""},
+		{"nearest directory", "mod/q/r/sol.go", "mod/q/" + templatesDirName},
+		{"outside modules", "plain/p/sol.go", templatesDirName},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expected := ""
+			if tt.expected != "" {
+				expected = filepath.Join(dir, tt.expected)
+			}
+			if result := findTemplatesDir(filepath.Join(dir, tt.sourceFile)); result != expected {
+				t.Errorf("findTemplatesDir(%s) = %q; expected %q", tt.sourceFile, result, expected)
+			}
+		})
+	}
+}
+
+func TestRequiresModule(t *testing.T) {
+	tests := []struct {
+		name     string
+		goMod    string
+		expected bool
+	}{
+		{"single require", "module example.com/p\n\nrequire " + lctestModulePath + " v0.1.0\n", true},
+		{"require block", "module example.com/p\n\nrequire (\n\tgithub.com/a/b v1.0.0\n\t" + lctestModulePath + " v0.1.0 // indirect\n)\n", true},
+		{"the module itself", "module " + lctestModulePath + "\n\ngo 1.23\n", true},
+		{"replace only", "module example.com/p\n\nreplace " + lctestModulePath + " => ../lgt\n", false},
+		{"other module", "module example.com/p\n\nrequire (\n\t" + lctestModulePath + "/v2 v2.0.0\n)\n", false},
+		{"no requirements", "module example.com/p\n\ngo 1.23\n", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := requiresModule([]byte(tt.goMod), lctestModulePath); result != tt.expected {
+				t.Errorf("requiresModule() = %v; expected %v", result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,238 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ConfigFileNames are the names of project configuration files, in the order
+// they are looked up in each directory, see FindConfig.
+var ConfigFileNames = []string{"leetcode-gen-test.json", "leetcode-gen-test.toml"}
+
+// Config is the configuration of a project, read from a JSON or TOML file:
+//
+//	directive = "leetcode:test"
+//	templates = "tools/templates"
+//
+//	[naming]
+//	test_case_file = "cases_<name>.go"
+//	test_case_type = "<func>Case"
+//
+//	[compare]
+//	mode = "unordered"
+//	tolerance = "abs=1e-9 rel=0"
+type Config struct {
+	// Naming is the naming scheme of test case files and types. Patterns
+	// that are not set follow DefaultNaming.
+	Naming Naming `json:"naming"`
+	// Directive is the name of the test directive, e.g. "leetcode:test",
+	// whose namespace the annotations share, or empty for the default one.
+	Directive string `json:"directive"`
+	// Compare holds the default comparison options of the results of the
+	// functions and design types of the project.
+	Compare CompareConfig `json:"compare"`
+	// Templates is the template directory, relative to the directory of the
+	// configuration file, or empty to look up the directory of the project.
+	Templates string `json:"templates"`
+
+	// Path is the path of the configuration file, or empty for the default
+	// configuration.
+	Path string `json:"-"`
+}
+
+// CompareConfig holds the default comparison options of a project, which
+// the test directive and annotations of a declaration override.
+type CompareConfig struct {
+	// Mode is the comparison mode: "exact", "unordered", or empty to compare
+	// floating-point results within the tolerance.
+	Mode string `json:"mode"`
+	// Tolerance is spelled as the value of the tolerance annotation, e.g.
+	// "1e-9" or "abs=1e-9 rel=0", or empty for the precision LeetCode
+	// accepts.
+	Tolerance string `json:"tolerance"`
+}
+
+// DefaultConfig is the configuration of projects without a configuration
+// file.
+var DefaultConfig = Config{Naming: DefaultNaming}
+
+// LoadConfig reads a configuration file, parsing it as TOML if its name ends
+// in ".toml" and as JSON otherwise. Unknown keys are rejected, so that typos
+// do not go unnoticed.
+//
+// Parameters:
+//   - path: The path of the configuration file
+//
+// Returns:
+//   - *Config: The configuration, with its naming scheme completed from
+//     DefaultNaming and its template directory resolved against the
+//     directory of the file
+//   - error: An error if the file cannot be read or parsed, or its naming
+//     scheme is malformed
+func LoadConfig(path string) (*Config, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading config: %v", err)
+	}
+
+	data := content
+	if filepath.Ext(path) == ".toml" {
+		table, err := parseTOML(content)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%v", path, err)
+		}
+		if data, err = json.Marshal(table); err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	var config Config
+	if err := decoder.Decode(&config); err != nil {
+		return nil, fmt.Errorf("%s: %s", path, strings.TrimPrefix(err.Error(), "json: "))
+	}
+
+	config.Path = path
+	config.Naming = config.Naming.WithDefaults()
+	if err := config.Naming.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: invalid naming: %v", path, err)
+	}
+	if config.Templates != "" && !filepath.IsAbs(config.Templates) {
+		config.Templates = filepath.Join(filepath.Dir(path), filepath.FromSlash(config.Templates))
+	}
+	return &config, nil
+}
+
+// FindConfig looks up the configuration file of a project, from a directory
+// up to the root of its repository, the first directory holding ".git", or
+// else up to the root of the file system.
+//
+// Parameters:
+//   - dir: The directory to start from
+//
+// Returns:
+//   - string: The path of the configuration file, or empty if there is none
+func FindConfig(dir string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	for {
+		for _, name := range ConfigFileNames {
+			path := filepath.Join(dir, name)
+			if info, err := os.Stat(path); err == nil && !info.IsDir() {
+				return path
+			}
+		}
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return ""
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// configResult is the configuration of a directory, or the error loading it.
+type configResult struct {
+	config *Config
+	err    error
+	// path is the configuration file, or empty if there is none, and stamp
+	// the version of it that was loaded.
+	path  string
+	stamp configStamp
+}
+
+// configStamp identifies a version of a configuration file.
+type configStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// stampOf returns the version of a configuration file, or the zero stamp if
+// it does not exist.
+func stampOf(path string) configStamp {
+	info, err := os.Stat(path)
+	if err != nil {
+		return configStamp{}
+	}
+	return configStamp{modTime: info.ModTime(), size: info.Size()}
+}
+
+// configs caches the configurations of directories by their absolute paths,
+// since the functions naming files look them up for every file. A cached
+// configuration is loaded again once its file changes.
+var configs sync.Map
+
+// defaultConfig is the configuration of the projects without a
+// configuration file, shared so that they get the same generators.
+var defaultConfig = DefaultConfig
+
+// ResetConfigs forgets the configurations loaded so far, so that the next
+// lookups find the configuration files created since, e.g. while watching a
+// project.
+func ResetConfigs() {
+	configs.Clear()
+}
+
+// ConfigOf returns the configuration of the project of a file, looked up
+// from the directory of the file, see FindConfig. Configurations are loaded
+// once per directory, and again once their file changes.
+//
+// Parameters:
+//   - file: The path of the file
+//
+// Returns:
+//   - *Config: The configuration, which is DefaultConfig if the project has
+//     no configuration file
+//   - error: An error if the configuration file cannot be loaded
+func ConfigOf(file string) (*Config, error) {
+	return configOfDir(filepath.Dir(file))
+}
+
+// configOfDir returns the configuration of the project of a directory.
+func configOfDir(dir string) (*Config, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("resolving %s: %v", dir, err)
+	}
+	if r, ok := configs.Load(abs); ok {
+		r := r.(configResult)
+		if r.path == "" || stampOf(r.path) == r.stamp {
+			return r.config, r.err
+		}
+	}
+
+	r := configResult{config: &defaultConfig, path: FindConfig(abs)}
+	if r.path != "" {
+		r.stamp = stampOf(r.path)
+		r.config, r.err = LoadConfig(r.path)
+	}
+	configs.Store(abs, r)
+	return r.config, r.err
+}
+
+// NamingOf returns the naming scheme of the project of a file, or
+// DefaultNaming if its configuration file cannot be loaded, which ConfigOf
+// reports.
+//
+// Parameters:
+//   - file: The path of the file
+//
+// Returns:
+//   - Naming: The naming scheme
+func NamingOf(file string) Naming {
+	config, err := ConfigOf(file)
+	if err != nil {
+		return DefaultNaming
+	}
+	return config.Naming
+}
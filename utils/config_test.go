@@ -0,0 +1,182 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseTOML(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    map[string]any
+		err     string
+	}{
+		{
+			name: "tables and values",
+			content: `# project settings
+directive = "lc:gen" # trailing comment
+templates = 'tools/#templates'
+
+[naming]
+test_case_file = "cases_<name>.go"
+
+[a.b]
+n = 1_000
+x = 1e-9
+on = true
+`,
+			want: map[string]any{
+				"directive": "lc:gen",
+				"templates": "tools/#templates",
+				"naming":    map[string]any{"test_case_file": "cases_<name>.go"},
+				"a":         map[string]any{"b": map[string]any{"n": int64(1000), "x": 1e-9, "on": true}},
+			},
+		},
+		{name: "missing equals", content: "directive\n", err: "1: expected key = value"},
+		{name: "duplicate key", content: "a = 1\na = 2\n", err: "2: key a is already defined"},
+		{name: "duplicate table", content: "[t]\n[t]\n", err: "2: table t is already defined"},
+		{name: "array", content: "a = [1]\n", err: "1: a: arrays and inline tables are not supported"},
+		{name: "array of tables", content: "[[t]]\n", err: "1: arrays of tables are not supported"},
+		{name: "bad string", content: `a = "x` + "\n", err: `1: a: invalid string "x`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := parseTOML([]byte(tt.content))
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("parseTOML() error = %v; want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseTOML() error = %v", err)
+			}
+			if !reflect.DeepEqual(result, tt.want) {
+				t.Errorf("parseTOML() = %v; want %v", result, tt.want)
+			}
+		})
+	}
+}
+
+// writeFiles writes files with their contents under dir.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"json/leetcode-gen-test.json":   `{"naming": {"test_case_type": "<func>Case"}, "compare": {"mode": "exact"}, "templates": "tpl"}`,
+		"toml/leetcode-gen-test.toml":   "directive = \"lc:gen\"\n[naming]\ntest_case_file = \"cases_<name>.go\"\n",
+		"bad/leetcode-gen-test.toml":    "bogus = 1\n",
+		"naming/leetcode-gen-test.json": `{"naming": {"test_case_file": "cases.go"}}`,
+	})
+
+	config, err := LoadConfig(filepath.Join(dir, "json/leetcode-gen-test.json"))
+	if err != nil {
+		t.Fatalf("LoadConfig(json) error = %v", err)
+	}
+	if config.Naming.TestCaseType != "<func>Case" || config.Naming.TestCaseFile != DefaultNaming.TestCaseFile ||
+		config.Compare.Mode != "exact" || config.Templates != filepath.Join(dir, "json/tpl") {
+		t.Errorf("LoadConfig(json) = %+v", config)
+	}
+
+	config, err = LoadConfig(filepath.Join(dir, "toml/leetcode-gen-test.toml"))
+	if err != nil {
+		t.Fatalf("LoadConfig(toml) error = %v", err)
+	}
+	if config.Directive != "lc:gen" || config.Naming.TestCaseFile != "cases_<name>.go" {
+		t.Errorf("LoadConfig(toml) = %+v", config)
+	}
+
+	for name, want := range map[string]string{
+		"bad/leetcode-gen-test.toml":    `unknown field "bogus"`,
+		"naming/leetcode-gen-test.json": "invalid naming",
+	} {
+		if _, err := LoadConfig(filepath.Join(dir, name)); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("LoadConfig(%s) error = %v; want it to contain %q", name, err, want)
+		}
+	}
+}
+
+func TestConfigOf(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"leetcode-gen-test.toml": "[naming]\ntest_case_file = \"cases_<name>.go\"\n",
+		"p/sol.go":               "",
+		"repo/.git/HEAD":         "",
+		"repo/p/sol.go":          "",
+	})
+
+	config, err := ConfigOf(filepath.Join(dir, "p/sol.go"))
+	if err != nil {
+		t.Fatalf("ConfigOf() error = %v", err)
+	}
+	if config.Path != filepath.Join(dir, "leetcode-gen-test.toml") {
+		t.Errorf("ConfigOf() path = %q", config.Path)
+	}
+	if config, err := ConfigOf(filepath.Join(dir, "repo/p/sol.go")); err != nil || config.Path != "" {
+		t.Errorf("ConfigOf() crossed the repository root: %+v, %v", config, err)
+	}
+	tests := []struct {
+		name   string
+		result string
+		want   string
+	}{
+		{"TestCaseFileNameOf", TestCaseFileNameOf(filepath.Join(dir, "p/sol.go")), filepath.Join(dir, "p/cases_sol.go")},
+		{"SrcFileNameOf", SrcFileNameOf(filepath.Join(dir, "p/cases_sol.go")), filepath.Join(dir, "p/sol.go")},
+		{"SrcFileNameOf default", SrcFileNameOf(filepath.Join(dir, "p/sol_testcase.go")), ""},
+		{"SrcFileNameOf outside", SrcFileNameOf(filepath.Join(dir, "repo/p/sol_testcase.go")), filepath.Join(dir, "repo/p/sol.go")},
+	}
+	for _, tt := range tests {
+		if tt.result != tt.want {
+			t.Errorf("%s = %q; want %q", tt.name, tt.result, tt.want)
+		}
+	}
+
+	// Configurations are loaded again once their file changes
+	writeFiles(t, dir, map[string]string{"leetcode-gen-test.toml": "# edited\n[naming]\ntest_case_file = \"<name>_cases.go\"\n"})
+	if config, err := ConfigOf(filepath.Join(dir, "p/sol.go")); err != nil || config.Naming.TestCaseFile != "<name>_cases.go" {
+		t.Errorf("ConfigOf() after editing the config = %+v, %v; expected the edited naming", config, err)
+	}
+	writeFiles(t, dir, map[string]string{"repo/leetcode-gen-test.json": `{"directive": "lc:gen"}`})
+	ResetConfigs()
+	if config, err := ConfigOf(filepath.Join(dir, "repo/p/sol.go")); err != nil || config.Directive != "lc:gen" {
+		t.Errorf("ConfigOf() after creating a config and ResetConfigs() = %+v, %v", config, err)
+	}
+}
+
+func TestTypeNamesIgnoreWorkingDirectory(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"leetcode-gen-test.toml": "[naming]\ntest_case_type = \"<func>Case\"\n"})
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	// Type names follow DefaultNaming unless a Naming is passed in
+	if result := TestCaseTypeNameOf("TwoSum"); result != "testTwoSumCase" {
+		t.Errorf("TestCaseTypeNameOf() = %q; want testTwoSumCase", result)
+	}
+	if result := NamingOf(filepath.Join(dir, "sol.go")).TestCaseTypeNameOf("TwoSum"); result != "TwoSumCase" {
+		t.Errorf("NamingOf().TestCaseTypeNameOf() = %q; want TwoSumCase", result)
+	}
+}
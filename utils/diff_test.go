@@ -0,0 +1,82 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	lines := func(n int) []string {
+		var ls []string
+		for i := 1; i <= n; i++ {
+			ls = append(ls, strings.Repeat("x", i))
+		}
+		return ls
+	}
+	join := func(ls []string) string { return strings.Join(ls, "\n") + "\n" }
+	replace := func(ls []string, i int, s string) []string {
+		ls = append([]string(nil), ls...)
+		ls[i] = s
+		return ls
+	}
+
+	tests := []struct {
+		name     string
+		old      string
+		new      string
+		expected string
+	}{
+		{
+			name:     "equal",
+			old:      "a\nb\n",
+			new:      "a\nb\n",
+			expected: "",
+		},
+		{
+			name:     "from empty",
+			old:      "",
+			new:      "a\nb\n",
+			expected: "--- old\n+++ new\n@@ -0,0 +1,2 @@\n+a\n+b\n",
+		},
+		{
+			name:     "changed line",
+			old:      "a\nb\nc\n",
+			new:      "a\nB\nc\n",
+			expected: "--- old\n+++ new\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+		},
+		{
+			name:     "no newline at end",
+			old:      "a\nb",
+			new:      "a\nb\n",
+			expected: "--- old\n+++ new\n@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+b\n",
+		},
+		{
+			name: "distant changes",
+			old:  join(lines(20)),
+			new:  join(replace(replace(lines(20), 1, "b"), 17, "r")),
+			expected: "--- old\n+++ new\n@@ -1,5 +1,5 @@\n x\n-xx\n+b\n xxx\n xxxx\n xxxxx\n" +
+				"@@ -15,6 +15,6 @@\n " + strings.Join(lines(20)[14:17], "\n ") + "\n-" + lines(20)[17] + "\n+r\n " + strings.Join(lines(20)[18:], "\n ") + "\n",
+		},
+		{
+			name:     "close changes",
+			old:      join(lines(10)),
+			new:      join(replace(replace(lines(10), 1, "b"), 7, "h")),
+			expected: "--- old\n+++ new\n@@ -1,10 +1,10 @@\n x\n-xx\n+b\n " + strings.Join(lines(10)[2:7], "\n ") + "\n-" + lines(10)[7] + "\n+h\n " + strings.Join(lines(10)[8:], "\n ") + "\n",
+		},
+		{
+			name:     "single line",
+			old:      "a\n",
+			new:      "b\n",
+			expected: "--- old\n+++ new\n@@ -1 +1 @@\n-a\n+b\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := UnifiedDiff("old", "new", []byte(tt.old), []byte(tt.new))
+			if result != tt.expected {
+				t.Errorf("UnifiedDiff() =\n%s\nwant\n%s", result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// WriteFileAtomic writes data to a file by writing a temporary file in the
+// same directory and renaming it over the file, so that readers and
+// interrupted runs never see a partially written file. An existing file keeps
+// its permissions.
+//
+// Parameters:
+//   - path: The path of the file to write
+//   - data: The content of the file
+//   - perm: The permissions of the file if it does not exist yet
+//
+// Returns:
+//   - An error if the file cannot be written; the file is left untouched then.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) (err error) {
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("creating temporary file: %v", err)
+	}
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	if _, err := tmp.Write(data); err != nil {
+		return fmt.Errorf("writing temporary file: %v", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		return fmt.Errorf("syncing temporary file: %v", err)
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		return fmt.Errorf("setting permissions: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("closing temporary file: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("renaming temporary file: %v", err)
+	}
+	return nil
+}
+
+// FindTestCaseFile returns the test case file of a source file in the layout
+// it exists in: the test-only case file if there is one, or else the regular
+// test case file, which is also returned if neither exists.
+//
+// Parameters:
+//   - sourceFile: The path of the source file
+//
+// Returns:
+//   - The path of the test case file, or an empty string if sourceFile is not
+//     a Go file.
+func FindTestCaseFile(sourceFile string) string {
+	if testOnly := TestOnlyTestCaseFileNameOf(sourceFile); testOnly != "" {
+		if _, err := os.Stat(testOnly); err == nil {
+			return testOnly
+		}
+	}
+	return TestCaseFileNameOf(sourceFile)
+}
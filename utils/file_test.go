@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "two_sum_test.go")
+
+	if err := WriteFileAtomic(path, []byte("package p\n"), 0o644); err != nil {
+		t.Fatalf("WriteFileAtomic() error = %v", err)
+	}
+	if err := os.Chmod(path, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteFileAtomic(path, []byte("package q\n"), 0o644); err != nil {
+		t.Fatalf("WriteFileAtomic() error = %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "package q\n" {
+		t.Errorf("WriteFileAtomic() wrote %q; expected %q", content, "package q\n")
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("WriteFileAtomic() set permissions %v; expected the existing %v", perm, os.FileMode(0o600))
+	}
+
+	// No temporary files are left behind
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("WriteFileAtomic() left %d files in the directory; expected 1", len(entries))
+	}
+
+	// A failed write leaves nothing behind either
+	if err := WriteFileAtomic(filepath.Join(dir, "missing", "x.go"), nil, 0o644); err == nil {
+		t.Errorf("WriteFileAtomic() into a missing directory succeeded; expected an error")
+	}
+}
+
+func TestFindTestCaseFile(t *testing.T) {
+	dir := t.TempDir()
+	regular := filepath.Join(dir, "a.go")
+	testOnly := filepath.Join(dir, "b.go")
+	if err := os.WriteFile(filepath.Join(dir, "a_testcase.go"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "b_testcase_test.go"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		sourceFile string
+		expected   string
+	}{
+		{regular, filepath.Join(dir, "a_testcase.go")},
+		{testOnly, filepath.Join(dir, "b_testcase_test.go")},
+		{filepath.Join(dir, "c.go"), filepath.Join(dir, "c_testcase.go")},
+		{"invalid", ""},
+	}
+	for _, tt := range tests {
+		if result := FindTestCaseFile(tt.sourceFile); result != tt.expected {
+			t.Errorf("FindTestCaseFile(%s) = %s; want %s", tt.sourceFile, result, tt.expected)
+		}
+	}
+}
@@ -2,11 +2,281 @@ package utils
 
 import (
 	"fmt"
+	"go/token"
+	"path/filepath"
 	"strings"
+	"unicode"
 )
 
+// Placeholders of the patterns of a Naming.
+const (
+	// NamePlaceholder stands for the name of the source file without ".go"
+	// in file name patterns.
+	NamePlaceholder = "<name>"
+	// FuncPlaceholder stands for the name test case types are derived from,
+	// such as "TwoSum", in type name patterns.
+	FuncPlaceholder = "<func>"
+)
+
+// Naming is the naming scheme of test case files and of the types and
+// functions declared in them. Each field is a pattern holding a single
+// placeholder, which the name of a file or function replaces; names are
+// matched against the same patterns in the other direction.
+type Naming struct {
+	// TestCaseFile is the pattern of test case file names, e.g.
+	// "<name>_testcase.go". Test-only case files end in "_test.go" instead
+	// of ".go".
+	TestCaseFile string `json:"test_case_file"`
+	// TestCaseType, TestCaseInputType and TestCaseOutputType are the
+	// patterns of the test case types, e.g. "test<func>Case".
+	TestCaseType       string `json:"test_case_type"`
+	TestCaseInputType  string `json:"test_case_input_type"`
+	TestCaseOutputType string `json:"test_case_output_type"`
+	// CheckerFunc is the pattern of the names of user-written checkers,
+	// e.g. "check<func>".
+	CheckerFunc string `json:"checker_func"`
+}
+
+// DefaultNaming is the naming scheme used unless a project configures
+// another one, see Config.
+var DefaultNaming = Naming{
+	TestCaseFile:       NamePlaceholder + "_testcase.go",
+	TestCaseType:       "test" + FuncPlaceholder + "Case",
+	TestCaseInputType:  "test" + FuncPlaceholder + "Input",
+	TestCaseOutputType: "test" + FuncPlaceholder + "Output",
+	CheckerFunc:        "check" + FuncPlaceholder,
+}
+
+// WithDefaults returns the naming scheme with the patterns that are not set
+// taken from DefaultNaming.
+func (n Naming) WithDefaults() Naming {
+	for _, p := range []struct {
+		pattern  *string
+		fallback string
+	}{
+		{&n.TestCaseFile, DefaultNaming.TestCaseFile},
+		{&n.TestCaseType, DefaultNaming.TestCaseType},
+		{&n.TestCaseInputType, DefaultNaming.TestCaseInputType},
+		{&n.TestCaseOutputType, DefaultNaming.TestCaseOutputType},
+		{&n.CheckerFunc, DefaultNaming.CheckerFunc},
+	} {
+		if *p.pattern == "" {
+			*p.pattern = p.fallback
+		}
+	}
+	return n
+}
+
+// Validate reports whether the patterns of a naming scheme can be applied in
+// both directions: each holds its placeholder once, file names end in ".go"
+// without being test files, and type and function names are identifiers
+// that tell the test case types apart.
+//
+// Returns:
+//   - An error describing the first malformed pattern, or nil.
+func (n Naming) Validate() error {
+	if strings.Count(n.TestCaseFile, NamePlaceholder) != 1 {
+		return fmt.Errorf("test case file pattern %q must hold %s once", n.TestCaseFile, NamePlaceholder)
+	}
+	if !strings.HasSuffix(n.TestCaseFile, ".go") || strings.HasSuffix(n.TestCaseFile, "_test.go") ||
+		strings.ContainsAny(n.TestCaseFile, `/\`) {
+		return fmt.Errorf("test case file pattern %q must be a file name ending in .go but not in _test.go", n.TestCaseFile)
+	}
+
+	types := []struct {
+		name    string
+		pattern string
+	}{
+		{"test case type", n.TestCaseType},
+		{"test case input type", n.TestCaseInputType},
+		{"test case output type", n.TestCaseOutputType},
+		{"checker function", n.CheckerFunc},
+	}
+	for i, t := range types {
+		if strings.Count(t.pattern, FuncPlaceholder) != 1 {
+			return fmt.Errorf("%s pattern %q must hold %s once", t.name, t.pattern, FuncPlaceholder)
+		}
+		if !token.IsIdentifier(strings.Replace(t.pattern, FuncPlaceholder, "X", 1)) {
+			return fmt.Errorf("%s pattern %q must make identifiers", t.name, t.pattern)
+		}
+		for _, other := range types[:i] {
+			if t.pattern == other.pattern {
+				return fmt.Errorf("%s pattern %q is the same as the %s pattern", t.name, t.pattern, other.name)
+			}
+		}
+	}
+	return nil
+}
+
+// apply substitutes a name for the placeholder of a pattern.
+func apply(pattern, placeholder, name string) string {
+	return strings.Replace(pattern, placeholder, name, 1)
+}
+
+// match extracts the name standing for the placeholder of a pattern from s,
+// reporting whether s follows the pattern.
+func match(pattern, placeholder, s string) (string, bool) {
+	prefix, suffix, _ := strings.Cut(pattern, placeholder)
+	if len(s) < len(prefix)+len(suffix) || !strings.HasPrefix(s, prefix) || !strings.HasSuffix(s, suffix) {
+		return "", false
+	}
+	return s[len(prefix) : len(s)-len(suffix)], true
+}
+
+// testOnlyPattern returns the pattern of test-only case file names.
+func (n Naming) testOnlyPattern() string {
+	return strings.TrimSuffix(n.TestCaseFile, ".go") + "_test.go"
+}
+
+// SrcFileNameOf takes a test case file name and returns the corresponding
+// source file name, keeping its directory. Test-only case files are matched
+// first, as their names may also follow the pattern of regular ones.
+//
+// Parameters:
+//   - testCaseFile: The path of the test case file
+//
+// Returns:
+//   - The path of the corresponding source file, or an empty string if the
+//     file is not a test case file.
+func (n Naming) SrcFileNameOf(testCaseFile string) string {
+	dir, base := filepath.Split(testCaseFile)
+	for _, pattern := range []string{n.testOnlyPattern(), n.TestCaseFile} {
+		if name, ok := match(pattern, NamePlaceholder, base); ok && name != "" {
+			return dir + name + ".go"
+		}
+	}
+	return ""
+}
+
+// TestCaseFileNameOf returns the path of the test case file of a source file.
+//
+// Parameters:
+//   - sourceFile: The path of the source file
+//
+// Returns:
+//   - The path of the test case file, or an empty string if the source file
+//     does not end in ".go".
+func (n Naming) TestCaseFileNameOf(sourceFile string) string {
+	return n.fileNameOf(n.TestCaseFile, sourceFile)
+}
+
+// TestOnlyTestCaseFileNameOf returns the path of the test-only case file of
+// a source file.
+//
+// Parameters:
+//   - sourceFile: The path of the source file
+//
+// Returns:
+//   - The path of the test-only case file, or an empty string if the source
+//     file does not end in ".go".
+func (n Naming) TestOnlyTestCaseFileNameOf(sourceFile string) string {
+	return n.fileNameOf(n.testOnlyPattern(), sourceFile)
+}
+
+// fileNameOf applies a file name pattern to the name of a source file,
+// keeping its directory.
+func (n Naming) fileNameOf(pattern, sourceFile string) string {
+	if !strings.HasSuffix(sourceFile, ".go") {
+		return ""
+	}
+	dir, base := filepath.Split(sourceFile)
+	return dir + apply(pattern, NamePlaceholder, strings.TrimSuffix(base, ".go"))
+}
+
+// IsTestOnlyTestCaseFile reports whether a test case file name follows the
+// test-only layout.
+//
+// Parameters:
+//   - testCaseFile: The path of the test case file
+//
+// Returns:
+//   - bool: True if the file is only compiled by go test, false otherwise.
+func (n Naming) IsTestOnlyTestCaseFile(testCaseFile string) bool {
+	name, ok := match(n.testOnlyPattern(), NamePlaceholder, filepath.Base(testCaseFile))
+	return ok && name != ""
+}
+
+// TestCaseTypeNameOf returns the name of the test case type of a function.
+func (n Naming) TestCaseTypeNameOf(funcName string) string {
+	return apply(n.TestCaseType, FuncPlaceholder, funcName)
+}
+
+// TestCaseInputTypeNameOf returns the name of the test case input type of a
+// function.
+func (n Naming) TestCaseInputTypeNameOf(funcName string) string {
+	return apply(n.TestCaseInputType, FuncPlaceholder, funcName)
+}
+
+// TestCaseOutputTypeNameOf returns the name of the test case output type of
+// a function.
+func (n Naming) TestCaseOutputTypeNameOf(funcName string) string {
+	return apply(n.TestCaseOutputType, FuncPlaceholder, funcName)
+}
+
+// CheckerFuncNameOf returns the name of the checker of a function.
+func (n Naming) CheckerFuncNameOf(funcName string) string {
+	return apply(n.CheckerFunc, FuncPlaceholder, funcName)
+}
+
+// FuncNameOfChecker extracts the name of the checked function from the name
+// of a checker function, which must start with an upper-case letter.
+//
+// Parameters:
+//   - checkerName: The name of the checker function.
+//
+// Returns:
+//   - The name of the checked function, or an empty string if checkerName
+//     does not follow the checker naming convention.
+func (n Naming) FuncNameOfChecker(checkerName string) string {
+	funcName, ok := match(n.CheckerFunc, FuncPlaceholder, checkerName)
+	if !ok || funcName == "" || !unicode.IsUpper([]rune(funcName)[0]) {
+		return ""
+	}
+	return funcName
+}
+
+// IsTestCase reports whether a type name follows the test case type pattern.
+func (n Naming) IsTestCase(typeName string) bool {
+	_, ok := match(n.TestCaseType, FuncPlaceholder, typeName)
+	return ok
+}
+
+// IsTestCaseInput reports whether a type name follows the test case input
+// type pattern.
+func (n Naming) IsTestCaseInput(typeName string) bool {
+	_, ok := match(n.TestCaseInputType, FuncPlaceholder, typeName)
+	return ok
+}
+
+// IsTestCaseOutput reports whether a type name follows the test case output
+// type pattern.
+func (n Naming) IsTestCaseOutput(typeName string) bool {
+	_, ok := match(n.TestCaseOutputType, FuncPlaceholder, typeName)
+	return ok
+}
+
+// FuncNameOf extracts the function name from the name of a test case, input
+// or output type.
+//
+// Parameters:
+//   - typeName: The type name to process.
+//
+// Returns:
+//   - The extracted function name, or an empty string if the type name does
+//     not follow any of the patterns.
+func (n Naming) FuncNameOf(typeName string) string {
+	for _, pattern := range []string{n.TestCaseType, n.TestCaseInputType, n.TestCaseOutputType} {
+		if funcName, ok := match(pattern, FuncPlaceholder, typeName); ok {
+			return funcName
+		}
+	}
+	return ""
+}
+
 // SrcFileNameOf takes a test case file name and returns the corresponding source file name.
-// It removes the "_testcase.go" suffix from the input file name and appends ".go".
+// It removes the "_testcase.go" suffix, or the "_testcase_test.go" suffix of
+// test-only case files, from the input file name and appends ".go". Projects
+// may configure other test case file names, see NamingOf.
 //
 // Parameters:
 //
@@ -16,14 +286,12 @@ import (
 //
 //	The name of the corresponding source file.
 func SrcFileNameOf(testCaseFile string) string {
-	if !strings.HasSuffix(testCaseFile, "_testcase.go") {
-		return ""
-	}
-	return fmt.Sprintf("%s.go", strings.TrimSuffix(testCaseFile, "_testcase.go"))
+	return NamingOf(testCaseFile).SrcFileNameOf(testCaseFile)
 }
 
 // TestCaseFileNameOf generates a test case file name based on the provided source file name.
-// It removes the ".go" suffix from the source file name and appends "_testcase.go" to it.
+// It removes the ".go" suffix from the source file name and appends "_testcase.go" to it,
+// unless the project of the file configures another naming scheme, see NamingOf.
 //
 // Parameters:
 //   - sourceFile: The name of the source file as a string.
@@ -31,10 +299,34 @@ func SrcFileNameOf(testCaseFile string) string {
 // Returns:
 //   - A string representing the generated test case file name.
 func TestCaseFileNameOf(sourceFile string) string {
-	if !strings.HasSuffix(sourceFile, ".go") {
-		return ""
-	}
-	return fmt.Sprintf("%s_testcase.go", strings.TrimSuffix(sourceFile, ".go"))
+	return NamingOf(sourceFile).TestCaseFileNameOf(sourceFile)
+}
+
+// TestOnlyTestCaseFileNameOf generates the name of a test-only case file for
+// the provided source file name. Test-only case files end in "_test.go", so
+// that their declarations are only compiled by go test and stay out of the
+// package itself.
+//
+// Parameters:
+//   - sourceFile: The name of the source file.
+//
+// Returns:
+//   - A string representing the test-only case file name, e.g.
+//     "two_sum_testcase_test.go" for "two_sum.go".
+func TestOnlyTestCaseFileNameOf(sourceFile string) string {
+	return NamingOf(sourceFile).TestOnlyTestCaseFileNameOf(sourceFile)
+}
+
+// IsTestOnlyTestCaseFile reports whether a test case file name follows the
+// test-only layout of the naming scheme of its project, see NamingOf.
+//
+// Parameters:
+//   - testCaseFile: The name of the test case file.
+//
+// Returns:
+//   - bool: True if the file is only compiled by go test, false otherwise.
+func IsTestOnlyTestCaseFile(testCaseFile string) bool {
+	return NamingOf(testCaseFile).IsTestOnlyTestCaseFile(testCaseFile)
 }
 
 // TestFileNameOf generates the test file name for a given source file.
@@ -52,16 +344,13 @@ func TestFileNameOf(sourceFile string) string {
 	return fmt.Sprintf("%s_test.go", strings.TrimSuffix(sourceFile, ".go"))
 }
 
-const (
-	testCasePrefix       = "test"
-	testCaseSuffix       = "Case"
-	testCaseInputSuffix  = "Input"
-	testCaseOutputSuffix = "Output"
-)
-
 // TestCaseTypeNameOf generates a test case type name by concatenating a prefix,
 // the provided function name, and a suffix.
 //
+// Like the other functions naming types and checkers without a file at hand,
+// it follows DefaultNaming; the methods of a Naming, such as the one NamingOf
+// returns for a file, follow the naming scheme of a project.
+//
 // Parameters:
 //   - funcName: The name of the function for which the test case type name is being generated.
 //
@@ -69,7 +358,7 @@ const (
 //
 //	A string representing the test case type name.
 func TestCaseTypeNameOf(funcName string) string {
-	return fmt.Sprintf("%s%s%s", testCasePrefix, funcName, testCaseSuffix)
+	return DefaultNaming.TestCaseTypeNameOf(funcName)
 }
 
 // TestCaseInputTypeNameOf generates a test case input type name by concatenating
@@ -81,7 +370,7 @@ func TestCaseTypeNameOf(funcName string) string {
 // Returns:
 // A string representing the test case input type name.
 func TestCaseInputTypeNameOf(funcName string) string {
-	return fmt.Sprintf("%s%s%s", testCasePrefix, funcName, testCaseInputSuffix)
+	return DefaultNaming.TestCaseInputTypeNameOf(funcName)
 }
 
 // TestCaseOutputTypeNameOf generates the name for the test case output type
@@ -95,7 +384,35 @@ func TestCaseInputTypeNameOf(funcName string) string {
 // Returns:
 // - A string representing the test case output type name.
 func TestCaseOutputTypeNameOf(funcName string) string {
-	return fmt.Sprintf("%s%s%s", testCasePrefix, funcName, testCaseOutputSuffix)
+	return DefaultNaming.TestCaseOutputTypeNameOf(funcName)
+}
+
+// CheckerFuncNameOf generates the name of the user-written checker function
+// that validates the results of the given function, by prefixing the
+// function name.
+//
+// Parameters:
+//   - funcName: The name of the function whose results are checked.
+//
+// Returns:
+//
+//	A string representing the checker function name.
+func CheckerFuncNameOf(funcName string) string {
+	return DefaultNaming.CheckerFuncNameOf(funcName)
+}
+
+// FuncNameOfChecker extracts the name of the checked function from the name
+// of a checker function. The checker name must start with the checker prefix
+// followed by an upper-case letter; otherwise an empty string is returned.
+//
+// Parameters:
+//   - checkerName: The name of the checker function.
+//
+// Returns:
+//   - The name of the checked function, or an empty string if checkerName
+//     does not follow the checker naming convention.
+func FuncNameOfChecker(checkerName string) string {
+	return DefaultNaming.FuncNameOfChecker(checkerName)
 }
 
 // IsTestCase checks if the given type name starts with a specific prefix and ends with a specific suffix.
@@ -107,7 +424,7 @@ func TestCaseOutputTypeNameOf(funcName string) string {
 // Returns:
 //   - bool: True if the type name is a test case, false otherwise.
 func IsTestCase(typeName string) bool {
-	return strings.HasPrefix(typeName, testCasePrefix) && strings.HasSuffix(typeName, testCaseSuffix)
+	return DefaultNaming.IsTestCase(typeName)
 }
 
 // IsTestCaseInput checks if the given type name represents a test case input.
@@ -120,7 +437,7 @@ func IsTestCase(typeName string) bool {
 // Returns:
 //   - bool: True if the type name matches the test case input pattern, false otherwise.
 func IsTestCaseInput(typeName string) bool {
-	return strings.HasPrefix(typeName, testCasePrefix) && strings.HasSuffix(typeName, testCaseInputSuffix)
+	return DefaultNaming.IsTestCaseInput(typeName)
 }
 
 // IsTestCaseOutput checks if the given typeName represents a test case output.
@@ -135,7 +452,7 @@ func IsTestCaseInput(typeName string) bool {
 //
 //	bool: True if the typeName is a test case output, false otherwise.
 func IsTestCaseOutput(typeName string) bool {
-	return strings.HasPrefix(typeName, testCasePrefix) && strings.HasSuffix(typeName, testCaseOutputSuffix)
+	return DefaultNaming.IsTestCaseOutput(typeName)
 }
 
 // FuncNameOf extracts the function name from a given type name if it follows
@@ -150,15 +467,5 @@ func IsTestCaseOutput(typeName string) bool {
 //   - A string representing the extracted function name, or an empty string
 //     if the type name does not follow the expected naming convention.
 func FuncNameOf(typeName string) string {
-	if !strings.HasPrefix(typeName, testCasePrefix) {
-		return ""
-	}
-	typeName = strings.TrimPrefix(typeName, testCasePrefix)
-
-	for _, suffix := range []string{testCaseSuffix, testCaseInputSuffix, testCaseOutputSuffix} {
-		if strings.HasSuffix(typeName, suffix) {
-			return strings.TrimSuffix(typeName, suffix)
-		}
-	}
-	return ""
+	return DefaultNaming.FuncNameOf(typeName)
 }
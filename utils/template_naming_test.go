@@ -15,6 +15,8 @@ func TestSrcFileNameOf(t *testing.T) {
 		{"no_suffix.go", ""},
 		{"another_testcase_testcase.go", "another_testcase.go"},
 		{"invalid_testcase", ""},
+		{"example_testcase_test.go", "example.go"},
+		{"example_test.go", ""},
 	}
 
 	for _, tt := range tests {
@@ -49,6 +51,47 @@ func TestTestCaseFileNameOf(t *testing.T) {
 		})
 	}
 }
+func TestTestOnlyTestCaseFileNameOf(t *testing.T) {
+	tests := []struct {
+		sourceFile string
+		expected   string
+	}{
+		{"example.go", "example_testcase_test.go"},
+		{"dir/two_sum.go", "dir/two_sum_testcase_test.go"},
+		{"invalid", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sourceFile, func(t *testing.T) {
+			result := TestOnlyTestCaseFileNameOf(tt.sourceFile)
+			if result != tt.expected {
+				t.Errorf("TestOnlyTestCaseFileNameOf(%s) = %s; want %s", tt.sourceFile, result, tt.expected)
+			}
+			if result != "" && SrcFileNameOf(result) != tt.sourceFile {
+				t.Errorf("SrcFileNameOf(%s) = %s; want %s", result, SrcFileNameOf(result), tt.sourceFile)
+			}
+		})
+	}
+}
+func TestIsTestOnlyTestCaseFile(t *testing.T) {
+	tests := []struct {
+		testCaseFile string
+		expected     bool
+	}{
+		{"example_testcase_test.go", true},
+		{"example_testcase.go", false},
+		{"example_test.go", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testCaseFile, func(t *testing.T) {
+			if result := IsTestOnlyTestCaseFile(tt.testCaseFile); result != tt.expected {
+				t.Errorf("IsTestOnlyTestCaseFile(%s) = %v; want %v", tt.testCaseFile, result, tt.expected)
+			}
+		})
+	}
+}
 func TestTestFileNameOf(t *testing.T) {
 	tests := []struct {
 		sourceFile string
@@ -137,6 +180,48 @@ func TestTestCaseOutputTypeNameOf(t *testing.T) {
 	}
 }
 
+func TestCheckerFuncNameOf(t *testing.T) {
+	tests := []struct {
+		funcName string
+		expected string
+	}{
+		{"Example", "checkExample"},
+		{"TwoSum", "checkTwoSum"},
+		{"", "check"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.funcName, func(t *testing.T) {
+			result := CheckerFuncNameOf(tt.funcName)
+			if result != tt.expected {
+				t.Errorf("CheckerFuncNameOf(%s) = %s; want %s", tt.funcName, result, tt.expected)
+			}
+		})
+	}
+}
+func TestFuncNameOfChecker(t *testing.T) {
+	tests := []struct {
+		checkerName string
+		expected    string
+	}{
+		{"checkExample", "Example"},
+		{"checkTwoSum", "TwoSum"},
+		{"checker", ""},
+		{"check", ""},
+		{"CheckExample", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.checkerName, func(t *testing.T) {
+			result := FuncNameOfChecker(tt.checkerName)
+			if result != tt.expected {
+				t.Errorf("FuncNameOfChecker(%s) = %s; want %s", tt.checkerName, result, tt.expected)
+			}
+		})
+	}
+}
+
 func TestIsTestCase(t *testing.T) {
 	tests := []struct {
 		typeName string
@@ -236,3 +321,59 @@ func TestFuncNameOf(t *testing.T) {
 		})
 	}
 }
+
+func TestNaming(t *testing.T) {
+	n := Naming{TestCaseFile: "cases_<name>.go", TestCaseType: "<func>Case"}.WithDefaults()
+	if err := n.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		result string
+		want   string
+	}{
+		{"TestCaseFileNameOf", n.TestCaseFileNameOf("dir/two_sum.go"), "dir/cases_two_sum.go"},
+		{"TestOnlyTestCaseFileNameOf", n.TestOnlyTestCaseFileNameOf("two_sum.go"), "cases_two_sum_test.go"},
+		{"SrcFileNameOf", n.SrcFileNameOf("dir/cases_two_sum.go"), "dir/two_sum.go"},
+		{"SrcFileNameOf test-only", n.SrcFileNameOf("cases_two_sum_test.go"), "two_sum.go"},
+		{"SrcFileNameOf default", n.SrcFileNameOf("two_sum_testcase.go"), ""},
+		{"TestCaseTypeNameOf", n.TestCaseTypeNameOf("TwoSum"), "TwoSumCase"},
+		{"TestCaseInputTypeNameOf", n.TestCaseInputTypeNameOf("TwoSum"), "testTwoSumInput"},
+		{"FuncNameOf case", n.FuncNameOf("TwoSumCase"), "TwoSum"},
+		{"FuncNameOf input", n.FuncNameOf("testTwoSumInput"), "TwoSum"},
+		{"FuncNameOf default case", n.FuncNameOf("testTwoSumCase"), "testTwoSum"},
+	}
+	for _, tt := range tests {
+		if tt.result != tt.want {
+			t.Errorf("%s = %q; want %q", tt.name, tt.result, tt.want)
+		}
+	}
+	if !n.IsTestOnlyTestCaseFile("cases_two_sum_test.go") || n.IsTestOnlyTestCaseFile("two_sum_testcase_test.go") {
+		t.Errorf("IsTestOnlyTestCaseFile() does not follow the pattern %q", n.TestCaseFile)
+	}
+}
+
+func TestNamingValidate(t *testing.T) {
+	tests := []struct {
+		naming Naming
+		valid  bool
+	}{
+		{DefaultNaming, true},
+		{Naming{TestCaseFile: "cases.go"}, false},
+		{Naming{TestCaseFile: "<name>_cases_test.go"}, false},
+		{Naming{TestCaseFile: "cases/<name>.go"}, false},
+		{Naming{TestCaseType: "Case"}, false},
+		{Naming{TestCaseType: "<func>-case"}, false},
+		{Naming{TestCaseType: "<func><func>Case"}, false},
+		{Naming{TestCaseType: "test<func>Input"}, false},
+		{Naming{CheckerFunc: "<func>Checker"}, true},
+	}
+
+	for _, tt := range tests {
+		err := tt.naming.WithDefaults().Validate()
+		if (err == nil) != tt.valid {
+			t.Errorf("Validate(%+v) error = %v; want valid = %v", tt.naming, err, tt.valid)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseTOML parses the subset of TOML that configuration files need: comments,
+// tables, including dotted ones such as "[a.b]", and bare keys set to strings,
+// integers, floats or booleans. Arrays, inline tables, multi-line strings and
+// dates are rejected.
+//
+// Parameters:
+//   - content: The TOML document
+//
+// Returns:
+//   - map[string]any: The root table, holding the other tables as
+//     map[string]any and values as string, int64, float64 or bool
+//   - error: An error prefixed with the line it was found on, e.g.
+//     "3: expected key = value"
+func parseTOML(content []byte) (map[string]any, error) {
+	root := make(map[string]any)
+	table := root
+	// defined holds the tables declared with a header, which must not be
+	// declared twice
+	defined := make(map[string]bool)
+	for i, line := range strings.Split(string(content), "\n") {
+		lineNo := i + 1
+		line = strings.TrimSpace(cutComment(line))
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if strings.HasPrefix(line, "[[") {
+				return nil, fmt.Errorf("%d: arrays of tables are not supported", lineNo)
+			}
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("%d: expected ] at the end of the table header", lineNo)
+			}
+			name := strings.TrimSpace(line[1 : len(line)-1])
+			if defined[name] {
+				return nil, fmt.Errorf("%d: table %s is already defined", lineNo, name)
+			}
+			defined[name] = true
+			table = root
+			for _, key := range strings.Split(name, ".") {
+				key = strings.TrimSpace(key)
+				if !isBareKey(key) {
+					return nil, fmt.Errorf("%d: invalid table name %q", lineNo, name)
+				}
+				switch sub := table[key].(type) {
+				case nil:
+					next := make(map[string]any)
+					table[key] = next
+					table = next
+				case map[string]any:
+					table = sub
+				default:
+					return nil, fmt.Errorf("%d: key %s is already defined", lineNo, key)
+				}
+			}
+			continue
+		}
+
+		key, raw, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || !isBareKey(key) {
+			return nil, fmt.Errorf("%d: expected key = value", lineNo)
+		}
+		if _, ok := table[key]; ok {
+			return nil, fmt.Errorf("%d: key %s is already defined", lineNo, key)
+		}
+		value, err := parseTOMLValue(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fmt.Errorf("%d: %s: %v", lineNo, key, err)
+		}
+		table[key] = value
+	}
+	return root, nil
+}
+
+// parseTOMLValue parses a string, integer, float or boolean value.
+func parseTOMLValue(raw string) (any, error) {
+	switch {
+	case raw == "":
+		return nil, fmt.Errorf("missing value")
+	case strings.HasPrefix(raw, `"""`) || strings.HasPrefix(raw, "'''"):
+		return nil, fmt.Errorf("multi-line strings are not supported")
+	case raw[0] == '"':
+		s, err := strconv.Unquote(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid string %s", raw)
+		}
+		return s, nil
+	case raw[0] == '\'':
+		if len(raw) < 2 || raw[len(raw)-1] != '\'' || strings.Contains(raw[1:len(raw)-1], "'") {
+			return nil, fmt.Errorf("invalid string %s", raw)
+		}
+		return raw[1 : len(raw)-1], nil
+	case raw == "true" || raw == "false":
+		return raw == "true", nil
+	case raw[0] == '[' || raw[0] == '{':
+		return nil, fmt.Errorf("arrays and inline tables are not supported")
+	}
+
+	number := strings.ReplaceAll(raw, "_", "")
+	if i, err := strconv.ParseInt(number, 0, 64); err == nil {
+		return i, nil
+	}
+	if f, err := strconv.ParseFloat(number, 64); err == nil && !strings.HasPrefix(strings.TrimLeft(number, "+-"), "0x") {
+		return f, nil
+	}
+	return nil, fmt.Errorf("unsupported value %s", raw)
+}
+
+// cutComment removes the comment at the end of a line, leaving the number
+// signs inside strings alone.
+func cutComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote == 0 && c == '#':
+			return line[:i]
+		case quote == 0 && (c == '"' || c == '\''):
+			quote = c
+		case quote == '"' && c == '\\':
+			// Skip the escaped character
+			i++
+		case c == quote:
+			quote = 0
+		}
+	}
+	return line
+}
+
+// isBareKey reports whether s is a bare TOML key: a non-empty run of ASCII
+// letters, digits, underscores and dashes.
+func isBareKey(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-') {
+			return false
+		}
+	}
+	return true
+}
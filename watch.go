@@ -0,0 +1,324 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Ezer015/leetcode-gen-test/codegen"
+	"github.com/Ezer015/leetcode-gen-test/utils"
+)
+
+// fileStamp identifies a version of a file for change detection.
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// snapshotOf records the stamps of the source, test case and configuration
+// files under root, leaving out the test files that watching generates.
+func snapshotOf(root string) (map[string]fileStamp, error) {
+	files, err := expandTargets([]string{filepath.Join(root, "...")}, func(path string) bool {
+		return isConfigFile(path) || (strings.HasSuffix(path, ".go") &&
+			(!strings.HasSuffix(path, "_test.go") || utils.IsTestOnlyTestCaseFile(path)))
+	})
+	if err != nil {
+		return nil, err
+	}
+	snapshot := make(map[string]fileStamp, len(files))
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			// Removed since the directory was walked
+			continue
+		}
+		snapshot[file] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+	}
+	return snapshot, nil
+}
+
+// isConfigFile reports whether a file is a project configuration file, see
+// utils.Config.
+func isConfigFile(path string) bool {
+	for _, name := range utils.ConfigFileNames {
+		if filepath.Base(path) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// changedConfigDirsOf compares two snapshots and returns the directories of
+// the configuration files that were added, modified or removed.
+func changedConfigDirsOf(before, after map[string]fileStamp) []string {
+	var dirs []string
+	for file, stamp := range after {
+		if old, ok := before[file]; isConfigFile(file) && (!ok || old != stamp) {
+			dirs = append(dirs, filepath.Dir(file))
+		}
+	}
+	for file := range before {
+		if _, ok := after[file]; isConfigFile(file) && !ok {
+			dirs = append(dirs, filepath.Dir(file))
+		}
+	}
+	return dirs
+}
+
+// changedSourcesOf compares two snapshots and returns the source files whose
+// source or test case file was added or modified, or whose project
+// configuration changed, sorted. Files without a test case file are not
+// tested and are left out.
+func changedSourcesOf(before, after map[string]fileStamp) []string {
+	configDirs := changedConfigDirsOf(before, after)
+	seen := make(map[string]bool)
+	var sourceFiles []string
+	for file, stamp := range after {
+		if isConfigFile(file) {
+			continue
+		}
+		if old, ok := before[file]; ok && old == stamp && !inDirs(file, configDirs) {
+			continue
+		}
+		sourceFile := file
+		if src := utils.SrcFileNameOf(file); src != "" {
+			sourceFile = src
+		}
+		_, ok := after[utils.TestCaseFileNameOf(sourceFile)]
+		if _, testOnly := after[utils.TestOnlyTestCaseFileNameOf(sourceFile)]; !(ok || testOnly) || seen[sourceFile] {
+			continue
+		}
+		seen[sourceFile] = true
+		sourceFiles = append(sourceFiles, sourceFile)
+	}
+	sort.Strings(sourceFiles)
+	return sourceFiles
+}
+
+// inDirs reports whether a file is in one of the directories or their
+// subdirectories.
+func inDirs(file string, dirs []string) bool {
+	for _, dir := range dirs {
+		if rel, err := filepath.Rel(dir, file); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// watch polls the source and test case files under root until ctx is done.
+// Whenever a pair of files changes, its test file is regenerated and the
+// tests of the pair are run in its package.
+//
+// Parameters:
+//   - ctx: The context stopping the watch
+//   - gs: The generators of the source files
+//   - root: The directory to watch, including its subdirectories
+//   - interval: The time between two polls
+//   - out: The writer the results are reported to
+//
+// Returns:
+//   - error: An error if the directory cannot be walked
+func watch(ctx context.Context, gs *generators, root string, interval time.Duration, out io.Writer) error {
+	snapshot, err := snapshotOf(root)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "watching %s for changes\n", root)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		next, err := snapshotOf(root)
+		if err != nil {
+			fmt.Fprintf(out, "failed to poll %s: %v\n", root, err)
+			continue
+		}
+		// Configuration files created under root are found by the next
+		// lookups, and the test case files are named anew
+		if len(changedConfigDirsOf(snapshot, next)) > 0 {
+			utils.ResetConfigs()
+			if next, err = snapshotOf(root); err != nil {
+				fmt.Fprintf(out, "failed to poll %s: %v\n", root, err)
+				continue
+			}
+		}
+		changed := changedSourcesOf(snapshot, next)
+		snapshot = next
+		for _, sourceFile := range changed {
+			testFile, _, err := generateFile(gs, sourceFile, false)
+			if ds, ok := err.(codegen.Diagnostics); ok {
+				// One problem per line, so that editors can jump to them
+				fmt.Fprintf(out, "FAIL %s\n%v\n", sourceFile, ds)
+				continue
+			}
+			if err != nil {
+				fmt.Fprintf(out, "FAIL %s: %v\n", sourceFile, err)
+				continue
+			}
+			testContent, err := os.ReadFile(testFile)
+			if err != nil {
+				fmt.Fprintf(out, "FAIL %s: %v\n", sourceFile, err)
+				continue
+			}
+			testNames := testFuncNamesOf(testContent)
+			if len(testNames) == 0 {
+				continue
+			}
+			fmt.Fprintf(out, "%s changed\n%s", sourceFile, runTests(ctx, filepath.Dir(sourceFile), testNames))
+		}
+	}
+}
+
+// testFuncNamesOf returns the names of the test functions declared in the
+// content of a test file, or nil if it cannot be parsed.
+func testFuncNamesOf(content []byte) []string {
+	f, err := parser.ParseFile(token.NewFileSet(), "", content, 0)
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, decl := range f.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil && strings.HasPrefix(fn.Name.Name, "Test") {
+			names = append(names, fn.Name.Name)
+		}
+	}
+	return names
+}
+
+// runTests runs the named tests of the package in dir and summarizes the results.
+func runTests(ctx context.Context, dir string, testNames []string) string {
+	pattern := "^(" + strings.Join(testNames, "|") + ")$"
+	cmd := exec.CommandContext(ctx, "go", "test", "-json", "-count=1", "-run", pattern, ".")
+	cmd.Dir = dir
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	// A failing test is reported through the events, not the error
+	_ = cmd.Run()
+	return summarizeTestEvents(&output)
+}
+
+// testEvent is an event of the output of go test -json.
+type testEvent struct {
+	Action  string
+	Package string
+	Test    string
+	Output  string
+}
+
+// frameLine matches the lines go test prints around tests, which the summary
+// leaves out in favor of the results of the tests.
+var frameLine = regexp.MustCompile(`^\s*(=== (RUN|PAUSE|CONT|NAME)|--- (PASS|FAIL|SKIP):)`)
+
+// summarizeTestEvents turns the output of go test -json into one line per
+// test, followed by the output of failing subtests, or the build output if
+// the package does not build.
+//
+// Example:
+//
+//	PASS TestTwoSum (3/3)
+//	FAIL TestMaxArea (1/2)
+//	    sol_test.go:24: maxArea() field0 = 42, want field0 = 49
+func summarizeTestEvents(r io.Reader) string {
+	type testResult struct {
+		action        string
+		passed, total int
+		output        []string
+	}
+	var (
+		order   []string
+		results = make(map[string]*testResult)
+		other   []string
+	)
+	resultOf := func(name string) *testResult {
+		if results[name] == nil {
+			results[name] = &testResult{}
+			order = append(order, name)
+		}
+		return results[name]
+	}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		var e testEvent
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			// Build errors and other messages of the go command
+			other = append(other, scanner.Text())
+			continue
+		}
+		top, sub, isSub := strings.Cut(e.Test, "/")
+		switch {
+		case e.Test == "":
+			if e.Action == "output" || e.Action == "build-output" {
+				other = append(other, strings.TrimSuffix(e.Output, "\n"))
+			}
+		case e.Action == "output":
+			if line := strings.TrimSpace(e.Output); !frameLine.MatchString(line) && line != "" {
+				if isSub {
+					line = sub + ": " + line
+				}
+				resultOf(top).output = append(resultOf(top).output, line)
+			}
+		case e.Action == "pass" || e.Action == "fail" || e.Action == "skip":
+			if !isSub {
+				resultOf(top).action = e.Action
+				continue
+			}
+			resultOf(top).total++
+			if e.Action != "fail" {
+				resultOf(top).passed++
+			}
+		}
+	}
+
+	var sb strings.Builder
+	for _, name := range order {
+		result := results[name]
+		if result.action == "" {
+			continue
+		}
+		fmt.Fprintf(&sb, "%s %s", strings.ToUpper(result.action), name)
+		if result.total > 0 {
+			fmt.Fprintf(&sb, " (%d/%d)", result.passed, result.total)
+		}
+		sb.WriteString("\n")
+		if result.action == "fail" {
+			for _, line := range result.output {
+				fmt.Fprintf(&sb, "    %s\n", line)
+			}
+		}
+	}
+	if sb.Len() == 0 {
+		// Nothing ran, most likely because the package does not build
+		for _, line := range other {
+			if line != "" && !strings.HasPrefix(line, "ok ") && line != "PASS" && line != "FAIL" {
+				fmt.Fprintf(&sb, "    %s\n", line)
+			}
+		}
+		if sb.Len() == 0 {
+			return "no tests to run\n"
+		}
+		return "FAIL build\n" + sb.String()
+	}
+	return sb.String()
+}
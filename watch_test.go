@@ -0,0 +1,126 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestChangedSourcesOf(t *testing.T) {
+	stamp := func(sec int64) fileStamp { return fileStamp{modTime: time.Unix(sec, 0), size: 10} }
+	before := map[string]fileStamp{
+		"a.go":          stamp(1),
+		"a_testcase.go": stamp(1),
+		"b.go":          stamp(1),
+		"b_testcase.go": stamp(1),
+		"helper.go":     stamp(1),
+	}
+	after := map[string]fileStamp{
+		"a.go":               stamp(1),
+		"a_testcase.go":      stamp(2), // test case edited
+		"b.go":               stamp(2), // source edited
+		"b_testcase.go":      stamp(1),
+		"c.go":               stamp(2), // source and test case added
+		"c_testcase.go":      stamp(2),
+		"helper.go":          stamp(2), // no test case file
+		"d.go":               stamp(2),
+		"e.go":               stamp(1),
+		"e_testcase_test.go": stamp(2), // test-only test case edited
+	}
+
+	expected := []string{"a.go", "b.go", "c.go", "e.go"}
+	if result := changedSourcesOf(before, after); !reflect.DeepEqual(result, expected) {
+		t.Errorf("changedSourcesOf() = %v; expected %v", result, expected)
+	}
+	if result := changedSourcesOf(after, after); len(result) != 0 {
+		t.Errorf("changedSourcesOf() without changes = %v; expected none", result)
+	}
+
+	// Editing a configuration file changes the sources of its project
+	configured := map[string]fileStamp{
+		"p/leetcode-gen-test.toml": stamp(1),
+		"p/a.go":                   stamp(1),
+		"p/a_testcase.go":          stamp(1),
+		"p/q/b.go":                 stamp(1),
+		"p/q/b_testcase.go":        stamp(1),
+		"pq/c.go":                  stamp(1),
+		"pq/c_testcase.go":         stamp(1),
+	}
+	edited := make(map[string]fileStamp)
+	for file, s := range configured {
+		edited[file] = s
+	}
+	edited["p/leetcode-gen-test.toml"] = stamp(2)
+	expected = []string{"p/a.go", "p/q/b.go"}
+	if result := changedSourcesOf(configured, edited); !reflect.DeepEqual(result, expected) {
+		t.Errorf("changedSourcesOf() after editing the config = %v; expected %v", result, expected)
+	}
+	delete(edited, "p/leetcode-gen-test.toml")
+	if result := changedSourcesOf(configured, edited); !reflect.DeepEqual(result, expected) {
+		t.Errorf("changedSourcesOf() after removing the config = %v; expected %v", result, expected)
+	}
+}
+
+func TestTestFuncNamesOf(t *testing.T) {
+	const content = `package p
+
+func TestTwoSum(t *testing.T) {}
+func TestSolutionTwoSum(t *testing.T) {}
+func helper() {}
+`
+	expected := []string{"TestTwoSum", "TestSolutionTwoSum"}
+	if result := testFuncNamesOf([]byte(content)); !reflect.DeepEqual(result, expected) {
+		t.Errorf("testFuncNamesOf() = %v; expected %v", result, expected)
+	}
+}
+
+func TestSummarizeTestEvents(t *testing.T) {
+	tests := []struct {
+		name     string
+		output   string
+		expected string
+	}{
+		{
+			name: "pass and fail",
+			output: `{"Action":"start","Package":"p"}
+{"Action":"run","Package":"p","Test":"TestAdd"}
+{"Action":"output","Package":"p","Test":"TestAdd","Output":"=== RUN   TestAdd\n"}
+{"Action":"output","Package":"p","Test":"TestAdd/small","Output":"--- PASS: TestAdd/small (0.00s)\n"}
+{"Action":"pass","Package":"p","Test":"TestAdd/small"}
+{"Action":"pass","Package":"p","Test":"TestAdd"}
+{"Action":"output","Package":"p","Test":"TestMax/large","Output":"    sol_test.go:24: max() field0 = 1, want field0 = 2\n"}
+{"Action":"output","Package":"p","Test":"TestMax/large","Output":"--- FAIL: TestMax/large (0.00s)\n"}
+{"Action":"fail","Package":"p","Test":"TestMax/large"}
+{"Action":"pass","Package":"p","Test":"TestMax/small"}
+{"Action":"fail","Package":"p","Test":"TestMax"}
+{"Action":"output","Package":"p","Output":"FAIL\n"}
+{"Action":"fail","Package":"p"}
+`,
+			expected: "PASS TestAdd (1/1)\nFAIL TestMax (1/2)\n    large: sol_test.go:24: max() field0 = 1, want field0 = 2\n",
+		},
+		{
+			name: "build failure",
+			output: `# p
+./sol.go:3:2: undefined: x
+{"Action":"start","Package":"p"}
+{"Action":"output","Package":"p","Output":"FAIL\tp [build failed]\n"}
+{"Action":"fail","Package":"p"}
+`,
+			expected: "FAIL build\n    # p\n    ./sol.go:3:2: undefined: x\n    FAIL\tp [build failed]\n",
+		},
+		{
+			name:     "no tests",
+			output:   `{"Action":"output","Package":"p","Output":"ok  \tp\t0.001s [no tests to run]\n"}`,
+			expected: "no tests to run\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := summarizeTestEvents(strings.NewReader(tt.output)); result != tt.expected {
+				t.Errorf("summarizeTestEvents() =\n%s\nexpected\n%s", result, tt.expected)
+			}
+		})
+	}
+}